@@ -0,0 +1,18 @@
+// Command caffeinated-vet reports colour literals in Go source that should
+// come from the Caffeinated palette. Run it through go vet:
+//
+//	go install github.com/caffeinated-minds/caffeinated-rust/cmd/caffeinated-vet@latest
+//	go vet -vettool=$(which caffeinated-vet) ./...
+//
+// or on its own, where -fix applies the suggested fixes:
+//
+//	caffeinated-vet -fix ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorlint"
+)
+
+func main() { singlechecker.Main(colorlint.Analyzer) }
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/artwork"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runArtwork(args []string) error {
+	fs, root := newFlagSet("artwork")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant")
+	preset := fs.String("preset", "", "preset to generate (default all): "+presetNames())
+	size := fs.String("size", "", "size as WIDTHxHEIGHT, overriding the preset")
+	pattern := fs.String("pattern", "", "pattern, overriding the preset: "+strings.Join(artwork.Patterns, ", "))
+	seed := fs.Uint64("seed", 1, "random seed; the same seed gives the same artwork")
+	wordmark := fs.String("wordmark", "", `text drawn over the pattern, overriding the preset; "name" for the theme name, "none" for no text`)
+	maxLum := fs.Float64("max-luminance", artwork.DefaultMaxLuminance, "highest relative luminance allowed in the pattern")
+	format := fs.String("format", "png,svg", "comma-separated output formats: png, svg")
+	out := fs.String("o", "dist/artwork", "output directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	formats := strings.Split(*format, ",")
+	for _, f := range formats {
+		if f != "png" && f != "svg" {
+			return fmt.Errorf("unknown format %q", f)
+		}
+	}
+	presets := artwork.Presets
+	if *preset != "" {
+		pr, ok := artwork.LookupPreset(*preset)
+		if !ok {
+			return fmt.Errorf("unknown preset %q (want one of %s)", *preset, presetNames())
+		}
+		presets = []artwork.Preset{pr}
+	}
+
+	v, err := theme.LoadVariant(*root, *variant)
+	if err != nil {
+		return err
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		return err
+	}
+	m, err := manifest.Load(*root)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+	failed := 0
+	for _, pr := range presets {
+		opts := pr.Options
+		opts.Seed, opts.MaxLuminance = *seed, *maxLum
+		if *size != "" {
+			if opts.Width, opts.Height, err = parseSize(*size); err != nil {
+				return err
+			}
+		}
+		if *pattern != "" {
+			opts.Pattern = *pattern
+		}
+		switch *wordmark {
+		case "":
+		case "none":
+			opts.Wordmark = ""
+		default:
+			opts.Wordmark = *wordmark
+		}
+		if pr.Name == "banner" {
+			// The marketplace header around the banner should be its base colour.
+			if bg := p.Color(palette.Background).Hex(); !strings.EqualFold(m.Banner.Color, bg) || m.Banner.Theme != "dark" {
+				return fmt.Errorf("package.json galleryBanner is %s %q, want %s \"dark\" to match the banner", m.Banner.Color, m.Banner.Theme, bg)
+			}
+		}
+		s, err := artwork.Generate(p, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", pr.Name, err)
+		}
+		img, r := artwork.Raster(s)
+		status := "ok"
+		if !r.Within(opts.MaxLuminance) {
+			status = "FAIL: brighter than the cap"
+			failed++
+		}
+		fmt.Printf("%s: %dx%d %s, luminance max %.4f mean %.4f (cap %.3f): %s\n",
+			pr.Name, opts.Width, opts.Height, opts.Pattern, r.MaxLuminance, r.MeanLuminance, opts.MaxLuminance, status)
+
+		stem := pr.Name
+		if v.ID != theme.DefaultVariant {
+			stem += "-" + v.ID
+		}
+		for _, f := range formats {
+			var data []byte
+			switch f {
+			case "png":
+				var b bytes.Buffer
+				if err := png.Encode(&b, img); err != nil {
+					return err
+				}
+				data = b.Bytes()
+			case "svg":
+				if data, err = artwork.SVG(s); err != nil {
+					return err
+				}
+			}
+			path := filepath.Join(*out, stem+"."+f)
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("  wrote %s\n", path)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d artworks exceed the luminance cap", failed, len(presets))
+	}
+	return nil
+}
+
+func presetNames() string {
+	var names []string
+	for _, p := range artwork.Presets {
+		names = append(names, p.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseSize parses WIDTHxHEIGHT.
+func parseSize(s string) (int, int, error) {
+	ws, hs, ok := strings.Cut(s, "x")
+	w, werr := strconv.Atoi(ws)
+	h, herr := strconv.Atoi(hs)
+	if !ok || werr != nil || herr != nil || w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("size %q is not WIDTHxHEIGHT", s)
+	}
+	return w, h, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/balance"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runBalance(args []string) error {
+	fs, root := newFlagSet("balance")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant")
+	band := fs.Float64("band", balance.DefaultOptions.Band, "width of the accepted OKLCH lightness band around the target")
+	target := fs.Float64("target", 0, "target OKLCH lightness (default the median of the accents)")
+	neutrals := fs.Bool("neutrals", false, "also balance syntax roles without a hue, such as comments and variables")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	v, err := theme.LoadVariant(*root, *variant)
+	if err != nil {
+		return err
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		return err
+	}
+	r, err := balance.Check(p, balance.Options{Band: *band, Target: *target, Neutrals: *neutrals})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s on %s (L %.3f)\n", v.Label, r.Background.Hex(), r.Background.OKLCH().L)
+	fmt.Printf("%-12s %-8s %6s %6s %5s %7s\n", "role", "colour", "L", "C", "H", "offset")
+	for _, role := range r.Roles {
+		hue := "-"
+		if role.Color.Chromatic() {
+			hue = fmt.Sprintf("%.0f", role.LCH.H)
+		}
+		fmt.Printf("%-12s %-8s %6.3f %6.3f %5s", role.Role, role.Color.Hex(), role.LCH.L, role.LCH.C, hue)
+		switch {
+		case !role.Accent:
+			fmt.Printf(" %7s  neutral, not balanced\n", "")
+		case role.Outside:
+			fmt.Printf(" %+7.3f  outside; try %s (L %.3f, same hue", role.Offset, role.Suggest.Hex(), r.Target)
+			if role.Clipped {
+				fmt.Printf(", chroma %.3f to stay in sRGB", role.Suggest.OKLCH().C)
+			}
+			fmt.Println(")")
+		default:
+			fmt.Printf(" %+7.3f\n", role.Offset)
+		}
+	}
+	if r.Target == 0 {
+		fmt.Println("no chromatic syntax roles to balance")
+		return nil
+	}
+	s := r.Spread
+	fmt.Printf("lightness %.3f to %.3f (spread %.3f, sd %.3f over distinct colours), chroma %.3f to %.3f; band %.3f ± %.3f\n",
+		s.MinL, s.MaxL, s.MaxL-s.MinL, s.StdL, s.MinC, s.MaxC, r.Target, r.Band/2)
+	if out := r.Outside(); len(out) > 0 {
+		return fmt.Errorf("%d syntax roles of %s are outside the lightness band", len(out), v.Label)
+	}
+	return nil
+}
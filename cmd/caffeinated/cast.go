@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/cast"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runCast(args []string) error {
+	fs, root := newFlagSet("cast")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant")
+	format := fs.String("format", "svg", "animation format: "+strings.Join(cast.Formats, " or "))
+	out := fs.String("o", "dist/casts", "output directory, relative to -root")
+	idle := fs.Float64("idle", cast.DefaultOptions.Idle, "longest pause between events in seconds, 0 to keep the recorded pauses")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: caffeinated cast [flags] recording.cast...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	opts := cast.DefaultOptions
+	opts.Idle = *idle
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("no recordings given")
+	}
+
+	v, err := theme.LoadVariant(*root, *variant)
+	if err != nil {
+		return err
+	}
+	eff, err := colorreg.Resolve(v.Theme)
+	if err != nil {
+		return err
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		return err
+	}
+	colors, err := cast.TerminalColors(eff, p)
+	if err != nil {
+		return err
+	}
+	dir := rootPath(*root, *out)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, path := range fs.Args() {
+		c, err := cast.Load(path)
+		if err != nil {
+			return err
+		}
+		frames := cast.Play(c, opts)
+		anim, err := cast.Render(*format, frames, colors, c.Header.Title)
+		if err != nil {
+			return err
+		}
+		still, err := cast.PNG(frames[len(frames)-1].Screen, colors)
+		if err != nil {
+			return err
+		}
+		stem := strings.TrimSuffix(filepath.Base(path), cast.Ext)
+		if v.ID != theme.DefaultVariant {
+			stem += "-" + v.ID
+		}
+		for _, f := range []struct {
+			ext  string
+			data []byte
+		}{{"." + *format, anim}, {".png", still}} {
+			name := filepath.Join(dir, stem+f.ext)
+			if err := os.WriteFile(name, f.data, 0o644); err != nil {
+				return err
+			}
+			fmt.Println(name)
+		}
+		fmt.Printf("\t%d frames\n", len(frames))
+	}
+	return nil
+}
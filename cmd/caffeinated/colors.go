@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runColors(args []string) error {
+	fs, root := newFlagSet("colors")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant")
+	prefix := fs.String("prefix", "", "only list ids starting with this prefix")
+	unset := fs.Bool("unset", false, "also list ids that paint nothing")
+	asJSON := fs.Bool("json", false, "print the effective colour map as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	v, err := theme.LoadVariant(*root, *variant)
+	if err != nil {
+		return err
+	}
+	reg := colorreg.Default()
+	eff, err := reg.Resolve(v.Theme)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		m := map[string]string{}
+		for _, id := range eff.IDs() {
+			if c, ok := eff.Color(id); ok && strings.HasPrefix(id, *prefix) {
+				m[id] = c.Hex()
+			}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	}
+
+	for _, id := range eff.IDs() {
+		val, _ := eff.Lookup(id)
+		if !strings.HasPrefix(id, *prefix) || (val.Source == colorreg.Unset && !*unset) {
+			continue
+		}
+		switch val.Source {
+		case colorreg.Themed:
+			fmt.Printf("%-50s %-9s theme\n", id, val.Color.Hex())
+		case colorreg.Defaulted:
+			fmt.Printf("%-50s %-9s default %s\n", id, val.Color.Hex(), val.Expr)
+		default:
+			fmt.Printf("%-50s %-9s unset\n", id, "-")
+		}
+	}
+	for _, id := range reg.Unknown(v.Theme) {
+		fmt.Fprintf(os.Stderr, "%s sets %s, which is not in the colour registry snapshot\n", v.Label, id)
+	}
+	return nil
+}
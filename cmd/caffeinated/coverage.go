@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/coverage"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runCoverage(args []string) error {
+	fs, root := newFlagSet("coverage")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant")
+	dir := fs.String("fixtures", "testdata/scopes", "scope fixture directory, relative to -root")
+	syntax := fs.String("syntax", "testdata/syntax", "caret-annotated syntax test directory, relative to -root")
+	verbose := fs.Bool("v", false, "list every token and the token rules no fixture reaches")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	v, err := theme.LoadVariant(*root, *variant)
+	if err != nil {
+		return err
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		return err
+	}
+	fixtures, err := coverage.LoadDir(rootPath(*root, *dir))
+	if err != nil {
+		return err
+	}
+
+	tests, err := coverage.LoadSyntaxDir(rootPath(*root, *syntax))
+	if err != nil {
+		return err
+	}
+
+	var reports []*coverage.Report
+	for _, f := range fixtures {
+		reports = append(reports, coverage.Check(f, v.Theme, p))
+	}
+	for _, f := range tests {
+		reports = append(reports, coverage.CheckSyntax(f, v.Theme, p))
+	}
+	failed := 0
+	for _, r := range reports {
+		status := "ok"
+		if r.Failed > 0 {
+			status = fmt.Sprintf("%d of %d tokens wrong", r.Failed, len(r.Results))
+			failed++
+		}
+		fmt.Printf("%s: %s\n", r.Fixture.Name, status)
+		for _, res := range r.Results {
+			if *verbose || res.Problem != "" {
+				fmt.Printf("\t%s\n", res)
+			}
+		}
+	}
+	if *verbose {
+		for _, i := range coverage.Unused(v.Theme, reports) {
+			fmt.Printf("rule %d (%s) colours no fixture token\n", i, strings.Join(v.Theme.TokenColors[i].Scope, ", "))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixtures do not match %s", failed, len(reports), v.Label)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"path/filepath"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/devcontainer"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/profile"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runDevcontainer(args []string) error {
+	fs, root := newFlagSet("devcontainer")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant")
+	vsix := fs.String("vsix", "", "packaged extension to install, relative to -root; without it the extension comes from the marketplace")
+	out := fs.String("o", "dist/devcontainer/src/"+devcontainer.FeatureID, "feature output directory, relative to -root; replaced only if it holds a generated feature")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	m, err := manifest.Load(*root)
+	if err != nil {
+		return err
+	}
+	v, err := theme.LoadVariant(*root, *variant)
+	if err != nil {
+		return err
+	}
+	settings, err := profile.NewSettings(v, nil)
+	if err != nil {
+		return err
+	}
+	opts := devcontainer.Options{Variant: v, Manifest: m, Settings: settings}
+	if *vsix != "" {
+		zr, err := zip.OpenReader(rootPath(*root, *vsix))
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		opts.VSIX = &zr.Reader
+	}
+	files, err := devcontainer.Generate(opts)
+	if err != nil {
+		return err
+	}
+	dir := rootPath(*root, *out)
+	if err := replaceDir(dir, "devcontainer-feature.json"); err != nil {
+		return err
+	}
+	if err := export.Write(dir, files); err != nil {
+		return err
+	}
+	fmt.Println(filepath.Join(dir, "devcontainer-feature.json"))
+	return nil
+}
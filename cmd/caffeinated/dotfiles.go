@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/dotfiles"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runDotfiles(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: caffeinated dotfiles install|rollback|list [flags]")
+	}
+	sub, args := args[0], args[1:]
+	fs, root := newFlagSet("dotfiles " + sub)
+	home := fs.String("home", os.Getenv("HOME"), "home directory to install into")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant (install)")
+	tools := fs.String("tools", "", "comma separated tools to restrict the install to (default: every detected tool)")
+	dryRun := fs.Bool("n", false, "print the planned changes without making them (install)")
+	id := fs.String("id", "", "install to roll back (default: the latest)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch sub {
+	case "install":
+		v, err := theme.LoadVariant(*root, *variant)
+		if err != nil {
+			return err
+		}
+		var only []string
+		if *tools != "" {
+			only = strings.Split(*tools, ",")
+		}
+		targets := dotfiles.Detect(*home, only)
+		if len(targets) == 0 {
+			fmt.Println("no supported tool configs found in", *home)
+			return nil
+		}
+		for _, t := range targets {
+			fmt.Println("detected", t.Tool)
+		}
+		steps, err := dotfiles.Plan(*home, v, targets)
+		if err != nil {
+			return err
+		}
+		if len(steps) == 0 {
+			fmt.Println("already installed")
+			return nil
+		}
+		for _, s := range steps {
+			fmt.Println(s)
+		}
+		if *dryRun {
+			return nil
+		}
+		j, err := dotfiles.Apply(*home, v.ID, steps, time.Now())
+		if err != nil && j != nil {
+			return fmt.Errorf("%w\nundo the changes made so far with: caffeinated dotfiles rollback -id %s", err, j.ID)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("installed; undo with: caffeinated dotfiles rollback -id %s\n", j.ID)
+	case "rollback":
+		j, err := dotfiles.Rollback(*home, *id)
+		if err != nil {
+			return err
+		}
+		for _, a := range j.Actions {
+			switch {
+			case a.Appended != "":
+				fmt.Println("unhooked ~/" + a.Path)
+			case a.Backup == "":
+				fmt.Println("removed  ~/" + a.Path)
+			default:
+				fmt.Println("restored ~/" + a.Path)
+			}
+		}
+	case "list":
+		ids, err := dotfiles.Installs(*home)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			fmt.Println(id)
+		}
+	default:
+		return fmt.Errorf("unknown subcommand %q", sub)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runExport(args []string) error {
+	fs, root := newFlagSet("export")
+	format := fs.String("format", "", "comma separated formats to export, or \"all\" ("+strings.Join(export.Names(), ", ")+")")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant")
+	out := fs.String("o", "dist/export", "output directory, relative to -root")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format == "" {
+		return fmt.Errorf("-format is required")
+	}
+	v, err := theme.LoadVariant(*root, *variant)
+	if err != nil {
+		return err
+	}
+	dir := rootPath(*root, *out)
+	formats := strings.Split(*format, ",")
+	if *format == "all" {
+		formats = export.Names()
+	}
+	for _, f := range formats {
+		f = strings.TrimSpace(f)
+		files, err := export.Run(f, v)
+		if err != nil {
+			return err
+		}
+		if err := export.Write(dir, files); err != nil {
+			return err
+		}
+		for _, file := range files {
+			fmt.Println(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		}
+	}
+	return nil
+}
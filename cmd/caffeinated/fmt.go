@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/themefmt"
+)
+
+// generated matches the marker Go tools use for generated files, which
+// "caffeinated focus" also writes.
+var generated = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+func runFmt(args []string) error {
+	fs, root := newFlagSet("fmt")
+	check := fs.Bool("check", false, "report unformatted files and fail instead of rewriting them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	paths := fs.Args()
+	if len(paths) == 0 {
+		vs, err := theme.LoadVariants(*root)
+		if err != nil {
+			return err
+		}
+		for _, v := range vs {
+			paths = append(paths, v.Path)
+		}
+	} else {
+		for i, p := range paths {
+			paths[i] = rootPath(*root, p)
+		}
+	}
+
+	unformatted := 0
+	for _, path := range paths {
+		name := path
+		if rel, err := filepath.Rel(*root, path); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		first, _, _ := bytes.Cut(src, []byte("\n"))
+		if generated.Match(first) {
+			fmt.Printf("%s: generated, skipped\n", name)
+			continue
+		}
+		r, err := themefmt.Format(src)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for _, id := range r.Unknown {
+			fmt.Printf("%s: warning: %s is not in the colour registry snapshot\n", name, id)
+		}
+		for _, id := range r.Unsectioned {
+			fmt.Printf("%s: warning: no section for %s; it goes under %q\n", name, id, themefmt.Other)
+		}
+		if bytes.Equal(r.Data, src) {
+			fmt.Printf("%s: ok\n", name)
+			continue
+		}
+		unformatted++
+		for _, c := range r.Changes {
+			fmt.Printf("%s:%s\n", name, c)
+		}
+		if *check {
+			fmt.Printf("%s: not formatted\n", name)
+			continue
+		}
+		if err := os.WriteFile(path, r.Data, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("%s: formatted\n", name)
+	}
+	if *check && unformatted > 0 {
+		return fmt.Errorf("%d of %d theme files are not formatted; run caffeinated fmt", unformatted, len(paths))
+	}
+	return nil
+}
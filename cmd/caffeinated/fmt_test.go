@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFmtCheck runs fmt -check on an unformatted theme, which must fail
+// without touching the file, then formats it and checks again.
+func TestFmtCheck(t *testing.T) {
+	root := t.TempDir()
+	src := []byte(`{
+  "colors": {
+    // Merge Conflicts
+    "editorOverviewRuler.currentContentForeground": "#aabbcc"
+  }
+}
+`)
+	path := filepath.Join(root, "theme.json")
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runFmt([]string{"-root", root, "-check", "theme.json"}); err == nil {
+		t.Fatal("fmt -check passed an unformatted theme")
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != string(src) {
+		t.Fatalf("fmt -check changed the file: %q, %v", got, err)
+	}
+	if err := runFmt([]string{"-root", root, "theme.json"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runFmt([]string{"-root", root, "-check", "theme.json"}); err != nil {
+		t.Errorf("fmt -check after fmt: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/variants"
+)
+
+func runFocus(args []string) error {
+	fs, root := newFlagSet("focus")
+	out := fs.String("o", "themes/Caffeinated-Rust-Focus-color-theme.json", "output theme file, relative to -root")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	base, err := theme.LoadVariant(*root, theme.DefaultVariant)
+	if err != nil {
+		return err
+	}
+	t, err := variants.Focus(base.Theme)
+	if err != nil {
+		return err
+	}
+	return t.Write(rootPath(*root, *out), "Code generated by \"caffeinated focus\"; DO NOT EDIT.")
+}
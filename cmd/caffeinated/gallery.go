@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/gallery"
+)
+
+func runGallery(args []string) error {
+	fs, root := newFlagSet("gallery")
+	base := fs.String("url", "", "URL the gallery will be served from; it becomes extensionsGallery.serviceUrl")
+	out := fs.String("o", "dist/gallery", "output directory, relative to -root; replaced only if it holds a generated gallery")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: caffeinated gallery -url <url> [-o dir] <file.vsix>...")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *base == "" || fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("need -url and at least one VSIX")
+	}
+	var exts []*gallery.Extension
+	for _, name := range fs.Args() {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		e, err := gallery.Read(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		exts = append(exts, e)
+	}
+	files, resp, err := gallery.Generate(exts, *base)
+	if err != nil {
+		return err
+	}
+	dir := rootPath(*root, *out)
+	if err := replaceDir(dir, gallery.QueryPath); err != nil {
+		return err
+	}
+	if err := export.Write(dir, files); err != nil {
+		return err
+	}
+	for _, e := range resp.Results[0].Extensions {
+		var vs []string
+		for _, v := range e.Versions {
+			vs = append(vs, v.Version)
+		}
+		fmt.Printf("%s.%s: %s\n", e.Publisher.PublisherName, e.ExtensionName, strings.Join(vs, ", "))
+	}
+	fmt.Println(filepath.Join(dir, "product.json"))
+	return nil
+}
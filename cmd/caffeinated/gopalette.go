@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/gopalette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runGoPalette(args []string) error {
+	fs, root := newFlagSet("gopalette")
+	check := fs.Bool("check", false, "fail if the package is out of date instead of writing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// The package follows the default variant; Focus is a reading mode
+	// of the editor, not a palette for programs.
+	v, err := theme.LoadVariant(*root, theme.DefaultVariant)
+	if err != nil {
+		return err
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		return err
+	}
+	src, err := gopalette.Generate(p)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(*root, gopalette.Dir, "palette.go")
+	old, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if bytes.Equal(old, src) {
+		fmt.Printf("%s: ok\n", path)
+		return nil
+	}
+	if *check {
+		return fmt.Errorf("%s is out of date; run caffeinated gopalette", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s\n", path)
+	return nil
+}
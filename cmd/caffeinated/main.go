@@ -0,0 +1,111 @@
+// Command caffeinated generates and checks the Caffeinated Rust theme
+// variants and the files derived from them.
+//
+// Usage:
+//
+//	caffeinated <command> [flags]
+//
+// Run "caffeinated help" for the list of commands.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"fmt", "format the theme files: sections, key order and hex case", runFmt},
+	{"focus", "generate the monochrome Caffeinated Rust Focus variant", runFocus},
+	{"profile", "generate the settings snippet and .code-profile export", runProfile},
+	{"export", "export the palette to terminal and tool configs", runExport},
+	{"devcontainer", "generate a dev container feature installing the theme and configs", runDevcontainer},
+	{"dotfiles", "install exported configs into a home directory, or roll an install back", runDotfiles},
+	{"screenshots", "check the committed screenshots against the current palette", runScreenshots},
+	{"colors", "list the effective workbench colours, including VS Code defaults", runColors},
+	{"mockups", "render SVG mockups of workbench scenes in the theme colours", runMockups},
+	{"semantic", "generate the semanticTokenScopes in package.json", runSemantic},
+	{"coverage", "check the token rules against the scope fixtures", runCoverage},
+	{"cast", "render asciinema recordings in the terminal colours", runCast},
+	{"balance", "check that the syntax colours have balanced lightness", runBalance},
+	{"artwork", "generate wallpapers, slide backgrounds and banners in the palette", runArtwork},
+	{"serve", "serve the palette, themes and exports over HTTP on localhost", runServe},
+	{"vsix", "check the packaged files, README images and package size", runVSIX},
+	{"gopalette", "generate the Go palette package that caffeinated-vet fixes refer to", runGoPalette},
+	{"tint", "generate per-host SSH terminal tints for prod, staging and dev", runTint},
+	{"gallery", "generate static private gallery metadata from built VSIX files", runGallery},
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "help" || os.Args[1] == "-h" {
+		usage()
+		return
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				if !errors.Is(err, flag.ErrHelp) {
+					fmt.Fprintf(os.Stderr, "caffeinated %s: %v\n", c.name, err)
+				}
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "caffeinated: unknown command %q\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: caffeinated <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.summary)
+	}
+}
+
+// newFlagSet returns a flag set with the -root flag every command shares.
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet("caffeinated "+name, flag.ContinueOnError)
+	root := fs.String("root", ".", "repository root containing package.json")
+	return fs, root
+}
+
+// rootPath resolves a path flag relative to the repository root unless it
+// is absolute.
+func rootPath(root, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
+
+// replaceDir clears an output directory before a command regenerates it.
+// It only removes a directory holding marker, the file the command always
+// writes, so that a mistyped -o cannot wipe the repository or a home
+// directory.
+func replaceDir(dir, marker string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, marker)); err != nil {
+		return fmt.Errorf("refusing to replace %s: it has no %s, so it was not written by this command", dir, marker)
+	}
+	return os.RemoveAll(dir)
+}
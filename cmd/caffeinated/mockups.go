@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/mockup"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runMockups(args []string) error {
+	fs, root := newFlagSet("mockups")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant")
+	scenes := fs.String("scenes", "", "comma separated scenes to render (default all)")
+	out := fs.String("o", "dist/mockups", "output directory")
+	strict := fs.Bool("strict", false, "fail when a scene uses a colour the theme leaves to VS Code")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	v, err := theme.LoadVariant(*root, *variant)
+	if err != nil {
+		return err
+	}
+	eff, err := colorreg.Resolve(v.Theme)
+	if err != nil {
+		return err
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		return err
+	}
+
+	list := mockup.Scenes
+	if *scenes != "" {
+		list = nil
+		for _, name := range strings.Split(*scenes, ",") {
+			s, ok := mockup.Lookup(strings.TrimSpace(name))
+			if !ok {
+				return fmt.Errorf("unknown scene %q", name)
+			}
+			list = append(list, s)
+		}
+	}
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return err
+	}
+	incomplete := 0
+	for _, s := range list {
+		svg, r := mockup.Render(s, eff, p)
+		path := filepath.Join(*out, export.BaseName(v)+"-"+s.Name+".svg")
+		if err := os.WriteFile(path, svg, 0o644); err != nil {
+			return err
+		}
+		fmt.Println(path)
+		for _, id := range r.Defaulted {
+			fmt.Printf("\t%s uses VS Code's default for %s\n", s.Name, id)
+		}
+		for _, id := range r.Unset {
+			fmt.Printf("\t%s paints nothing for %s\n", s.Name, id)
+		}
+		for _, id := range r.Unknown {
+			fmt.Printf("\t%s uses %s, which is not in the colour registry snapshot\n", s.Name, id)
+		}
+		if !r.OK() {
+			incomplete++
+		}
+	}
+	if *strict && incomplete > 0 {
+		return fmt.Errorf("%d of %d scenes use colours %s does not set", incomplete, len(list), v.Label)
+	}
+	return nil
+}
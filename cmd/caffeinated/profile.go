@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/profile"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runProfile(args []string) error {
+	fs, root := newFlagSet("profile")
+	dark := fs.String("dark", theme.DefaultVariant, "variant used as the dark theme")
+	light := fs.String("light", "", "variant used as the light theme (default: the first light variant, if any)")
+	out := fs.String("o", "dist", "output directory, relative to -root")
+	check := fs.String("check", "", "validate an existing .code-profile file, relative to -root, instead of generating one")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *check != "" {
+		doc, err := os.ReadFile(rootPath(*root, *check))
+		if err != nil {
+			return err
+		}
+		return profile.Validate(doc)
+	}
+
+	m, err := manifest.Load(*root)
+	if err != nil {
+		return err
+	}
+	vs, err := theme.LoadVariants(*root)
+	if err != nil {
+		return err
+	}
+	d, err := theme.FindVariant(vs, *dark)
+	if err != nil {
+		return err
+	}
+	var l *theme.Variant
+	if *light != "" {
+		v, err := theme.FindVariant(vs, *light)
+		if err != nil {
+			return err
+		}
+		l = &v
+	} else {
+		for i := range vs {
+			if vs[i].UITheme == "vs" || vs[i].UITheme == "hc-light" {
+				l = &vs[i]
+				break
+			}
+		}
+	}
+
+	settings, err := profile.NewSettings(d, l)
+	if err != nil {
+		return err
+	}
+	snippet, err := settings.JSON()
+	if err != nil {
+		return err
+	}
+	p, err := profile.New(m.DisplayName, m, settings)
+	if err != nil {
+		return err
+	}
+	export, err := p.JSON()
+	if err != nil {
+		return err
+	}
+
+	dir := rootPath(*root, *out)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		"settings.json":                 snippet,
+		m.DisplayName + ".code-profile": export,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
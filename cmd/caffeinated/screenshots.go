@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/screenshot"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func runScreenshots(args []string) error {
+	fs, root := newFlagSet("screenshots")
+	claimsPath := fs.String("claims", "images/screenshots.json", "screenshot claims file, relative to -root")
+	tolerance := fs.Float64("tolerance", screenshot.DefaultOptions.Tolerance, "OKLab distance within which an image colour matches a theme colour")
+	dominant := fs.Float64("dominant", screenshot.DefaultOptions.Dominant, "share of non-background pixels a colour needs to be checked")
+	verbose := fs.Bool("v", false, "list every dominant colour cluster")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	claims, err := screenshot.LoadClaims(rootPath(*root, *claimsPath))
+	if err != nil {
+		return err
+	}
+	vs, err := theme.LoadVariants(*root)
+	if err != nil {
+		return err
+	}
+	opts := screenshot.Options{Tolerance: *tolerance, Dominant: *dominant}
+
+	failed := 0
+	for _, name := range claims.Images() {
+		claim := claims[name]
+		v, err := theme.FindVariant(vs, claim.Variant)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		f, err := os.Open(filepath.Join(*root, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		r, err := screenshot.Analyze(img, v.Theme, claim.Roles, opts)
+		if err != nil {
+			return err
+		}
+		status := "ok"
+		if !r.OK() {
+			status = "STALE"
+			failed++
+		}
+		fmt.Printf("%s: %s\n", name, status)
+		if *verbose {
+			for _, c := range r.Clusters {
+				fmt.Printf("\t%s\n", c)
+			}
+		}
+		for _, c := range r.Stale {
+			fmt.Printf("\tcolour %s (%.1f%% of the picture) is not in %s\n", c.Color.Hex(), c.Share*100, v.Label)
+		}
+		for _, role := range r.Missing {
+			fmt.Printf("\tclaims role %s but does not contain its colour\n", role)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d screenshots are out of date", failed, len(claims))
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/semantic"
+)
+
+func runSemantic(args []string) error {
+	fs, root := newFlagSet("semantic")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	changed, err := manifest.WriteContribution(*root, "semanticTokenScopes", semantic.Contribution())
+	if err != nil {
+		return err
+	}
+	if changed {
+		fmt.Printf("wrote %s\n", filepath.Join(*root, "package.json"))
+	}
+	return nil
+}
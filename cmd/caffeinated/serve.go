@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/themeapi"
+)
+
+func runServe(args []string) error {
+	fs, root := newFlagSet("serve")
+	addr := fs.String("addr", "localhost:7457", "listen address")
+	public := fs.Bool("public", false, "listen on and serve non-loopback addresses")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	h := themeapi.Handler(themeapi.Options{Root: *root, Public: *public})
+	if !*public && !themeapi.IsLoopback(*addr) {
+		return fmt.Errorf("%s is not a loopback address; use -public to serve other hosts", *addr)
+	}
+	fmt.Printf("serving %s on http://%s/\n", rootPath(*root, "."), *addr)
+	return http.ListenAndServe(*addr, h)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/tint"
+)
+
+func runTint(args []string) error {
+	fs, root := newFlagSet("tint")
+	config := fs.String("config", filepath.Join(os.Getenv("HOME"), filepath.FromSlash(export.ConfigDir), "tint.json"), "host pattern to environment config")
+	variant := fs.String("variant", theme.DefaultVariant, "theme variant")
+	out := fs.String("o", "dist/tint", "output directory")
+	host := fs.String("host", "", "print the environment and colours for a host instead of writing files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, err := tint.Load(*config)
+	if err != nil {
+		return err
+	}
+	v, err := theme.LoadVariant(*root, *variant)
+	if err != nil {
+		return err
+	}
+	eff, err := colorreg.Resolve(v.Theme)
+	if err != nil {
+		return err
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		return err
+	}
+	base, err := tint.BaseColors(eff, p)
+	if err != nil {
+		return err
+	}
+
+	if *host != "" {
+		e, pattern, ok := c.Match(*host)
+		if !ok {
+			fmt.Printf("%s: no environment; the terminal stays untinted\n", *host)
+			return nil
+		}
+		t, err := base.Tint(p, e)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s (matches %s)\n", *host, e.Name, pattern)
+		fmt.Printf("\tbackground %s, was %s\n", t.Background.Hex(), base.Background.Hex())
+		fmt.Printf("\tcursor     %s (%s)\n", t.Cursor.Hex(), e.Role)
+		fmt.Printf("\tselection  %s, was %s\n", t.Selection.Hex(), base.Selection.Hex())
+		return nil
+	}
+
+	files, err := tint.Files(c, base, p, export.BaseName(v), filepath.Base(*config))
+	if err != nil {
+		return err
+	}
+	if err := export.Write(*out, files); err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Println(filepath.Join(*out, filepath.FromSlash(f.Path)))
+	}
+	return nil
+}
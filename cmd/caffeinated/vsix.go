@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/vsix"
+)
+
+func runVSIX(args []string) error {
+	fs, root := newFlagSet("vsix")
+	budget := fs.String("budget", "", "largest allowed package size, in bytes or with a K or M suffix (default: caffeinated.vsixBudget in package.json)")
+	optimize := fs.Bool("optimize", false, "rewrite packaged PNGs that lossless optimisation makes smaller")
+	list := fs.Bool("list", false, "list every packaged file with its size")
+	readme := fs.String("readme", "README.md", "Markdown file whose images are checked, relative to -root")
+	limits := vsix.DefaultImageLimits
+	fs.IntVar(&limits.MinWidth, "min-width", limits.MinWidth, "narrowest allowed README image, in pixels")
+	fs.IntVar(&limits.MaxWidth, "max-width", limits.MaxWidth, "widest allowed README image, in pixels")
+	fs.IntVar(&limits.MaxHeight, "max-height", limits.MaxHeight, "tallest allowed README image, in pixels")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *budget == "" {
+		m, err := manifest.Load(*root)
+		if err != nil {
+			return err
+		}
+		if *budget = m.Tooling.VSIXBudget; *budget == "" {
+			return fmt.Errorf("no package size budget: set caffeinated.vsixBudget in package.json or pass -budget")
+		}
+	}
+	max, err := vsix.ParseSize(*budget)
+	if err != nil {
+		return err
+	}
+	failed := 0
+
+	problems, err := vsix.CheckImages(*root, *readme, limits)
+	if err != nil {
+		return err
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	if len(problems) == 0 {
+		fmt.Printf("%s images: ok\n", *readme)
+	} else {
+		failed++
+	}
+
+	pkg, err := vsix.Contents(*root)
+	if err != nil {
+		return err
+	}
+	saved := 0
+	for _, f := range pkg.Files {
+		if !strings.EqualFold(path.Ext(f.Path), ".png") {
+			continue
+		}
+		name := filepath.Join(*root, filepath.FromSlash(f.Path))
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		small, err := vsix.OptimizePNG(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Path, err)
+		}
+		if len(small) == len(data) {
+			continue
+		}
+		saved += len(data) - len(small)
+		if !*optimize {
+			fmt.Printf("%s: %s, %s after optimisation\n", f.Path, size(len(data)), size(len(small)))
+			continue
+		}
+		if err := os.WriteFile(name, small, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s, optimised to %s\n", f.Path, size(len(data)), size(len(small)))
+	}
+	if saved > 0 && !*optimize {
+		fmt.Printf("lossless optimisation would save %s; run with -optimize to rewrite the files\n", size(saved))
+	}
+	if *optimize && saved > 0 {
+		if pkg, err = vsix.Contents(*root); err != nil {
+			return err
+		}
+	}
+
+	var raw int64
+	for _, f := range pkg.Files {
+		raw += f.Size
+		if *list {
+			fmt.Printf("%9s %9s  %s\n", size(int(f.Size)), size(int(f.Compressed)), f.Path)
+		}
+	}
+	status := "ok"
+	if pkg.Size > max {
+		status = "over budget"
+		failed++
+	}
+	fmt.Printf("package: %d files, %s unpacked, about %s packed (budget %s): %s\n",
+		len(pkg.Files), size(int(raw)), size(int(pkg.Size)), size(int(max)), status)
+	if failed > 0 {
+		return fmt.Errorf("%d of 2 package checks failed", failed)
+	}
+	return nil
+}
+
+// size formats a byte count for people.
+func size(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fK", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
@@ -0,0 +1,333 @@
+// Package artwork generates backgrounds in a variant's palette: desktop
+// wallpapers, slide backgrounds and the marketplace banner. Artwork is built
+// from a seed, so the same seed gives the same picture at any resolution,
+// and every colour is darkened to stay under a luminance cap so that the
+// result sits behind dark windows and light text without glare.
+package artwork
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/bitmapfont"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+)
+
+// Patterns lists the pattern names Generate accepts.
+var Patterns = []string{"gradient", "geometric", "noise"}
+
+// Options describes one piece of artwork.
+type Options struct {
+	Width, Height int
+	Pattern       string
+	Seed          uint64
+	// Wordmark is drawn over the pattern when set; it is exempt from the
+	// luminance cap.
+	Wordmark string
+	// Centered places the wordmark in the middle rather than at the bottom
+	// left.
+	Centered bool
+	// MaxLuminance caps the WCAG relative luminance of every pixel of the
+	// pattern.
+	MaxLuminance float64
+}
+
+// Preset is a named size and style.
+type Preset struct {
+	Name    string
+	Purpose string
+	Options Options
+}
+
+// DefaultMaxLuminance keeps artwork within a few shades of the editor
+// background; the foreground text of the theme is about 0.85.
+const DefaultMaxLuminance = 0.05
+
+// Presets lists the built-in artwork. The banner is drawn on the editor
+// background, which package.json's galleryBanner.color repeats so that the
+// marketplace header around it matches.
+var Presets = []Preset{
+	{"wallpaper", "4K desktop wallpaper", Options{Width: 3840, Height: 2160, Pattern: "geometric"}},
+	{"slide", "16:9 slide background", Options{Width: 1920, Height: 1080, Pattern: "gradient"}},
+	{"banner", "README and marketplace banner", Options{Width: 1280, Height: 320, Pattern: "gradient", Wordmark: "name", Centered: true}},
+	{"social", "repository social preview", Options{Width: 1280, Height: 640, Pattern: "noise", Wordmark: "name"}},
+}
+
+// LookupPreset returns the preset with the given name.
+func LookupPreset(name string) (Preset, bool) {
+	for _, p := range Presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// Point is a position in pixels.
+type Point struct{ X, Y float64 }
+
+// Stop is a colour stop of a gradient, with an opacity.
+type Stop struct {
+	Offset float64
+	Color  colors.Color
+	Alpha  float64
+}
+
+// Linear is a linear gradient between two points.
+type Linear struct {
+	From, To Point
+	Stops    []Stop
+}
+
+// Glow is a radial gradient that fades to transparent at its radius.
+type Glow struct {
+	Center Point
+	Radius float64
+	Stops  []Stop
+}
+
+// Shape is a filled polygon with one or more contours, filled with the
+// even-odd rule so that an inner contour cuts a hole.
+type Shape struct {
+	Contours [][]Point
+	Color    colors.Color
+	Alpha    float64
+}
+
+// Noise is a layer of fractal value noise shading from one colour to
+// another, transparent where the noise is low.
+type Noise struct {
+	Seed    uint64
+	Cells   float64
+	Octaves int
+	From    colors.Color
+	To      colors.Color
+	Alpha   float64
+}
+
+// Pixel is a square of the wordmark.
+type Pixel struct {
+	X, Y, Size int
+	Color      colors.Color
+}
+
+// Scene is generated artwork, ready to render.
+type Scene struct {
+	Width, Height int
+	Background    Linear
+	Glows         []Glow
+	Shapes        []Shape
+	Noise         *Noise
+	Wordmark      []Pixel
+}
+
+// Generate builds the artwork described by opts from p. A Wordmark of
+// "name" is replaced by the palette's theme name.
+func Generate(p *palette.Palette, opts Options) (*Scene, error) {
+	if opts.Width < 16 || opts.Height < 16 {
+		return nil, fmt.Errorf("artwork: %dx%d is too small", opts.Width, opts.Height)
+	}
+	if opts.MaxLuminance <= 0 {
+		opts.MaxLuminance = DefaultMaxLuminance
+	}
+	bg := p.Color(palette.Background)
+	if bg.Luminance() > opts.MaxLuminance {
+		return nil, fmt.Errorf("artwork: the background %s is brighter than the luminance cap %.3f", bg.Hex(), opts.MaxLuminance)
+	}
+	g := &generator{
+		rng: rand.New(rand.NewPCG(opts.Seed, 0x9e3779b97f4a7c15)),
+		w:   float64(opts.Width), h: float64(opts.Height),
+		bg: bg,
+	}
+	for _, r := range []palette.Role{palette.Keyword, palette.String, palette.Function, palette.Type, palette.Accent} {
+		c := dim(p.Color(r), opts.MaxLuminance)
+		if !containsColor(g.accents, c) {
+			g.accents = append(g.accents, c)
+		}
+	}
+	s := &Scene{Width: opts.Width, Height: opts.Height}
+	s.Background = g.background()
+	switch opts.Pattern {
+	case "gradient":
+		s.Glows = g.glows(3+g.rng.IntN(3), 0.35, 0.75)
+	case "geometric":
+		s.Glows = g.glows(2, 0.2, 0.45)
+		s.Shapes = g.shapes()
+	case "noise":
+		s.Glows = g.glows(1, 0.2, 0.4)
+		s.Noise = &Noise{Seed: g.rng.Uint64(), Cells: 4 + 4*g.rng.Float64(), Octaves: 5, From: g.accent(), To: g.accent(), Alpha: 0.9}
+	default:
+		return nil, fmt.Errorf("artwork: unknown pattern %q (want one of %s)", opts.Pattern, strings.Join(Patterns, ", "))
+	}
+	if opts.Wordmark != "" {
+		text := opts.Wordmark
+		if text == "name" {
+			text = p.Name
+		}
+		s.Wordmark = wordmark(text, opts, p.Color(palette.Foreground), p.Color(palette.Keyword))
+	}
+	return s, nil
+}
+
+type generator struct {
+	rng     *rand.Rand
+	w, h    float64
+	bg      colors.Color
+	accents []colors.Color
+}
+
+func (g *generator) accent() colors.Color {
+	return g.accents[g.rng.IntN(len(g.accents))]
+}
+
+func (g *generator) between(lo, hi float64) float64 {
+	return lo + (hi-lo)*g.rng.Float64()
+}
+
+// background runs diagonally from the editor background into a tint of
+// one accent and back.
+func (g *generator) background() Linear {
+	tint := g.accent().WithAlpha(uint8(255 * g.between(0.25, 0.45))).Over(g.bg)
+	return Linear{
+		From: Point{0, 0}, To: Point{g.w, g.h},
+		Stops: []Stop{{0, g.bg, 1}, {0.5, tint, 1}, {1, g.bg, 1}},
+	}
+}
+
+// glows scatters n soft light pools with radii between lo and hi of the
+// longer side.
+func (g *generator) glows(n int, lo, hi float64) []Glow {
+	var out []Glow
+	size := math.Max(g.w, g.h)
+	for i := 0; i < n; i++ {
+		c, a := g.accent(), g.between(0.35, 0.7)
+		// A quadratic falloff, in stops that SVG can express.
+		var stops []Stop
+		for _, t := range []float64{0, 0.25, 0.5, 0.75, 1} {
+			stops = append(stops, Stop{t, c, a * (1 - t) * (1 - t)})
+		}
+		out = append(out, Glow{
+			Center: Point{g.w * g.rng.Float64(), g.h * g.rng.Float64()},
+			Radius: size * g.between(lo, hi),
+			Stops:  stops,
+		})
+	}
+	return out
+}
+
+// shapes draws parallel bands at one angle and a few rings and discs.
+func (g *generator) shapes() []Shape {
+	var out []Shape
+	size := math.Max(g.w, g.h)
+	angle := g.between(20, 40) * math.Pi / 180
+	if g.rng.IntN(2) == 0 {
+		angle = -angle
+	}
+	dir := Point{math.Cos(angle), math.Sin(angle)}
+	normal := Point{-dir.Y, dir.X}
+	reach := 2 * size
+	for i, n := 0, 5+g.rng.IntN(5); i < n; i++ {
+		// A band through a random point, as long as the diagonal in both
+		// directions so that it crosses the whole picture.
+		c := Point{g.w * g.rng.Float64(), g.h * g.rng.Float64()}
+		half := size * g.between(0.01, 0.08)
+		var quad []Point
+		for _, k := range [][2]float64{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+			quad = append(quad, Point{c.X + k[0]*reach*dir.X + k[1]*half*normal.X, c.Y + k[0]*reach*dir.Y + k[1]*half*normal.Y})
+		}
+		out = append(out, Shape{Contours: [][]Point{quad}, Color: g.accent(), Alpha: g.between(0.08, 0.25)})
+	}
+	for i, n := 0, 3+g.rng.IntN(4); i < n; i++ {
+		c := Point{g.w * g.rng.Float64(), g.h * g.rng.Float64()}
+		r := size * g.between(0.03, 0.18)
+		contours := [][]Point{circle(c, r)}
+		if g.rng.IntN(2) == 0 {
+			contours = append(contours, circle(c, r*g.between(0.75, 0.92)))
+		}
+		out = append(out, Shape{Contours: contours, Color: g.accent(), Alpha: g.between(0.1, 0.3)})
+	}
+	return out
+}
+
+// circle approximates a circle closely enough for any resolution used
+// here.
+func circle(c Point, r float64) []Point {
+	n := 96
+	pts := make([]Point, n)
+	for i := range pts {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		pts[i] = Point{c.X + r*math.Cos(a), c.Y + r*math.Sin(a)}
+	}
+	return pts
+}
+
+// dim lowers the OKLCH lightness of c, keeping its hue, until its
+// luminance is at most max.
+func dim(c colors.Color, max float64) colors.Color {
+	if c.Luminance() <= max {
+		return c
+	}
+	lch := c.OKLCH()
+	lo, hi := 0.0, lch.L
+	for i := 0; i < 24; i++ {
+		mid := (lo + hi) / 2
+		if colors.FromOKLCH(colors.OKLCH{L: mid, C: lch.C, H: lch.H}, 0xff).Luminance() <= max {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return colors.FromOKLCH(colors.OKLCH{L: lo, C: lch.C, H: lch.H}, 0xff)
+}
+
+func containsColor(cs []colors.Color, c colors.Color) bool {
+	for _, x := range cs {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+// wordmark lays text out in the bitmap font, the first word in the
+// foreground colour and the rest in the second colour.
+func wordmark(text string, opts Options, first, rest colors.Color) []Pixel {
+	const advance = bitmapfont.Width + 1
+	n := len([]rune(text))
+	height := 0.12
+	if opts.Centered {
+		height = 0.3
+	}
+	scale := int(float64(opts.Height) * height / bitmapfont.Height)
+	if fit := int(0.85 * float64(opts.Width) / float64(n*advance)); fit < scale {
+		scale = fit
+	}
+	scale = max(scale, 1)
+	w, h := (n*advance-1)*scale, bitmapfont.Height*scale
+	x, y := opts.Height/10, opts.Height-opts.Height/10-h
+	if opts.Centered {
+		x, y = (opts.Width-w)/2, (opts.Height-h)/2
+	}
+	var px []Pixel
+	color := first
+	for i, r := range []rune(text) {
+		if r == ' ' {
+			color = rest
+		}
+		g, ok := bitmapfont.Glyph(r)
+		if !ok {
+			continue
+		}
+		for col := 0; col < bitmapfont.Width; col++ {
+			for row := 0; row < bitmapfont.Height; row++ {
+				if g[col]>>row&1 != 0 {
+					px = append(px, Pixel{X: x + (i*advance+col)*scale, Y: y + row*scale, Size: scale, Color: color})
+				}
+			}
+		}
+	}
+	return px
+}
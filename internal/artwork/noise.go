@@ -0,0 +1,49 @@
+package artwork
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// valueNoise is lattice value noise: random values at integer points,
+// smoothly interpolated, tiled every 256 cells.
+type valueNoise struct {
+	perm   [512]uint8
+	values [256]float64
+}
+
+func newValueNoise(seed uint64) *valueNoise {
+	rng := rand.New(rand.NewPCG(seed, 0xda3e39cb94b95bdb))
+	n := &valueNoise{}
+	for i, p := range rng.Perm(256) {
+		n.perm[i], n.perm[i+256] = uint8(p), uint8(p)
+		n.values[i] = rng.Float64()
+	}
+	return n
+}
+
+func (n *valueNoise) lattice(x, y int) float64 {
+	return n.values[n.perm[int(n.perm[x&255])+y&255]]
+}
+
+func (n *valueNoise) at(x, y float64) float64 {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+	sx, sy := fx*fx*(3-2*fx), fy*fy*(3-2*fy)
+	top := n.lattice(ix, iy) + (n.lattice(ix+1, iy)-n.lattice(ix, iy))*sx
+	bottom := n.lattice(ix, iy+1) + (n.lattice(ix+1, iy+1)-n.lattice(ix, iy+1))*sx
+	return top + (bottom-top)*sy
+}
+
+// fbm sums octaves of noise at doubling frequency and halving amplitude,
+// normalised to [0, 1].
+func (n *valueNoise) fbm(x, y float64, octaves int) float64 {
+	var sum, amp, total float64 = 0, 1, 0
+	for i := 0; i < octaves; i++ {
+		sum += n.at(x, y) * amp
+		total += amp
+		x, y, amp = x*2+17.1, y*2+31.7, amp/2
+	}
+	return sum / total
+}
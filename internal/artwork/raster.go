@@ -0,0 +1,228 @@
+package artwork
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+)
+
+// canvas holds colours as non-linear sRGB fractions, the space SVG
+// renderers blend in by default, so that PNG and SVG output agree.
+type canvas struct {
+	w, h int
+	pix  []float64
+}
+
+func newCanvas(w, h int) *canvas {
+	return &canvas{w: w, h: h, pix: make([]float64, 3*w*h)}
+}
+
+func rgb(c colors.Color) [3]float64 {
+	return [3]float64{float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255}
+}
+
+// blend paints c with opacity a over pixel i.
+func (cv *canvas) blend(i int, c [3]float64, a float64) {
+	if a <= 0 {
+		return
+	}
+	a = math.Min(a, 1)
+	p := cv.pix[3*i : 3*i+3]
+	for k := range p {
+		p[k] += (c[k] - p[k]) * a
+	}
+}
+
+// at interpolates stops at offset t.
+func at(stops []Stop, t float64) ([3]float64, float64) {
+	if t <= stops[0].Offset {
+		return rgb(stops[0].Color), stops[0].Alpha
+	}
+	for i := 1; i < len(stops); i++ {
+		if t <= stops[i].Offset {
+			a, b := stops[i-1], stops[i]
+			f := (t - a.Offset) / (b.Offset - a.Offset)
+			ca, cb := rgb(a.Color), rgb(b.Color)
+			var c [3]float64
+			for k := range c {
+				c[k] = ca[k] + (cb[k]-ca[k])*f
+			}
+			return c, a.Alpha + (b.Alpha-a.Alpha)*f
+		}
+	}
+	last := stops[len(stops)-1]
+	return rgb(last.Color), last.Alpha
+}
+
+func (cv *canvas) linear(g Linear) {
+	dx, dy := g.To.X-g.From.X, g.To.Y-g.From.Y
+	d2 := dx*dx + dy*dy
+	for y := 0; y < cv.h; y++ {
+		for x := 0; x < cv.w; x++ {
+			t := ((float64(x)+0.5-g.From.X)*dx + (float64(y)+0.5-g.From.Y)*dy) / d2
+			c, a := at(g.Stops, t)
+			cv.blend(y*cv.w+x, c, a)
+		}
+	}
+}
+
+func (cv *canvas) glow(g Glow) {
+	x0, x1 := max(0, int(g.Center.X-g.Radius)), min(cv.w, int(g.Center.X+g.Radius)+1)
+	y0, y1 := max(0, int(g.Center.Y-g.Radius)), min(cv.h, int(g.Center.Y+g.Radius)+1)
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			t := math.Hypot(float64(x)+0.5-g.Center.X, float64(y)+0.5-g.Center.Y) / g.Radius
+			if t < 1 {
+				c, a := at(g.Stops, t)
+				cv.blend(y*cv.w+x, c, a)
+			}
+		}
+	}
+}
+
+// subRows is the number of scanlines sampled per pixel row; horizontal
+// coverage is computed exactly.
+const subRows = 4
+
+// shape fills s with the even-odd rule and antialiased edges.
+func (cv *canvas) shape(s Shape) {
+	type edge struct{ x0, y0, x1, y1 float64 }
+	var edges []edge
+	minY, maxY := math.Inf(1), math.Inf(-1)
+	for _, c := range s.Contours {
+		for i, p := range c {
+			q := c[(i+1)%len(c)]
+			if p.Y != q.Y {
+				edges = append(edges, edge{p.X, p.Y, q.X, q.Y})
+			}
+			minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+		}
+	}
+	cover := make([]float64, cv.w)
+	col := rgb(s.Color)
+	var xs []float64
+	for y := max(0, int(minY)); y < min(cv.h, int(maxY)+1); y++ {
+		clear(cover)
+		touched := false
+		for k := 0; k < subRows; k++ {
+			sy := float64(y) + (float64(k)+0.5)/subRows
+			xs = xs[:0]
+			for _, e := range edges {
+				if (sy >= e.y0) != (sy >= e.y1) {
+					xs = append(xs, e.x0+(sy-e.y0)*(e.x1-e.x0)/(e.y1-e.y0))
+				}
+			}
+			sort.Float64s(xs)
+			for i := 0; i+1 < len(xs); i += 2 {
+				span(cover, xs[i], xs[i+1], 1.0/subRows)
+				touched = true
+			}
+		}
+		if !touched {
+			continue
+		}
+		for x, c := range cover {
+			cv.blend(y*cv.w+x, col, c*s.Alpha)
+		}
+	}
+}
+
+// span adds weight times the covered fraction of each pixel between a
+// and b.
+func span(cover []float64, a, b, weight float64) {
+	a, b = math.Max(a, 0), math.Min(b, float64(len(cover)))
+	for x := int(a); x < len(cover) && float64(x) < b; x++ {
+		lo, hi := math.Max(a, float64(x)), math.Min(b, float64(x+1))
+		if hi > lo {
+			cover[x] += (hi - lo) * weight
+		}
+	}
+}
+
+func (cv *canvas) noise(n *Noise) {
+	from, to := rgb(n.From), rgb(n.To)
+	vn := newValueNoise(n.Seed)
+	scale := n.Cells / float64(cv.w)
+	for y := 0; y < cv.h; y++ {
+		for x := 0; x < cv.w; x++ {
+			v := vn.fbm(float64(x)*scale, float64(y)*scale, n.Octaves)
+			var c [3]float64
+			for k := range c {
+				c[k] = from[k] + (to[k]-from[k])*v
+			}
+			cv.blend(y*cv.w+x, c, n.Alpha*smoothstep(0.4, 0.8, v))
+		}
+	}
+}
+
+func smoothstep(lo, hi, v float64) float64 {
+	t := math.Max(0, math.Min(1, (v-lo)/(hi-lo)))
+	return t * t * (3 - 2*t)
+}
+
+func (cv *canvas) image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, cv.w, cv.h))
+	for i := 0; i < cv.w*cv.h; i++ {
+		for k := 0; k < 3; k++ {
+			img.Pix[4*i+k] = uint8(math.Round(math.Max(0, math.Min(1, cv.pix[3*i+k])) * 255))
+		}
+		img.Pix[4*i+3] = 0xff
+	}
+	return img
+}
+
+// Report describes the rendered pattern, before the wordmark is drawn.
+type Report struct {
+	MaxLuminance  float64
+	MeanLuminance float64
+}
+
+// luminanceSlack allows for rounding the pattern to 8 bits per channel.
+const luminanceSlack = 0.001
+
+// Within reports whether the pattern stays under a luminance cap.
+func (r Report) Within(max float64) bool {
+	return r.MaxLuminance <= max+luminanceSlack
+}
+
+// luminance measures the pattern.
+func luminance(img *image.NRGBA) Report {
+	var r Report
+	n := 0
+	for i := 0; i < len(img.Pix); i += 4 {
+		l := colors.Color{R: img.Pix[i], G: img.Pix[i+1], B: img.Pix[i+2], A: 0xff}.Luminance()
+		r.MaxLuminance = math.Max(r.MaxLuminance, l)
+		r.MeanLuminance += l
+		n++
+	}
+	r.MeanLuminance /= float64(n)
+	return r
+}
+
+// Raster renders s and reports the luminance of the pattern.
+func Raster(s *Scene) (*image.NRGBA, Report) {
+	cv := newCanvas(s.Width, s.Height)
+	cv.linear(s.Background)
+	for _, g := range s.Glows {
+		cv.glow(g)
+	}
+	for _, sh := range s.Shapes {
+		cv.shape(sh)
+	}
+	if s.Noise != nil {
+		cv.noise(s.Noise)
+	}
+	img := cv.image()
+	r := luminance(img)
+	for _, p := range s.Wordmark {
+		c := p.Color.NRGBA()
+		for y := p.Y; y < p.Y+p.Size; y++ {
+			for x := p.X; x < p.X+p.Size; x++ {
+				img.SetNRGBA(x, y, c)
+			}
+		}
+	}
+	return img, r
+}
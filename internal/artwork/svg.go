@@ -0,0 +1,91 @@
+package artwork
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+)
+
+// SVG renders s as an SVG document. Gradients and shapes stay vectors, so
+// the picture scales; a noise layer has no SVG equivalent that renders the
+// same everywhere, so a scene with noise embeds its pattern as a PNG and
+// only the wordmark stays vector.
+func SVG(s *Scene) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", s.Width, s.Height, s.Width, s.Height)
+	if s.Noise != nil {
+		pattern := *s
+		pattern.Wordmark = nil
+		img, _ := Raster(&pattern)
+		var data bytes.Buffer
+		if err := png.Encode(&data, img); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "<image width=\"%d\" height=\"%d\" xlink:href=\"data:image/png;base64,%s\"/>\n", s.Width, s.Height, base64.StdEncoding.EncodeToString(data.Bytes()))
+	} else {
+		b.WriteString("<defs>\n")
+		g := s.Background
+		fmt.Fprintf(&b, "<linearGradient id=\"bg\" gradientUnits=\"userSpaceOnUse\" x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\">\n", num(g.From.X), num(g.From.Y), num(g.To.X), num(g.To.Y))
+		stops(&b, g.Stops)
+		b.WriteString("</linearGradient>\n")
+		for i, g := range s.Glows {
+			fmt.Fprintf(&b, "<radialGradient id=\"glow%d\" gradientUnits=\"userSpaceOnUse\" cx=\"%s\" cy=\"%s\" r=\"%s\">\n", i, num(g.Center.X), num(g.Center.Y), num(g.Radius))
+			stops(&b, g.Stops)
+			b.WriteString("</radialGradient>\n")
+		}
+		b.WriteString("</defs>\n")
+		fmt.Fprintf(&b, "<rect width=\"%d\" height=\"%d\" fill=\"url(#bg)\"/>\n", s.Width, s.Height)
+		for i, g := range s.Glows {
+			fmt.Fprintf(&b, "<circle cx=\"%s\" cy=\"%s\" r=\"%s\" fill=\"url(#glow%d)\"/>\n", num(g.Center.X), num(g.Center.Y), num(g.Radius), i)
+		}
+		for _, sh := range s.Shapes {
+			var d strings.Builder
+			for _, c := range sh.Contours {
+				for i, p := range c {
+					if i == 0 {
+						d.WriteString("M")
+					} else {
+						d.WriteString(" L")
+					}
+					d.WriteString(num(p.X) + " " + num(p.Y))
+				}
+				d.WriteString(" Z")
+			}
+			fmt.Fprintf(&b, "<path d=\"%s\" fill=\"%s\" fill-opacity=\"%s\" fill-rule=\"evenodd\"/>\n", d.String(), sh.Color.Opaque().Hex(), num(sh.Alpha))
+		}
+	}
+	// One path per colour, of unit squares, keeps the wordmark small.
+	var order []colors.Color
+	paths := map[colors.Color]*strings.Builder{}
+	for _, p := range s.Wordmark {
+		d, ok := paths[p.Color]
+		if !ok {
+			d = &strings.Builder{}
+			paths[p.Color] = d
+			order = append(order, p.Color)
+		}
+		fmt.Fprintf(d, "M%d %dh%dv%dh-%dz", p.X, p.Y, p.Size, p.Size, p.Size)
+	}
+	for _, c := range order {
+		fmt.Fprintf(&b, "<path d=\"%s\" fill=\"%s\" shape-rendering=\"crispEdges\"/>\n", paths[c].String(), c.Opaque().Hex())
+	}
+	b.WriteString("</svg>\n")
+	return b.Bytes(), nil
+}
+
+func stops(b *bytes.Buffer, ss []Stop) {
+	for _, s := range ss {
+		fmt.Fprintf(b, "<stop offset=\"%s\" stop-color=\"%s\" stop-opacity=\"%s\"/>\n", num(s.Offset), s.Color.Opaque().Hex(), num(s.Alpha))
+	}
+}
+
+// num formats a coordinate or opacity to thousandths.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1000)/1000, 'f', -1, 64)
+}
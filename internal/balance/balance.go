@@ -0,0 +1,156 @@
+// Package balance measures how evenly the syntax colours of a theme stand
+// out from the editor background. Accents that differ widely in OKLCH
+// lightness do not read as equals: dark ones recede and light ones shout,
+// whatever their hue. The check reports each role's lightness and chroma,
+// flags accents outside a band around a target lightness, and suggests the
+// colour with the target lightness and the same hue.
+package balance
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+)
+
+// Options configures the check.
+type Options struct {
+	// Band is the width of the accepted lightness band, centred on the
+	// target.
+	Band float64
+	// Target is the lightness accents should have. Zero uses the median
+	// lightness of the accents.
+	Target float64
+	// Neutrals also balances syntax roles without a hue, such as variables
+	// and comments, which are usually meant to sit apart from the accents.
+	Neutrals bool
+}
+
+// DefaultOptions accepts accents within ±0.05 of their median lightness,
+// about two and a half just noticeable differences.
+var DefaultOptions = Options{Band: 0.10}
+
+// Role is the measurement of one syntax role.
+type Role struct {
+	Role  palette.Role
+	Color colors.Color
+	LCH   colors.OKLCH
+	// Accent is set for roles that are balanced: chromatic ones, and
+	// neutral ones with Options.Neutrals.
+	Accent bool
+	// Offset is the lightness difference from the target.
+	Offset float64
+	// Outside is set for accents whose lightness is outside the band.
+	Outside bool
+	// Suggest is the colour with the target lightness and the same hue,
+	// for roles outside the band.
+	Suggest colors.Color
+	// Clipped is set when the suggestion had to give up chroma to stay in
+	// sRGB.
+	Clipped bool
+}
+
+// Spread summarises the lightness and chroma of the accents.
+type Spread struct {
+	MinL, MaxL, MeanL, StdL float64
+	MinC, MaxC              float64
+}
+
+// Report is the result of the check.
+type Report struct {
+	Background colors.Color
+	Target     float64
+	Band       float64
+	Roles      []Role
+	Spread     Spread
+}
+
+// Outside returns the roles outside the band.
+func (r *Report) Outside() []Role {
+	var out []Role
+	for _, role := range r.Roles {
+		if role.Outside {
+			out = append(out, role)
+		}
+	}
+	return out
+}
+
+// Check measures the syntax roles of p.
+func Check(p *palette.Palette, opts Options) (*Report, error) {
+	if opts.Band <= 0 {
+		return nil, fmt.Errorf("balance: band must be positive")
+	}
+	r := &Report{Background: p.Color(palette.Background), Band: opts.Band}
+	// Roles sharing a colour count once, so that the target is not pulled
+	// towards whichever colour is reused most.
+	var ls []float64
+	seen := map[colors.Color]bool{}
+	for _, d := range palette.Definitions {
+		if d.Kind != palette.Syntax {
+			continue
+		}
+		c := p.Color(d.Role).Over(r.Background)
+		role := Role{Role: d.Role, Color: c, LCH: c.OKLCH()}
+		role.Accent = opts.Neutrals || c.Chromatic()
+		if role.Accent && !seen[c] {
+			seen[c] = true
+			ls = append(ls, role.LCH.L)
+		}
+		r.Roles = append(r.Roles, role)
+	}
+	if len(ls) == 0 {
+		return r, nil
+	}
+
+	r.Target = opts.Target
+	if r.Target == 0 {
+		r.Target = median(ls)
+	}
+	r.Spread = spread(r.Roles, ls)
+	for i := range r.Roles {
+		role := &r.Roles[i]
+		role.Offset = role.LCH.L - r.Target
+		if !role.Accent || math.Abs(role.Offset) <= opts.Band/2 {
+			continue
+		}
+		role.Outside = true
+		want := colors.OKLCH{L: r.Target, C: role.LCH.C, H: role.LCH.H}
+		role.Suggest = colors.FromOKLCH(want, 0xff)
+		role.Clipped = role.LCH.C-role.Suggest.OKLCH().C > 0.005
+	}
+	return r, nil
+}
+
+func median(vs []float64) float64 {
+	s := append([]float64(nil), vs...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+// spread summarises the accents, given the lightness of each distinct
+// accent colour.
+func spread(roles []Role, ls []float64) Spread {
+	s := Spread{MinL: math.Inf(1), MaxL: math.Inf(-1), MinC: math.Inf(1), MaxC: math.Inf(-1)}
+	for _, r := range roles {
+		if r.Accent {
+			s.MinL, s.MaxL = math.Min(s.MinL, r.LCH.L), math.Max(s.MaxL, r.LCH.L)
+			s.MinC, s.MaxC = math.Min(s.MinC, r.LCH.C), math.Max(s.MaxC, r.LCH.C)
+		}
+	}
+	for _, l := range ls {
+		s.MeanL += l
+	}
+	s.MeanL /= float64(len(ls))
+	for _, l := range ls {
+		s.StdL += (l - s.MeanL) * (l - s.MeanL)
+	}
+	s.StdL = math.Sqrt(s.StdL / float64(len(ls)))
+	return s
+}
@@ -0,0 +1,131 @@
+package balance
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func check(t *testing.T, opts Options) *Report {
+	t.Helper()
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := Check(p, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func role(t *testing.T, r *Report, name palette.Role) Role {
+	t.Helper()
+	for _, role := range r.Roles {
+		if role.Role == name {
+			return role
+		}
+	}
+	t.Fatalf("no %s role", name)
+	return Role{}
+}
+
+// hueDiff is the angle between two hues in degrees.
+func hueDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
+
+// TestMeasure pins the OKLCH measurements of a few roles of the default
+// variant, taken on the editor background.
+func TestMeasure(t *testing.T) {
+	r := check(t, DefaultOptions)
+	for _, tc := range []struct {
+		role    palette.Role
+		l, c, h float64
+		accent  bool
+	}{
+		{palette.Keyword, 0.535, 0.163, 39.5, true},
+		{palette.String, 0.783, 0.121, 48.2, true},
+		{palette.Type, 0.745, 0.133, 255.0, true},
+		{palette.Comment, 0.531, 0, 0, false},
+		{palette.Variable, 0.946, 0, 0, false},
+	} {
+		got := role(t, r, tc.role)
+		if math.Abs(got.LCH.L-tc.l) > 0.001 || math.Abs(got.LCH.C-tc.c) > 0.001 {
+			t.Errorf("%s: L %.3f C %.3f, want L %.3f C %.3f", tc.role, got.LCH.L, got.LCH.C, tc.l, tc.c)
+		}
+		if tc.accent && hueDiff(got.LCH.H, tc.h) > 0.1 {
+			t.Errorf("%s: hue %.1f, want %.1f", tc.role, got.LCH.H, tc.h)
+		}
+		if got.Accent != tc.accent {
+			t.Errorf("%s: accent %v, want %v", tc.role, got.Accent, tc.accent)
+		}
+	}
+	// The target is the median of the distinct accent colours, which here
+	// is the property and tag green.
+	if math.Abs(r.Target-role(t, r, palette.Property).LCH.L) > 1e-9 {
+		t.Errorf("target %.3f, want the property lightness", r.Target)
+	}
+}
+
+// TestOutside pins the roles of the committed theme outside the default
+// band: the rust keyword is much darker than the other accents, and the
+// function and punctuation yellow a little lighter.
+func TestOutside(t *testing.T) {
+	var names []string
+	for _, role := range check(t, DefaultOptions).Outside() {
+		names = append(names, string(role.Role))
+	}
+	if got := strings.Join(names, " "); got != "keyword function punctuation" {
+		t.Errorf("outside the band: %s, want keyword function punctuation", got)
+	}
+}
+
+// TestSuggest checks that every suggestion, once on the background, is
+// inside the band with the hue of the role it replaces, and keeps its
+// chroma unless it was clipped.
+func TestSuggest(t *testing.T) {
+	for _, opts := range []Options{DefaultOptions, {Band: 0.04, Target: 0.7}, {Band: 0.1, Neutrals: true}} {
+		r := check(t, opts)
+		if opts.Target != 0 && r.Target != opts.Target {
+			t.Errorf("target %.3f, want %.3f", r.Target, opts.Target)
+		}
+		if len(r.Outside()) == 0 {
+			t.Errorf("%+v: no role outside the band to suggest a colour for", opts)
+		}
+		for _, role := range r.Outside() {
+			got := role.Suggest.Over(r.Background).OKLCH()
+			if math.Abs(got.L-r.Target) > r.Band/2 {
+				t.Errorf("%+v: %s suggestion %s has L %.3f, outside %.3f ± %.3f", opts, role.Role, role.Suggest.Hex(), got.L, r.Target, r.Band/2)
+			}
+			if role.LCH.C > 0.02 && hueDiff(got.H, role.LCH.H) > 1 {
+				t.Errorf("%+v: %s suggestion %s has hue %.1f, want %.1f", opts, role.Role, role.Suggest.Hex(), got.H, role.LCH.H)
+			}
+			if !role.Clipped && math.Abs(got.C-role.LCH.C) > 0.005 {
+				t.Errorf("%+v: %s suggestion %s has chroma %.3f, want %.3f", opts, role.Role, role.Suggest.Hex(), got.C, role.LCH.C)
+			}
+		}
+	}
+}
+
+func TestBand(t *testing.T) {
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Check(p, Options{}); err == nil {
+		t.Error("Check with no band succeeded")
+	}
+}
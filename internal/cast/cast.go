@@ -0,0 +1,245 @@
+// Package cast replays asciinema recordings (.cast files, format version 2)
+// through a terminal emulator and renders them in the theme's terminal
+// colours, so that documentation recordings look the way they do in the
+// integrated terminal rather than in the viewer's default palette.
+package cast
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/vt"
+)
+
+// Ext is the extension of recordings.
+const Ext = ".cast"
+
+// Header is the first line of a recording.
+type Header struct {
+	Version int `json:"version"`
+	Width   int `json:"width"`
+	Height  int `json:"height"`
+	// IdleTimeLimit caps the pauses between events, in seconds, when set.
+	IdleTimeLimit float64 `json:"idle_time_limit,omitempty"`
+	Title         string  `json:"title,omitempty"`
+}
+
+// Event is one recorded event. Output ("o") is what the terminal displays;
+// input ("i") and markers ("m") do not change the screen; resizes ("r")
+// carry "COLSxROWS".
+type Event struct {
+	Time float64
+	Type string
+	Data string
+}
+
+// Cast is a parsed recording.
+type Cast struct {
+	Header Header
+	Events []Event
+}
+
+// Load parses the recording at path.
+func Load(path string) (*Cast, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse reads a recording: a JSON header line followed by one JSON array
+// [time, type, data] per line.
+func Parse(r io.Reader) (*Cast, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 16<<20)
+	c := &Cast{}
+	n := 0
+	for sc.Scan() {
+		n++
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if c.Header.Version == 0 {
+			if err := json.Unmarshal(line, &c.Header); err != nil {
+				return nil, fmt.Errorf("line %d: header: %w", n, err)
+			}
+			if c.Header.Version != 2 {
+				return nil, fmt.Errorf("line %d: version %d, want 2", n, c.Header.Version)
+			}
+			if c.Header.Width < 1 || c.Header.Height < 1 {
+				return nil, fmt.Errorf("line %d: header has no terminal size", n)
+			}
+			continue
+		}
+		var raw []json.RawMessage
+		if err := json.Unmarshal(line, &raw); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		if len(raw) != 3 {
+			return nil, fmt.Errorf("line %d: event has %d fields, want 3", n, len(raw))
+		}
+		var e Event
+		if err := json.Unmarshal(raw[0], &e.Time); err != nil {
+			return nil, fmt.Errorf("line %d: time: %w", n, err)
+		}
+		if err := json.Unmarshal(raw[1], &e.Type); err != nil {
+			return nil, fmt.Errorf("line %d: type: %w", n, err)
+		}
+		if err := json.Unmarshal(raw[2], &e.Data); err != nil {
+			return nil, fmt.Errorf("line %d: data: %w", n, err)
+		}
+		c.Events = append(c.Events, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if c.Header.Version == 0 {
+		return nil, fmt.Errorf("no header")
+	}
+	return c, nil
+}
+
+// Options controls how a recording is cut into frames.
+type Options struct {
+	// Idle caps pauses between events, in seconds; the header's own limit
+	// wins when it is lower. Zero keeps the recorded pauses.
+	Idle float64
+	// Interval is the shortest time between frames, in seconds. Output
+	// arriving faster is coalesced into one frame.
+	Interval float64
+	// Hold is how long the final frame stays up before an animation loops.
+	Hold float64
+}
+
+// DefaultOptions renders at most 30 frames a second and shortens pauses to
+// two seconds.
+var DefaultOptions = Options{Idle: 2, Interval: 1.0 / 30, Hold: 3}
+
+// Frame is the screen at a moment of the replay.
+type Frame struct {
+	// Time is when the frame appears, in seconds from the start.
+	Time float64
+	// Duration is how long it stays up.
+	Duration float64
+	Screen   *Screen
+}
+
+// Play replays c and returns its frames. The last one is the final screen.
+func Play(c *Cast, opts Options) []Frame {
+	idle := opts.Idle
+	if l := c.Header.IdleTimeLimit; l > 0 && (idle <= 0 || l < idle) {
+		idle = l
+	}
+	term := vt.New(c.Header.Width, c.Header.Height)
+	frames := []Frame{{Screen: Snapshot(term)}}
+	// pending is when the output not yet in a frame started arriving.
+	var clock, last, pending float64
+	dirty := false
+	snap := func() {
+		if n := len(frames); frames[n-1].Time >= pending {
+			frames = frames[:n-1]
+		}
+		frames = append(frames, Frame{Time: pending, Screen: Snapshot(term)})
+		dirty = false
+	}
+	for _, e := range c.Events {
+		gap := max(e.Time-last, 0)
+		last = e.Time
+		if idle > 0 && gap > idle {
+			gap = idle
+		}
+		clock += gap
+		if dirty && clock-pending >= opts.Interval {
+			snap()
+		}
+		switch e.Type {
+		case "o":
+			term.WriteString(e.Data)
+		case "r":
+			w, h, ok := parseSize(e.Data)
+			if !ok {
+				continue
+			}
+			term.Resize(w, h)
+		default:
+			continue
+		}
+		if !dirty {
+			pending, dirty = clock, true
+		}
+	}
+	if dirty {
+		snap()
+	}
+	for i := range frames {
+		if i+1 < len(frames) {
+			frames[i].Duration = frames[i+1].Time - frames[i].Time
+		} else {
+			frames[i].Duration = opts.Hold
+		}
+	}
+	return dedupe(frames)
+}
+
+// dedupe merges consecutive identical frames.
+func dedupe(frames []Frame) []Frame {
+	var out []Frame
+	for _, f := range frames {
+		if n := len(out); n > 0 && out[n-1].Screen.Equal(f.Screen) {
+			out[n-1].Duration += f.Duration
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func parseSize(s string) (w, h int, ok bool) {
+	ws, hs, found := strings.Cut(s, "x")
+	if !found {
+		return 0, 0, false
+	}
+	w, err1 := strconv.Atoi(ws)
+	h, err2 := strconv.Atoi(hs)
+	return w, h, err1 == nil && err2 == nil
+}
+
+// Marker is the screen at a marker event, which asciinema records when
+// the user presses the marker key or the recording script asks for one.
+type Marker struct {
+	Label  string
+	Screen *Screen
+}
+
+// Markers replays c and returns the screen at each marker, followed by the
+// final screen labelled "end".
+func Markers(c *Cast) []Marker {
+	term := vt.New(c.Header.Width, c.Header.Height)
+	var out []Marker
+	for _, e := range c.Events {
+		switch e.Type {
+		case "o":
+			term.WriteString(e.Data)
+		case "r":
+			if w, h, ok := parseSize(e.Data); ok {
+				term.Resize(w, h)
+			}
+		case "m":
+			out = append(out, Marker{Label: e.Data, Screen: Snapshot(term)})
+		}
+	}
+	return append(out, Marker{Label: "end", Screen: Snapshot(term)})
+}
@@ -0,0 +1,59 @@
+package cast
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// TestRender plays and renders every fixture recording, so that the
+// renderers see the sequences the emulator tests cover.
+func TestRender(t *testing.T) {
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eff, err := colorreg.Resolve(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	colors, err := TerminalColors(eff, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, err := filepath.Glob(filepath.Join("..", "..", "testdata", "casts", "*"+Ext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no fixture recordings")
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			c, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			frames := Play(c, DefaultOptions)
+			markers := Markers(c)
+			if !frames[len(frames)-1].Screen.Equal(markers[len(markers)-1].Screen) {
+				t.Error("the last frame is not the final screen")
+			}
+			for _, format := range Formats {
+				if _, err := Render(format, frames, colors, c.Header.Title); err != nil {
+					t.Errorf("%s: %v", format, err)
+				}
+			}
+			if _, err := PNG(frames[len(frames)-1].Screen, colors); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package cast
+
+import (
+	"fmt"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/vt"
+)
+
+// Colors is the palette of VS Code's integrated terminal.
+type Colors struct {
+	ANSI       [16]colors.Color
+	Foreground colors.Color
+	Background colors.Color
+	// Cursor is the block cursor; CursorText is the character under it.
+	Cursor     colors.Color
+	CursorText colors.Color
+}
+
+// TerminalColors reads the terminal colours of a variant. Like VS Code, it
+// falls back to the panel background when terminal.background is unset,
+// and to the foreground and background for the cursor.
+func TerminalColors(eff *colorreg.Effective, p *palette.Palette) (Colors, error) {
+	editor := p.Color(palette.Background)
+	get := func(ids ...string) (colors.Color, error) {
+		for _, id := range ids {
+			if c, ok := eff.Color(id); ok {
+				return c.Over(editor), nil
+			}
+		}
+		return colors.Color{}, fmt.Errorf("cast: the theme sets none of %v", ids)
+	}
+	c := Colors{ANSI: p.ANSI()}
+	var err error
+	if c.Background, err = get("terminal.background", "panel.background", "editor.background"); err != nil {
+		return c, err
+	}
+	if c.Foreground, err = get("terminal.foreground"); err != nil {
+		return c, err
+	}
+	if c.Cursor, err = get("terminalCursor.foreground", "terminal.foreground"); err != nil {
+		return c, err
+	}
+	if c.CursorText, err = get("terminalCursor.background", "terminal.background", "panel.background"); err != nil {
+		return c, err
+	}
+	for i := range c.ANSI {
+		c.ANSI[i] = c.ANSI[i].Over(c.Background)
+	}
+	return c, nil
+}
+
+// Indexed returns colour i of the 256 colour palette: the 16 theme
+// colours, then xterm's colour cube and grey ramp, which VS Code does not
+// let themes change.
+func (c Colors) Indexed(i uint8) colors.Color {
+	if i < 16 {
+		return c.ANSI[i]
+	}
+	return colors.Xterm(i)
+}
+
+// cell is a screen cell with its colours resolved.
+type cell struct {
+	r      rune
+	fg, bg colors.Color
+	attr   vt.Attr
+}
+
+// resolve applies the palette to s. Bold text in one of the first eight
+// colours is drawn in the bright variant, as VS Code does by default;
+// faint text is blended halfway into its background.
+func (c Colors) resolve(s *Screen) [][]cell {
+	out := make([][]cell, s.Height)
+	for y, line := range s.Cells {
+		out[y] = make([]cell, s.Width)
+		for x, vc := range line {
+			fg, bg := c.Foreground, c.Background
+			switch vc.FG.Kind {
+			case vt.Indexed:
+				i := vc.FG.Index
+				if vc.Attr&vt.Bold != 0 && i < 8 {
+					i += 8
+				}
+				fg = c.Indexed(i)
+			case vt.RGB:
+				fg = colors.Color{R: vc.FG.R, G: vc.FG.G, B: vc.FG.B, A: 0xff}
+			}
+			switch vc.BG.Kind {
+			case vt.Indexed:
+				bg = c.Indexed(vc.BG.Index)
+			case vt.RGB:
+				bg = colors.Color{R: vc.BG.R, G: vc.BG.G, B: vc.BG.B, A: 0xff}
+			}
+			if vc.Attr&vt.Inverse != 0 {
+				fg, bg = bg, fg
+			}
+			if vc.Attr&vt.Faint != 0 {
+				fg = fg.WithAlpha(0x80).Over(bg)
+			}
+			if vc.Attr&vt.Hidden != 0 {
+				fg = bg
+			}
+			if s.CursorVisible && x == s.CursorX && y == s.CursorY {
+				fg, bg = c.CursorText, c.Cursor
+			}
+			out[y][x] = cell{r: vc.Rune, fg: fg, bg: bg, attr: vc.Attr}
+		}
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package cast
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/bitmapfont"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/vt"
+)
+
+// The raster renderers draw text with the built-in 5×7 bitmap font scaled
+// up by two, so that images come out the same on every machine and the
+// tool needs no font files. Box drawing and block characters, which
+// terminal UIs lean on, are drawn from their geometry instead.
+const (
+	glyphScale = 2
+	cellW      = 6 * glyphScale
+	cellH      = 10 * glyphScale
+	// glyphTop is the row, in font pixels, where the 7 rows of a glyph start.
+	glyphTop = 1
+)
+
+// drawCell paints one cell at pixel x, y of img.
+func drawCell(img *image.RGBA, x, y int, c cell) {
+	fill(img, x, y, cellW, cellH, c.bg.NRGBA())
+	fg := c.fg.NRGBA()
+	switch {
+	case c.r >= 0x2500 && c.r <= 0x257F:
+		boxDrawing(img, x, y, c.r, fg)
+	case c.r >= 0x2580 && c.r <= 0x259F:
+		block(img, x, y, c.r, fg)
+	default:
+		g, ok := bitmapfont.Glyph(c.r)
+		if !ok {
+			// Draw a hollow box for characters the font lacks, as a
+			// terminal does, rather than dropping them silently.
+			outline(img, x+glyphScale, y+glyphTop*glyphScale, bitmapfont.Width*glyphScale, bitmapfont.Height*glyphScale, fg)
+			break
+		}
+		italic := c.attr&vt.Italic != 0
+		for col := 0; col < bitmapfont.Width; col++ {
+			for row := 0; row < bitmapfont.Height; row++ {
+				if g[col]>>row&1 == 0 {
+					continue
+				}
+				px := x + col*glyphScale
+				if italic {
+					// Slant by shifting the upper rows right.
+					px += (6 - row) / 3
+				}
+				fill(img, px, y+(row+glyphTop)*glyphScale, glyphScale, glyphScale, fg)
+				if c.attr&vt.Bold != 0 {
+					fill(img, px+1, y+(row+glyphTop)*glyphScale, glyphScale, glyphScale, fg)
+				}
+			}
+		}
+	}
+	if c.attr&vt.Underline != 0 {
+		fill(img, x, y+cellH-2*glyphScale, cellW, glyphScale, fg)
+	}
+	if c.attr&vt.Strike != 0 {
+		fill(img, x, y+(glyphTop+3)*glyphScale, cellW, glyphScale, fg)
+	}
+}
+
+func fill(img *image.RGBA, x, y, w, h int, c color.Color) {
+	r := image.Rect(x, y, x+w, y+h).Intersect(img.Rect)
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for px := r.Min.X; px < r.Max.X; px++ {
+			img.Set(px, py, c)
+		}
+	}
+}
+
+func outline(img *image.RGBA, x, y, w, h int, c color.Color) {
+	fill(img, x, y, w, 1, c)
+	fill(img, x, y+h-1, w, 1, c)
+	fill(img, x, y, 1, h, c)
+	fill(img, x+w-1, y, 1, h, c)
+}
+
+// boxLines gives the arms of box drawing characters: up, right, down,
+// left. Heavy, double and dashed lines are drawn as light lines; arcs as
+// corners.
+var boxLines = map[rune][4]bool{
+	'─': {false, true, false, true}, '━': {false, true, false, true}, '═': {false, true, false, true},
+	'╌': {false, true, false, true}, '┄': {false, true, false, true}, '┈': {false, true, false, true},
+	'│': {true, false, true, false}, '┃': {true, false, true, false}, '║': {true, false, true, false},
+	'╎': {true, false, true, false}, '┆': {true, false, true, false}, '┊': {true, false, true, false},
+	'┌': {false, true, true, false}, '┏': {false, true, true, false}, '╔': {false, true, true, false}, '╭': {false, true, true, false},
+	'┐': {false, false, true, true}, '┓': {false, false, true, true}, '╗': {false, false, true, true}, '╮': {false, false, true, true},
+	'└': {true, true, false, false}, '┗': {true, true, false, false}, '╚': {true, true, false, false}, '╰': {true, true, false, false},
+	'┘': {true, false, false, true}, '┛': {true, false, false, true}, '╝': {true, false, false, true}, '╯': {true, false, false, true},
+	'├': {true, true, true, false}, '┣': {true, true, true, false}, '╠': {true, true, true, false},
+	'┤': {true, false, true, true}, '┫': {true, false, true, true}, '╣': {true, false, true, true},
+	'┬': {false, true, true, true}, '┳': {false, true, true, true}, '╦': {false, true, true, true},
+	'┴': {true, true, false, true}, '┻': {true, true, false, true}, '╩': {true, true, false, true},
+	'┼': {true, true, true, true}, '╋': {true, true, true, true}, '╬': {true, true, true, true},
+	'╴': {false, false, false, true}, '╵': {true, false, false, false},
+	'╶': {false, true, false, false}, '╷': {false, false, true, false},
+}
+
+func boxDrawing(img *image.RGBA, x, y int, r rune, c color.Color) {
+	arms, ok := boxLines[r]
+	if !ok {
+		outline(img, x+glyphScale, y+glyphTop*glyphScale, bitmapfont.Width*glyphScale, bitmapfont.Height*glyphScale, c)
+		return
+	}
+	cx, cy := x+cellW/2-glyphScale/2, y+cellH/2-glyphScale/2
+	if arms[0] {
+		fill(img, cx, y, glyphScale, cy-y+glyphScale, c)
+	}
+	if arms[1] {
+		fill(img, cx, cy, x+cellW-cx, glyphScale, c)
+	}
+	if arms[2] {
+		fill(img, cx, cy, glyphScale, y+cellH-cy, c)
+	}
+	if arms[3] {
+		fill(img, x, cy, cx-x+glyphScale, glyphScale, c)
+	}
+}
+
+// block draws the block elements U+2580 to U+259F: eighths, halves and
+// shades. Shades are dithered rather than blended so that frames keep to
+// the palette's colours.
+func block(img *image.RGBA, x, y int, r rune, c color.Color) {
+	switch {
+	case r == '▀':
+		fill(img, x, y, cellW, cellH/2, c)
+	case r >= '▁' && r <= '█':
+		h := cellH * int(r-'▀') / 8
+		fill(img, x, y+cellH-h, cellW, h, c)
+	case r >= '▉' && r <= '▏':
+		w := cellW * int('█'-r+8) / 8
+		fill(img, x, y, w, cellH, c)
+	case r == '▐':
+		fill(img, x+cellW/2, y, cellW/2, cellH, c)
+	case r == '▔':
+		fill(img, x, y, cellW, cellH/8, c)
+	case r == '▕':
+		fill(img, x+cellW*7/8, y, cellW/8, cellH, c)
+	case r >= '░' && r <= '▓':
+		// One, two or three pixels in four.
+		n := int(r-'░') + 1
+		for py := 0; py < cellH; py++ {
+			for px := 0; px < cellW; px++ {
+				if (px%2+2*(py%2)+py/2%2)%4 < n {
+					img.Set(x+px, y+py, c)
+				}
+			}
+		}
+	default:
+		// Quadrants.
+		q := quadrants[r]
+		for i, on := range q {
+			if on {
+				fill(img, x+i%2*cellW/2, y+i/2*cellH/2, cellW/2, cellH/2, c)
+			}
+		}
+	}
+}
+
+// quadrants gives the filled quarters of U+2596 to U+259F: top left, top
+// right, bottom left, bottom right.
+var quadrants = map[rune][4]bool{
+	'▖': {false, false, true, false},
+	'▗': {false, false, false, true},
+	'▘': {true, false, false, false},
+	'▙': {true, false, true, true},
+	'▚': {true, false, false, true},
+	'▛': {true, true, true, false},
+	'▜': {true, true, false, true},
+	'▝': {false, true, false, false},
+	'▞': {false, true, true, false},
+	'▟': {false, true, true, true},
+}
@@ -0,0 +1,267 @@
+package cast
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/vt"
+)
+
+// Formats lists the animation formats Render accepts.
+var Formats = []string{"svg", "gif"}
+
+// Render encodes frames as an animation in the given format.
+func Render(format string, frames []Frame, c Colors, title string) ([]byte, error) {
+	switch format {
+	case "svg":
+		return SVG(frames, c, title), nil
+	case "gif":
+		return GIF(frames, c)
+	}
+	return nil, fmt.Errorf("unknown format %q (want one of %s)", format, strings.Join(Formats, ", "))
+}
+
+// Image draws s with the built-in bitmap font.
+func Image(s *Screen, c Colors) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, s.Width*cellW, s.Height*cellH))
+	for y, line := range c.resolve(s) {
+		for x, cl := range line {
+			drawCell(img, x*cellW, y*cellH, cl)
+		}
+	}
+	return img
+}
+
+// PNG encodes s as a PNG image.
+func PNG(s *Screen, c Colors) ([]byte, error) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, Image(s, c)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// GIF encodes frames as a looping GIF. Frames after the first only carry
+// the rectangle that changed. When the recording resizes the terminal,
+// the image is as large as the largest screen.
+func GIF(frames []Frame, c Colors) ([]byte, error) {
+	var bounds image.Rectangle
+	for _, f := range frames {
+		bounds = bounds.Union(image.Rect(0, 0, f.Screen.Width*cellW, f.Screen.Height*cellH))
+	}
+	images := make([]*image.RGBA, len(frames))
+	for i, f := range frames {
+		images[i] = image.NewRGBA(bounds)
+		draw.Draw(images[i], bounds, image.NewUniform(c.Background.NRGBA()), image.Point{}, draw.Src)
+		draw.Draw(images[i], bounds, Image(f.Screen, c), image.Point{}, draw.Src)
+	}
+	pal := gifPalette(images)
+	anim := &gif.GIF{LoopCount: 0}
+	var prev *image.RGBA
+	// Delays are in hundredths of a second; carry the rounding error so
+	// that long recordings do not drift.
+	var clock, shown float64
+	for i, img := range images {
+		r := img.Bounds()
+		if prev != nil {
+			r = changed(prev, img)
+			if r.Empty() {
+				r = image.Rect(0, 0, 1, 1)
+			}
+		}
+		p := image.NewPaletted(r, pal)
+		draw.Draw(p, r, img, r.Min, draw.Src)
+		clock += frames[i].Duration
+		delay := int(math.Round((clock - shown) * 100))
+		delay = max(delay, 2)
+		shown += float64(delay) / 100
+		anim.Image = append(anim.Image, p)
+		anim.Delay = append(anim.Delay, delay)
+		anim.Disposal = append(anim.Disposal, gif.DisposalNone)
+		prev = img
+	}
+	anim.Config = image.Config{ColorModel: pal, Width: bounds.Dx(), Height: bounds.Dy()}
+	var b bytes.Buffer
+	if err := gif.EncodeAll(&b, anim); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// gifPalette collects the colours of the images. They are drawn without
+// antialiasing, so there are usually few; past 256, the web-safe palette
+// is used instead.
+func gifPalette(images []*image.RGBA) color.Palette {
+	seen := map[color.RGBA]bool{}
+	var pal color.Palette
+	for _, img := range images {
+		for i := 0; i < len(img.Pix); i += 4 {
+			c := color.RGBA{img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3]}
+			if !seen[c] {
+				seen[c] = true
+				pal = append(pal, c)
+				if len(pal) > 256 {
+					return palette.WebSafe
+				}
+			}
+		}
+	}
+	return pal
+}
+
+// changed returns the bounding box of the pixels that differ.
+func changed(a, b *image.RGBA) image.Rectangle {
+	var r image.Rectangle
+	for y := b.Rect.Min.Y; y < b.Rect.Max.Y; y++ {
+		row := y * b.Stride
+		for x := b.Rect.Min.X; x < b.Rect.Max.X; x++ {
+			i := row + x*4
+			if !bytes.Equal(a.Pix[i:i+4], b.Pix[i:i+4]) {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+// SVG cell size; text is laid out per cell so that the viewer's monospace
+// font need not match the recording's.
+const (
+	svgFontSize = 14
+	svgCellW    = 8.4
+	svgCellH    = 18
+)
+
+// SVG renders frames as an SVG animation: one group per frame, shown in
+// turn by SMIL animations that loop. A single frame gives a static image.
+func SVG(frames []Frame, c Colors, title string) []byte {
+	var b bytes.Buffer
+	w, h := 0, 0
+	for _, f := range frames {
+		w, h = max(w, f.Screen.Width), max(h, f.Screen.Height)
+	}
+	width, height := svgX(w), h*svgCellH
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%s\" height=\"%d\" viewBox=\"0 0 %s %d\">\n", width, height, width, height)
+	if title != "" {
+		fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(title))
+	}
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", c.Background.Hex())
+	fmt.Fprintf(&b, "<g font-family=\"Menlo, Consolas, 'DejaVu Sans Mono', monospace\" font-size=\"%d\" xml:space=\"preserve\">\n", svgFontSize)
+	var total float64
+	for _, f := range frames {
+		total += f.Duration
+	}
+	for i, f := range frames {
+		if len(frames) == 1 {
+			b.WriteString("<g>\n")
+		} else {
+			b.WriteString("<g visibility=\"hidden\">\n")
+			fmt.Fprintf(&b, "<animate attributeName=\"visibility\" calcMode=\"discrete\" dur=\"%.3fs\" repeatCount=\"indefinite\" %s/>\n",
+				total, keyTimes(f.Time/total, (f.Time+f.Duration)/total, i == 0, i == len(frames)-1))
+		}
+		svgScreen(&b, f.Screen, c)
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.Bytes()
+}
+
+// keyTimes returns the values and keyTimes attributes showing a frame from
+// start to end, as fractions of the animation.
+func keyTimes(start, end float64, first, last bool) string {
+	switch {
+	case first && last:
+		return `values="visible" keyTimes="0"`
+	case first:
+		return fmt.Sprintf(`values="visible;hidden" keyTimes="0;%.5f"`, end)
+	case last:
+		return fmt.Sprintf(`values="hidden;visible" keyTimes="0;%.5f"`, start)
+	}
+	return fmt.Sprintf(`values="hidden;visible;hidden" keyTimes="0;%.5f;%.5f"`, start, end)
+}
+
+// svgScreen writes the backgrounds of s as rectangles and its text as one
+// <text> element per row, with a <tspan> per run of one style.
+func svgScreen(b *bytes.Buffer, s *Screen, c Colors) {
+	cells := c.resolve(s)
+	for y, line := range cells {
+		for x := 0; x < len(line); {
+			end := x + 1
+			for end < len(line) && line[end].bg == line[x].bg {
+				end++
+			}
+			if line[x].bg != c.Background {
+				fmt.Fprintf(b, "<rect x=\"%s\" y=\"%d\" width=\"%s\" height=\"%d\" fill=\"%s\"/>\n",
+					svgX(x), y*svgCellH, svgX(end-x), svgCellH, line[x].bg.Hex())
+			}
+			x = end
+		}
+	}
+	for y, line := range cells {
+		var row strings.Builder
+		for x := 0; x < len(line); {
+			if line[x].r == ' ' && line[x].attr&(vt.Underline|vt.Strike) == 0 {
+				x++
+				continue
+			}
+			end := x + 1
+			for end < len(line) && sameText(line[end], line[x]) {
+				end++
+			}
+			for line[end-1].r == ' ' && line[end-1].attr&(vt.Underline|vt.Strike) == 0 {
+				end--
+			}
+			var text strings.Builder
+			for _, cl := range line[x:end] {
+				text.WriteRune(cl.r)
+			}
+			fmt.Fprintf(&row, "<tspan x=\"%s\" fill=\"%s\"%s>%s</tspan>",
+				svgX(x), line[x].fg.Hex(), svgAttrs(line[x].attr), html.EscapeString(text.String()))
+			x = end
+		}
+		if row.Len() > 0 {
+			fmt.Fprintf(b, "<text y=\"%d\">%s</text>\n", y*svgCellH+svgCellH-5, row.String())
+		}
+	}
+}
+
+// svgX returns the width of n cells, rounded to hundredths of a pixel.
+func svgX(n int) string {
+	return strconv.FormatFloat(math.Round(float64(n)*svgCellW*100)/100, 'f', -1, 64)
+}
+
+// sameText reports whether two cells can share a <tspan>.
+func sameText(a, b cell) bool {
+	return a.fg == b.fg && a.attr&^vt.Inverse == b.attr&^vt.Inverse
+}
+
+func svgAttrs(a vt.Attr) string {
+	var s string
+	if a&vt.Bold != 0 {
+		s += ` font-weight="bold"`
+	}
+	if a&vt.Italic != 0 {
+		s += ` font-style="italic"`
+	}
+	var deco []string
+	if a&vt.Underline != 0 {
+		deco = append(deco, "underline")
+	}
+	if a&vt.Strike != 0 {
+		deco = append(deco, "line-through")
+	}
+	if len(deco) > 0 {
+		s += ` text-decoration="` + strings.Join(deco, " ") + `"`
+	}
+	return s
+}
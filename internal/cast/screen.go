@@ -0,0 +1,125 @@
+package cast
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/vt"
+)
+
+// Screen is a copy of the terminal contents.
+type Screen struct {
+	Width, Height int
+	Cells         [][]vt.Cell
+	// CursorX and CursorY are the cursor position; CursorVisible is false
+	// when the program hid it.
+	CursorX, CursorY int
+	CursorVisible    bool
+}
+
+// Snapshot copies the current contents of t.
+func Snapshot(t *vt.Terminal) *Screen {
+	w, h := t.Size()
+	s := &Screen{Width: w, Height: h, Cells: make([][]vt.Cell, h)}
+	for y := range s.Cells {
+		s.Cells[y] = make([]vt.Cell, w)
+		for x := range s.Cells[y] {
+			s.Cells[y][x] = t.Cell(x, y)
+		}
+	}
+	s.CursorX, s.CursorY, s.CursorVisible = t.Cursor()
+	return s
+}
+
+// Equal reports whether s and o show the same thing.
+func (s *Screen) Equal(o *Screen) bool {
+	if s.Width != o.Width || s.Height != o.Height ||
+		s.CursorX != o.CursorX || s.CursorY != o.CursorY || s.CursorVisible != o.CursorVisible {
+		return false
+	}
+	for y := range s.Cells {
+		if !slices.Equal(s.Cells[y], o.Cells[y]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Dump renders s as text, one line per row with trailing blanks trimmed
+// and trailing empty rows dropped. Runs of styled text are wrapped in
+// tags naming their style, e.g. "[fg:1 bold]error[/]", with colours
+// written as a palette index, "#RRGGBB", or omitted for the default, and
+// a literal "[" doubled. Golden dumps make the emulator checkable without
+// comparing images.
+func (s *Screen) Dump() string {
+	var rows []string
+	for _, line := range s.Cells {
+		var b strings.Builder
+		end := len(line)
+		for end > 0 && line[end-1].Rune == ' ' && line[end-1].Style == (vt.Style{}) {
+			end--
+		}
+		open := vt.Style{}
+		for _, c := range line[:end] {
+			if c.Style != open {
+				if open != (vt.Style{}) {
+					b.WriteString("[/]")
+				}
+				if c.Style != (vt.Style{}) {
+					b.WriteString("[" + styleTag(c.Style) + "]")
+				}
+				open = c.Style
+			}
+			if c.Rune == '[' {
+				b.WriteString("[[")
+			} else {
+				b.WriteRune(c.Rune)
+			}
+		}
+		if open != (vt.Style{}) {
+			b.WriteString("[/]")
+		}
+		rows = append(rows, b.String())
+	}
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+	return strings.Join(rows, "\n") + "\n"
+}
+
+var attrNames = []struct {
+	attr vt.Attr
+	name string
+}{
+	{vt.Bold, "bold"},
+	{vt.Faint, "faint"},
+	{vt.Italic, "italic"},
+	{vt.Underline, "underline"},
+	{vt.Inverse, "inverse"},
+	{vt.Hidden, "hidden"},
+	{vt.Strike, "strike"},
+}
+
+func styleTag(st vt.Style) string {
+	var parts []string
+	if st.FG.Kind != vt.Default {
+		parts = append(parts, "fg:"+colorTag(st.FG))
+	}
+	if st.BG.Kind != vt.Default {
+		parts = append(parts, "bg:"+colorTag(st.BG))
+	}
+	for _, a := range attrNames {
+		if st.Attr&a.attr != 0 {
+			parts = append(parts, a.name)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func colorTag(c vt.Color) string {
+	if c.Kind == vt.RGB {
+		return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+	}
+	return fmt.Sprint(c.Index)
+}
@@ -0,0 +1,295 @@
+// Package colorlint defines an analyzer that finds colour literals handed to
+// lipgloss and fatih/color, matches each to the nearest palette role by
+// CIEDE2000, reports it, and suggests replacing it with the role's constant
+// from the generated caffeinated package.
+package colorlint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"github.com/caffeinated-minds/caffeinated-rust/caffeinated"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/gopalette"
+)
+
+const doc = `report colour literals that should come from the Caffeinated palette
+
+The analyzer finds the colours given to lipgloss (Color, and the Dark,
+TrueColor and ANSI256 fields of AdaptiveColor, CompleteColor) and to
+fatih/color (RGB, BgRGB, AddRGB, AddBgRGB), and matches each to the nearest
+palette role by CIEDE2000. A colour within -tolerance of a role is reported
+as that role, with a suggested fix that replaces the literal with the
+role's constant from the package named by -import; anything further is
+reported as off-palette, without a fix, since swapping it for the nearest
+role would change the colour. ANSI colours 0 to 15 are left alone: they
+are the terminal's own palette.`
+
+// Analyzer is the caffeinated colour analyzer.
+var Analyzer = &analysis.Analyzer{
+	Name:     "caffeinated",
+	Doc:      doc,
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+var (
+	importPath = gopalette.ImportPath
+	tolerance  = 2.0
+)
+
+func init() {
+	Analyzer.Flags.StringVar(&importPath, "import", importPath, "import path of the generated palette package the fixes refer to")
+	Analyzer.Flags.Float64Var(&tolerance, "tolerance", tolerance, "CIEDE2000 difference within which a colour counts as a palette role")
+}
+
+// role is a palette role with its colour.
+type role struct {
+	name  string
+	color colors.Color
+	// same names the later roles of the same colour.
+	same []string
+}
+
+var roles = func() []role {
+	var rs []role
+	index := map[string]int{}
+	for _, r := range caffeinated.Roles {
+		if i, ok := index[r.Hex]; ok {
+			rs[i].same = append(rs[i].same, r.Name)
+			continue
+		}
+		index[r.Hex] = len(rs)
+		rs = append(rs, role{name: r.Name, color: colors.MustParseHex(r.Hex)})
+	}
+	return rs
+}()
+
+// nearest returns the role closest to c and its distance.
+func nearest(c colors.Color) (role, float64) {
+	best, dist := roles[0], colors.DeltaE2000(c, roles[0].color)
+	for _, r := range roles[1:] {
+		if d := colors.DeltaE2000(c, r.color); d < dist {
+			best, dist = r, d
+		}
+	}
+	return best, dist
+}
+
+// checker holds the state of one pass.
+type checker struct {
+	pass *analysis.Pass
+	// imported records the files a fix already adds the palette import to,
+	// so that applying every fix in a file imports it once.
+	imported map[*ast.File]bool
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if pass.Pkg.Path() == importPath {
+		return nil, nil
+	}
+	c := &checker{pass: pass, imported: map[*ast.File]bool{}}
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	filter := []ast.Node{(*ast.CallExpr)(nil), (*ast.CompositeLit)(nil)}
+	ins.WithStack(filter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		file := stack[0].(*ast.File)
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			c.checkCall(file, n)
+		case *ast.CompositeLit:
+			c.checkLit(file, n)
+		}
+		return true
+	})
+	return nil, nil
+}
+
+// isLipgloss reports whether path is a lipgloss module, v1 or v2.
+func isLipgloss(path string) bool {
+	return path == "github.com/charmbracelet/lipgloss" || strings.HasPrefix(path, "github.com/charmbracelet/lipgloss/v") ||
+		strings.HasPrefix(path, "charm.land/lipgloss/v")
+}
+
+const fatih = "github.com/fatih/color"
+
+// callee returns the package-level object or method a call refers to. A
+// lipgloss v1 Color(...) is a conversion, so the object may be a type.
+func callee(pass *analysis.Pass, call *ast.CallExpr) types.Object {
+	var id *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return nil
+	}
+	return pass.TypesInfo.Uses[id]
+}
+
+func (c *checker) checkCall(file *ast.File, call *ast.CallExpr) {
+	obj := callee(c.pass, call)
+	if obj == nil || obj.Pkg() == nil {
+		return
+	}
+	switch path := obj.Pkg().Path(); {
+	case isLipgloss(path) && obj.Name() == "Color" && len(call.Args) == 1:
+		if _, ok := obj.(*types.Func); ok || isTypeName(obj) {
+			c.checkString(file, call.Args[0], "lipgloss.Color")
+		}
+	case path == fatih && len(call.Args) == 3:
+		switch obj.Name() {
+		case "RGB", "BgRGB", "AddRGB", "AddBgRGB":
+			c.checkRGB(file, call, obj.Name())
+		}
+	}
+}
+
+func isTypeName(obj types.Object) bool {
+	_, ok := obj.(*types.TypeName)
+	return ok
+}
+
+// colorFields are the fields of the lipgloss colour structs that hold a
+// colour for a dark terminal.
+var colorFields = map[string]bool{"Dark": true, "TrueColor": true, "ANSI256": true}
+
+func (c *checker) checkLit(file *ast.File, lit *ast.CompositeLit) {
+	named, ok := types.Unalias(c.pass.TypesInfo.TypeOf(lit)).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || !isLipgloss(named.Obj().Pkg().Path()) {
+		return
+	}
+	switch name := named.Obj().Name(); name {
+	case "AdaptiveColor", "CompleteColor", "CompleteAdaptiveColor":
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if key, ok := kv.Key.(*ast.Ident); ok && colorFields[key.Name] {
+				c.checkString(file, kv.Value, "lipgloss."+name+"."+key.Name)
+			}
+		}
+	}
+}
+
+// checkString reports a string literal holding a hex colour or an xterm
+// colour number.
+func (c *checker) checkString(file *ast.File, e ast.Expr, what string) {
+	lit, ok := ast.Unparen(e).(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return
+	}
+	var col colors.Color
+	if strings.HasPrefix(s, "#") {
+		if col, err = colors.ParseHex(s); err != nil || (len(s) != 4 && len(s) != 7) {
+			return
+		}
+	} else {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 16 || n > 255 {
+			return
+		}
+		col = colors.Xterm(uint8(n))
+	}
+	c.report(file, lit.Pos(), lit.End(), fmt.Sprintf("%s(%s)", what, lit.Value), col, func(pkg, name string) string {
+		return pkg + "." + name
+	})
+}
+
+// checkRGB reports a fatih/color call with three integer literals.
+func (c *checker) checkRGB(file *ast.File, call *ast.CallExpr, fn string) {
+	var v [3]uint8
+	for i, arg := range call.Args {
+		lit, ok := ast.Unparen(arg).(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			return
+		}
+		n, err := strconv.ParseUint(lit.Value, 0, 8)
+		if err != nil {
+			return
+		}
+		v[i] = uint8(n)
+	}
+	col := colors.Color{R: v[0], G: v[1], B: v[2], A: 0xff}
+	c.report(file, call.Args[0].Pos(), call.Args[2].End(), fmt.Sprintf("color.%s(%d, %d, %d)", fn, v[0], v[1], v[2]), col, func(pkg, name string) string {
+		return fmt.Sprintf("%s.RGB(%s.%s)", pkg, pkg, name)
+	})
+}
+
+// report reports the colour col written by the source between pos and end.
+// A colour within tolerance of a role gets a fix that replaces it with
+// replacement(package name, role).
+func (c *checker) report(file *ast.File, pos, end token.Pos, what string, col colors.Color, replacement func(pkg, name string) string) {
+	r, d := nearest(col)
+	if d >= tolerance {
+		c.pass.Report(analysis.Diagnostic{
+			Pos:     pos,
+			End:     end,
+			Message: fmt.Sprintf("%s is off the palette: %s is the nearest role, ΔE2000 %.1f", what, r.name, d),
+		})
+		return
+	}
+	msg := fmt.Sprintf("%s is the palette's %s", what, r.name)
+	if len(r.same) > 0 {
+		msg += fmt.Sprintf(" (also %s)", strings.Join(r.same, ", "))
+	}
+	msg += "; use the role instead of the literal"
+	pkg, edits := c.importEdits(file)
+	text := replacement(pkg, r.name)
+	c.pass.Report(analysis.Diagnostic{
+		Pos:     pos,
+		End:     end,
+		Message: msg,
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Replace with " + text,
+			TextEdits: append(edits, analysis.TextEdit{Pos: pos, End: end, NewText: []byte(text)}),
+		}},
+	})
+}
+
+// importEdits returns the name file refers to the palette package by and
+// the edits that import it if neither the file nor an earlier fix in it
+// does yet.
+func (c *checker) importEdits(file *ast.File) (string, []analysis.TextEdit) {
+	name := importPath[strings.LastIndex(importPath, "/")+1:]
+	for _, spec := range file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == importPath {
+			if spec.Name != nil {
+				return spec.Name.Name, nil
+			}
+			return name, nil
+		}
+	}
+	if c.imported[file] {
+		return name, nil
+	}
+	c.imported[file] = true
+	quoted := strconv.Quote(importPath)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			return name, []analysis.TextEdit{{Pos: gen.Rparen, End: gen.Rparen, NewText: []byte("\t" + quoted + "\n")}}
+		}
+		return name, []analysis.TextEdit{{Pos: gen.End(), End: gen.End(), NewText: []byte("\nimport " + quoted)}}
+	}
+	return name, []analysis.TextEdit{{Pos: file.Name.End(), End: file.Name.End(), NewText: []byte("\n\nimport " + quoted)}}
+}
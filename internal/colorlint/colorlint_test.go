@@ -0,0 +1,13 @@
+package colorlint_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorlint"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), colorlint.Analyzer, "a")
+}
@@ -0,0 +1,19 @@
+package a
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+)
+
+var (
+	accent  = lipgloss.Color("#76C7A5") // want `lipgloss.Color\("#76C7A5"\) is the palette's Accent \(also Added, Property, Tag\); use the role instead of the literal`
+	near    = lipgloss.Color("#76C8A5") // want `is the palette's Accent`
+	magenta = lipgloss.Color("#FF00FF") // want `lipgloss.Color\("#FF00FF"\) is off the palette: .* is the nearest role, ΔE2000 [0-9.]+`
+	ansi    = lipgloss.Color("1")
+
+	adaptive = lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#D1604D"} // want `lipgloss.AdaptiveColor.Dark\("#D1604D"\) is the palette's Error \(also Deleted\)`
+	complete = lipgloss.CompleteColor{TrueColor: "#B7410E", ANSI: "1"}   // want `is the palette's Keyword`
+
+	fg = color.RGB(112, 175, 255)  // want `color.RGB\(112, 175, 255\) is the palette's Info \(also Type, Constant\)`
+	bg = color.BgRGB(255, 0, 255) // want `color.BgRGB\(255, 0, 255\) is off the palette`
+)
@@ -0,0 +1,15 @@
+// Package lipgloss stubs the lipgloss colour types.
+package lipgloss
+
+type Color string
+
+type AdaptiveColor struct {
+	Light string
+	Dark  string
+}
+
+type CompleteColor struct {
+	TrueColor string
+	ANSI256   string
+	ANSI      string
+}
@@ -0,0 +1,9 @@
+// Package color stubs the fatih/color RGB functions.
+package color
+
+type Color struct{}
+
+func RGB(r, g, b int) *Color   { return &Color{} }
+func BgRGB(r, g, b int) *Color { return &Color{} }
+
+func (c *Color) AddRGB(r, g, b int) *Color { return c }
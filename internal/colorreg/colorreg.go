@@ -0,0 +1,303 @@
+// Package colorreg resolves the colours VS Code actually paints for a theme.
+// A theme only sets some workbench colour ids; every other id falls back to
+// a default registered by VS Code, which is usually an expression over other
+// ids such as transparent(editor.background, 0.5). The package bundles a
+// snapshot of those defaults and evaluates them against a theme.
+//
+// The snapshot is a subset of the registry: the ids the workbench paints in
+// the editor, side bar, panel, terminal, lists, inputs and the git and
+// merge-conflict decorations, plus every id their defaults refer to. Ids
+// outside it resolve as Unknown rather than Unset, since VS Code may well
+// give them a default the snapshot does not record.
+package colorreg
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// Kind selects which of the four default columns applies to a theme.
+type Kind int
+
+const (
+	Dark Kind = iota
+	Light
+	HCDark
+	HCLight
+)
+
+var kindNames = [...]string{"dark", "light", "hcDark", "hcLight"}
+
+func (k Kind) String() string { return kindNames[k] }
+
+// KindOf returns the kind of t from its "type" field, which accepts both
+// the theme file spellings and the package.json uiTheme values.
+func KindOf(t *theme.Theme) (Kind, error) {
+	switch t.Type {
+	case "dark", "vs-dark", "":
+		return Dark, nil
+	case "light", "vs":
+		return Light, nil
+	case "hc", "hcDark", "hc-black":
+		return HCDark, nil
+	case "hcLight", "hc-light":
+		return HCLight, nil
+	}
+	return Dark, fmt.Errorf("colorreg: unknown theme type %q", t.Type)
+}
+
+//go:embed defaults.json
+var defaultsJSON []byte
+
+// Registry holds the default value of every known colour id for each kind.
+type Registry struct {
+	// Source names the VS Code release the snapshot was taken from.
+	Source   string
+	ids      []string
+	raw      map[string][4]*string
+	defaults map[string][4]expr
+}
+
+var snapshot = func() *Registry {
+	r, err := Parse(defaultsJSON)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}()
+
+// Default returns the bundled snapshot.
+func Default() *Registry { return snapshot }
+
+// Parse reads a registry snapshot: an object mapping each id to its four
+// default values in the order dark, light, hcDark, hcLight, with null for
+// "no default". Every id an expression refers to must itself be listed.
+func Parse(data []byte) (*Registry, error) {
+	var doc struct {
+		Source string          `json:"source"`
+		Colors json.RawMessage `json:"colors"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("colorreg: %w", err)
+	}
+	r := &Registry{Source: doc.Source, raw: map[string][4]*string{}, defaults: map[string][4]expr{}}
+	dec := json.NewDecoder(strings.NewReader(string(doc.Colors)))
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("colorreg: colors: %w", err)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("colorreg: colors: %w", err)
+		}
+		id := tok.(string)
+		var values [4]*string
+		if err := dec.Decode(&values); err != nil {
+			return nil, fmt.Errorf("colorreg: %s: %w", id, err)
+		}
+		if _, dup := r.raw[id]; dup {
+			return nil, fmt.Errorf("colorreg: %s is listed twice", id)
+		}
+		var exprs [4]expr
+		for k, v := range values {
+			if v == nil {
+				continue
+			}
+			e, err := parseExpr(*v)
+			if err != nil {
+				return nil, fmt.Errorf("colorreg: %s (%s): %w", id, Kind(k), err)
+			}
+			exprs[k] = e
+		}
+		r.ids = append(r.ids, id)
+		r.raw[id] = values
+		r.defaults[id] = exprs
+	}
+	for _, id := range r.ids {
+		for k, e := range r.defaults[id] {
+			if e == nil {
+				continue
+			}
+			for _, dep := range e.refs() {
+				if _, ok := r.raw[dep]; !ok {
+					return nil, fmt.Errorf("colorreg: %s (%s) refers to unknown id %s", id, Kind(k), dep)
+				}
+			}
+		}
+	}
+	return r, nil
+}
+
+// IDs lists the registered ids in snapshot order.
+func (r *Registry) IDs() []string { return r.ids }
+
+// Known reports whether id is registered.
+func (r *Registry) Known(id string) bool {
+	_, ok := r.raw[id]
+	return ok
+}
+
+// Expr returns the default expression of id for kind k, and false when id
+// has no default for k.
+func (r *Registry) Expr(id string, k Kind) (string, bool) {
+	v := r.raw[id][k]
+	if v == nil {
+		return "", false
+	}
+	return *v, true
+}
+
+// Source says where an effective colour comes from.
+type Source int
+
+const (
+	// Unset ids paint nothing.
+	Unset Source = iota
+	// Themed ids are set by the theme.
+	Themed
+	// Defaulted ids take their value from the registry default.
+	Defaulted
+	// Unknown ids are neither set by the theme nor in the snapshot, so
+	// whether VS Code paints them is not known.
+	Unknown
+)
+
+func (s Source) String() string {
+	return [...]string{"unset", "theme", "default", "unknown"}[s]
+}
+
+// Value is the effective colour of one id.
+type Value struct {
+	ID     string
+	Color  colors.Color
+	Source Source
+	// Expr is the default expression for Defaulted and Unset values.
+	Expr string
+}
+
+// Effective is the full colour map of a theme.
+type Effective struct {
+	Kind   Kind
+	ids    []string
+	values map[string]Value
+}
+
+// IDs lists every id with a value: the registry ids followed by ids only the
+// theme sets, in theme order.
+func (e *Effective) IDs() []string { return e.ids }
+
+// Lookup returns the value of id. Ids the snapshot does not list and the
+// theme does not set come back with Source Unknown and false.
+func (e *Effective) Lookup(id string) (Value, bool) {
+	v, ok := e.values[id]
+	if !ok {
+		return Value{ID: id, Source: Unknown}, false
+	}
+	return v, true
+}
+
+// Color returns the colour painted for id, and false when id is unset or
+// unknown.
+func (e *Effective) Color(id string) (colors.Color, bool) {
+	v, ok := e.values[id]
+	return v.Color, ok && v.Source != Unset
+}
+
+// Unknown lists the ids t sets that the registry does not know, sorted.
+// They are usually typos or ids VS Code has removed.
+func (r *Registry) Unknown(t *theme.Theme) []string {
+	var out []string
+	for _, id := range t.Colors.Keys() {
+		if !r.Known(id) {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Resolve evaluates every registered default against t.
+func (r *Registry) Resolve(t *theme.Theme) (*Effective, error) {
+	k, err := KindOf(t)
+	if err != nil {
+		return nil, err
+	}
+	res := &resolver{reg: r, theme: t, kind: k, state: map[string]int{}, values: map[string]Value{}}
+	e := &Effective{Kind: k, values: res.values}
+	for _, id := range r.ids {
+		if _, _, err := res.value(id); err != nil {
+			return nil, err
+		}
+		e.ids = append(e.ids, id)
+	}
+	for _, id := range t.Colors.Keys() {
+		if r.Known(id) {
+			continue
+		}
+		if _, _, err := res.value(id); err != nil {
+			return nil, err
+		}
+		e.ids = append(e.ids, id)
+	}
+	return e, nil
+}
+
+// Resolve evaluates the bundled defaults against t.
+func Resolve(t *theme.Theme) (*Effective, error) {
+	return snapshot.Resolve(t)
+}
+
+const (
+	pending = iota + 1
+	done
+)
+
+type resolver struct {
+	reg    *Registry
+	theme  *theme.Theme
+	kind   Kind
+	state  map[string]int
+	values map[string]Value
+}
+
+func (r *resolver) defines(id string) bool {
+	_, ok := r.theme.Colors.Get(id)
+	return ok
+}
+
+func (r *resolver) value(id string) (colors.Color, bool, error) {
+	switch r.state[id] {
+	case done:
+		v := r.values[id]
+		return v.Color, v.Source != Unset, nil
+	case pending:
+		return colors.Color{}, false, fmt.Errorf("colorreg: %s refers to itself", id)
+	}
+	r.state[id] = pending
+	v := Value{ID: id}
+	if s, ok := r.theme.Colors.Get(id); ok {
+		c, err := colors.ParseHex(s)
+		if err != nil {
+			return c, false, fmt.Errorf("colorreg: %s: %w", id, err)
+		}
+		v.Color, v.Source = c, Themed
+	} else if e := r.reg.defaults[id][r.kind]; e != nil {
+		v.Expr = *r.reg.raw[id][r.kind]
+		c, ok, err := e.eval(r)
+		if err != nil {
+			return c, false, fmt.Errorf("colorreg: %s = %s: %w", id, v.Expr, err)
+		}
+		if ok {
+			v.Color, v.Source = c, Defaulted
+		}
+	}
+	r.values[id] = v
+	r.state[id] = done
+	return v.Color, v.Source != Unset, nil
+}
@@ -0,0 +1,46 @@
+package colorreg
+
+import (
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func TestLookupSources(t *testing.T) {
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eff, err := Resolve(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		id    string
+		found bool
+		want  Source
+	}{
+		{"editor.background", true, Themed},
+		{"not.a.colour.id", false, Unknown},
+	} {
+		got, ok := eff.Lookup(tt.id)
+		if ok != tt.found || got.Source != tt.want || got.ID != tt.id {
+			t.Errorf("Lookup(%q) = %+v, %v; want source %s, %v", tt.id, got, ok, tt.want, tt.found)
+		}
+		if _, ok := eff.Color(tt.id); ok != (tt.want == Themed || tt.want == Defaulted) {
+			t.Errorf("Color(%q) ok = %v", tt.id, ok)
+		}
+	}
+	for _, id := range eff.IDs() {
+		if v, _ := eff.Lookup(id); v.Source == Defaulted && v.Expr == "" {
+			t.Errorf("%s is defaulted without an expression", id)
+		}
+	}
+}
+
+func TestParseRejectsDanglingRefs(t *testing.T) {
+	_, err := Parse([]byte(`{"colors": {"a": ["b", null, null, null]}}`))
+	if err == nil {
+		t.Fatal("Parse accepted a default referring to an unlisted id")
+	}
+}
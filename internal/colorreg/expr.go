@@ -0,0 +1,210 @@
+package colorreg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+)
+
+// An expr is a parsed default value. eval reports ok=false when the value is
+// unset, which VS Code treats as "paint nothing".
+type expr interface {
+	eval(r *resolver) (c colors.Color, ok bool, err error)
+	refs() []string
+}
+
+type literal colors.Color
+
+type ref string
+
+type call struct {
+	fn   string
+	args []expr
+	nums []float64
+}
+
+func (l literal) eval(*resolver) (colors.Color, bool, error) { return colors.Color(l), true, nil }
+func (l literal) refs() []string                             { return nil }
+
+func (id ref) eval(r *resolver) (colors.Color, bool, error) { return r.value(string(id)) }
+func (id ref) refs() []string                               { return []string{string(id)} }
+
+func (c *call) refs() []string {
+	var out []string
+	for _, a := range c.args {
+		out = append(out, a.refs()...)
+	}
+	return out
+}
+
+// functions lists the colour transforms of VS Code's colour registry with
+// the number of colour and numeric arguments each takes. oneOf takes any
+// number of colours.
+var functions = map[string]struct{ colors, nums int }{
+	"transparent":       {1, 1},
+	"lighten":           {1, 1},
+	"darken":            {1, 1},
+	"opaque":            {2, 0},
+	"oneOf":             {-1, 0},
+	"lessProminent":     {2, 2},
+	"ifDefinedThenElse": {3, 0},
+}
+
+func (c *call) eval(r *resolver) (colors.Color, bool, error) {
+	switch c.fn {
+	case "oneOf":
+		for _, a := range c.args {
+			if v, ok, err := a.eval(r); ok || err != nil {
+				return v, ok, err
+			}
+		}
+		return colors.Color{}, false, nil
+	case "ifDefinedThenElse":
+		if r.defines(string(c.args[0].(ref))) {
+			return c.args[1].eval(r)
+		}
+		return c.args[2].eval(r)
+	}
+
+	v, ok, err := c.args[0].eval(r)
+	if !ok || err != nil {
+		return v, ok, err
+	}
+	switch c.fn {
+	case "transparent":
+		return v.WithAlpha(uint8(math.Round(float64(v.A) * c.nums[0]))), true, nil
+	case "lighten":
+		return v.Lighten(c.nums[0]), true, nil
+	case "darken":
+		return v.Darken(c.nums[0]), true, nil
+	}
+
+	bg, bgOK, err := c.args[1].eval(r)
+	if err != nil {
+		return v, false, err
+	}
+	switch c.fn {
+	case "opaque":
+		if v.A == 0xff || !bgOK {
+			return v, true, nil
+		}
+		return v.Over(bg), true, nil
+	case "lessProminent":
+		factor, transparency := c.nums[0], c.nums[1]
+		if !bgOK {
+			return transparent(v, factor*transparency), true, nil
+		}
+		return transparent(lessProminent(v, bg, factor), transparency), true, nil
+	}
+	return v, false, fmt.Errorf("unknown function %s", c.fn)
+}
+
+func transparent(c colors.Color, factor float64) colors.Color {
+	return c.WithAlpha(uint8(math.Round(float64(c.A) * factor)))
+}
+
+// lessProminent moves c towards bg's luminance by factor of the difference,
+// as VS Code's Color.getLighterColor and getDarkerColor do.
+func lessProminent(c, bg colors.Color, factor float64) colors.Color {
+	lc, lb := c.Luminance(), bg.Luminance()
+	if lc < lb {
+		return c.Lighten(factor * (lb - lc) / lb)
+	}
+	if lc > lb {
+		return c.Darken(factor * (lc - lb) / lc)
+	}
+	return c
+}
+
+// parseExpr parses a default value: a hex colour, a colour id, or a
+// transform call such as transparent(editor.background, 0.5).
+func parseExpr(s string) (expr, error) {
+	p := &parser{src: s}
+	e, err := p.expr()
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", s, err)
+	}
+	p.space()
+	if p.pos != len(p.src) {
+		return nil, fmt.Errorf("%q: unexpected %q", s, p.src[p.pos:])
+	}
+	return e, nil
+}
+
+type parser struct {
+	src string
+	pos int
+}
+
+func (p *parser) space() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *parser) word() string {
+	p.space()
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(" (),", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) peek(b byte) bool {
+	p.space()
+	return p.pos < len(p.src) && p.src[p.pos] == b
+}
+
+func (p *parser) expr() (expr, error) {
+	w := p.word()
+	switch {
+	case w == "":
+		return nil, fmt.Errorf("missing value at offset %d", p.pos)
+	case strings.HasPrefix(w, "#"):
+		c, err := colors.ParseHex(w)
+		return literal(c), err
+	case !p.peek('('):
+		return ref(w), nil
+	}
+	sig, ok := functions[w]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", w)
+	}
+	p.pos++
+	c := &call{fn: w}
+	for !p.peek(')') {
+		if len(c.args)+len(c.nums) > 0 {
+			if !p.peek(',') {
+				return nil, fmt.Errorf("expected , or ) at offset %d", p.pos)
+			}
+			p.pos++
+		}
+		if sig.colors < 0 || len(c.args) < sig.colors {
+			a, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			c.args = append(c.args, a)
+			continue
+		}
+		n, err := strconv.ParseFloat(p.word(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", w, err)
+		}
+		c.nums = append(c.nums, n)
+	}
+	p.pos++
+	if (sig.colors >= 0 && len(c.args) != sig.colors) || len(c.nums) != sig.nums || len(c.args) == 0 {
+		return nil, fmt.Errorf("%s: wrong number of arguments", w)
+	}
+	if w == "ifDefinedThenElse" {
+		if _, ok := c.args[0].(ref); !ok {
+			return nil, fmt.Errorf("%s: first argument must be a colour id", w)
+		}
+	}
+	return c, nil
+}
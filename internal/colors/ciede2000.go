@@ -0,0 +1,99 @@
+package colors
+
+import "math"
+
+// Lab is a colour in CIE L*a*b* under the D65 white point.
+type Lab struct {
+	L, A, B float64
+}
+
+// d65 is the reference white, in XYZ with Y = 1.
+var d65 = [3]float64{0.95047, 1, 1.08883}
+
+// Lab converts the colour channels of c, ignoring alpha.
+func (c Color) Lab() Lab {
+	r, g, b := toLinear(c.R), toLinear(c.G), toLinear(c.B)
+	xyz := [3]float64{
+		0.4124564*r + 0.3575761*g + 0.1804375*b,
+		0.2126729*r + 0.7151522*g + 0.0721750*b,
+		0.0193339*r + 0.1191920*g + 0.9503041*b,
+	}
+	var f [3]float64
+	for i, v := range xyz {
+		v /= d65[i]
+		if v > 216.0/24389 {
+			f[i] = math.Cbrt(v)
+		} else {
+			f[i] = (24389.0/27*v + 16) / 116
+		}
+	}
+	return Lab{L: 116*f[1] - 16, A: 500 * (f[0] - f[1]), B: 200 * (f[1] - f[2])}
+}
+
+// DeltaE2000 returns the CIEDE2000 colour difference between two colours,
+// ignoring alpha. A difference below 1 is not perceptible; around 2 it is
+// visible only side by side.
+func DeltaE2000(x, y Color) float64 {
+	return ciede2000(x.Lab(), y.Lab())
+}
+
+func ciede2000(l1, l2 Lab) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	deg := func(rad float64) float64 { return rad * 180 / math.Pi }
+	pow7 := func(v float64) float64 { return v * v * v * v * v * v * v }
+
+	c1, c2 := math.Hypot(l1.A, l1.B), math.Hypot(l2.A, l2.B)
+	cm := (c1 + c2) / 2
+	g := 0.5 * (1 - math.Sqrt(pow7(cm)/(pow7(cm)+pow7(25))))
+	a1, a2 := (1+g)*l1.A, (1+g)*l2.A
+	c1, c2 = math.Hypot(a1, l1.B), math.Hypot(a2, l2.B)
+	hue := func(a, b float64) float64 {
+		if a == 0 && b == 0 {
+			return 0
+		}
+		h := deg(math.Atan2(b, a))
+		if h < 0 {
+			h += 360
+		}
+		return h
+	}
+	h1, h2 := hue(a1, l1.B), hue(a2, l2.B)
+
+	dl := l2.L - l1.L
+	dc := c2 - c1
+	var dh float64
+	if c1*c2 != 0 {
+		dh = h2 - h1
+		switch {
+		case dh > 180:
+			dh -= 360
+		case dh < -180:
+			dh += 360
+		}
+	}
+	dH := 2 * math.Sqrt(c1*c2) * math.Sin(rad(dh/2))
+
+	lm := (l1.L + l2.L) / 2
+	cm = (c1 + c2) / 2
+	hm := h1 + h2
+	if c1*c2 != 0 {
+		switch {
+		case math.Abs(h1-h2) <= 180:
+			hm /= 2
+		case h1+h2 < 360:
+			hm = (hm + 360) / 2
+		default:
+			hm = (hm - 360) / 2
+		}
+	}
+	t := 1 - 0.17*math.Cos(rad(hm-30)) + 0.24*math.Cos(rad(2*hm)) +
+		0.32*math.Cos(rad(3*hm+6)) - 0.20*math.Cos(rad(4*hm-63))
+	sl := 1 + 0.015*(lm-50)*(lm-50)/math.Sqrt(20+(lm-50)*(lm-50))
+	sc := 1 + 0.045*cm
+	sh := 1 + 0.015*cm*t
+	rt := -2 * math.Sqrt(pow7(cm)/(pow7(cm)+pow7(25))) *
+		math.Sin(rad(60*math.Exp(-((hm-275)/25)*((hm-275)/25))))
+
+	dl, dc, dH = dl/sl, dc/sc, dH/sh
+	return math.Sqrt(dl*dl + dc*dc + dH*dH + rt*dc*dH)
+}
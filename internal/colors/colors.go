@@ -0,0 +1,129 @@
+// Package colors parses and converts the hex colours used in the theme files.
+package colors
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Color is an sRGB colour with straight (non-premultiplied) alpha.
+type Color struct {
+	R, G, B, A uint8
+}
+
+// ParseHex parses #RGB, #RGBA, #RRGGBB and #RRGGBBAA colours.
+func ParseHex(s string) (Color, error) {
+	h := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	switch len(h) {
+	case 3, 4:
+		var b strings.Builder
+		for _, r := range h {
+			b.WriteRune(r)
+			b.WriteRune(r)
+		}
+		h = b.String()
+	case 6, 8:
+	default:
+		return Color{}, fmt.Errorf("colors: invalid hex colour %q", s)
+	}
+	if len(h) == 6 {
+		h += "ff"
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("colors: invalid hex colour %q", s)
+	}
+	return Color{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
+
+// MustParseHex is like ParseHex but panics on malformed input. It is meant
+// for colour literals in Go source.
+func MustParseHex(s string) Color {
+	c, err := ParseHex(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Hex formats c as #RRGGBB, or #RRGGBBAA when it is not fully opaque.
+func (c Color) Hex() string {
+	if c.A == 0xff {
+		return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("#%02X%02X%02X%02X", c.R, c.G, c.B, c.A)
+}
+
+// Opaque returns c with its alpha channel set to 0xff.
+func (c Color) Opaque() Color {
+	c.A = 0xff
+	return c
+}
+
+// WithAlpha returns c with its alpha channel replaced.
+func (c Color) WithAlpha(a uint8) Color {
+	c.A = a
+	return c
+}
+
+// Over composites c on top of an opaque background, as the editor does when
+// painting a translucent colour onto a surface.
+func (c Color) Over(bg Color) Color {
+	a := float64(c.A) / 255
+	mix := func(f, b uint8) uint8 {
+		return uint8(math.Round(float64(f)*a + float64(b)*(1-a)))
+	}
+	return Color{R: mix(c.R, bg.R), G: mix(c.G, bg.G), B: mix(c.B, bg.B), A: 0xff}
+}
+
+// NRGBA converts c for use with the image packages.
+func (c Color) NRGBA() color.NRGBA {
+	return color.NRGBA{R: c.R, G: c.G, B: c.B, A: c.A}
+}
+
+// FromImage converts any image colour to a Color.
+func FromImage(c color.Color) Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return Color{R: n.R, G: n.G, B: n.B, A: n.A}
+}
+
+func toLinear(v uint8) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func fromLinear(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
+
+func to8(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+// Luminance returns the WCAG relative luminance of c, ignoring alpha.
+func (c Color) Luminance() float64 {
+	return 0.2126*toLinear(c.R) + 0.7152*toLinear(c.G) + 0.0722*toLinear(c.B)
+}
+
+// Contrast returns the WCAG contrast ratio between two opaque colours.
+func Contrast(a, b Color) float64 {
+	la, lb := a.Luminance(), b.Luminance()
+	if la < lb {
+		la, lb = lb, la
+	}
+	return (la + 0.05) / (lb + 0.05)
+}
+
+// CSS formats c as a CSS rgba() colour.
+func (c Color) CSS() string {
+	return fmt.Sprintf("rgba(%d, %d, %d, %s)", c.R, c.G, c.B, strconv.FormatFloat(math.Round(float64(c.A)/255*100)/100, 'f', -1, 64))
+}
@@ -0,0 +1,74 @@
+package colors
+
+import "math"
+
+// HSL is a colour in the hue, saturation, lightness model, with H in degrees
+// and S and L in [0, 1]. It is the model VS Code uses to lighten and darken
+// colours.
+type HSL struct {
+	H, S, L float64
+}
+
+// HSL converts the colour channels of c, ignoring alpha.
+func (c Color) HSL() HSL {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l := (hi + lo) / 2
+	if hi == lo {
+		return HSL{L: l}
+	}
+	d := hi - lo
+	s := d / (1 - math.Abs(2*l-1))
+	var h float64
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return HSL{H: h * 60, S: s, L: l}
+}
+
+// FromHSL converts h to sRGB with the given alpha. S and L are clamped to
+// [0, 1].
+func FromHSL(h HSL, alpha uint8) Color {
+	s, l := math.Max(0, math.Min(1, h.S)), math.Max(0, math.Min(1, h.L))
+	c := (1 - math.Abs(2*l-1)) * s
+	hp := math.Mod(h.H, 360) / 60
+	x := c * (1 - math.Abs(math.Mod(hp, 2)-1))
+	var r, g, b float64
+	switch {
+	case hp < 1:
+		r, g = c, x
+	case hp < 2:
+		r, g = x, c
+	case hp < 3:
+		g, b = c, x
+	case hp < 4:
+		g, b = x, c
+	case hp < 5:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	m := l - c/2
+	return Color{R: to8(r + m), G: to8(g + m), B: to8(b + m), A: alpha}
+}
+
+// Lighten raises the HSL lightness of c by factor of itself, as VS Code's
+// lighten() colour transform does.
+func (c Color) Lighten(factor float64) Color {
+	h := c.HSL()
+	h.L += h.L * factor
+	return FromHSL(h, c.A)
+}
+
+// Darken lowers the HSL lightness of c by factor of itself, as VS Code's
+// darken() colour transform does.
+func (c Color) Darken(factor float64) Color {
+	h := c.HSL()
+	h.L -= h.L * factor
+	return FromHSL(h, c.A)
+}
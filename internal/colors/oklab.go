@@ -0,0 +1,114 @@
+package colors
+
+import "math"
+
+// OKLab is a colour in Björn Ottosson's perceptual OKLab space.
+type OKLab struct {
+	L, A, B float64
+}
+
+// OKLCH is the cylindrical form of OKLab. H is in degrees.
+type OKLCH struct {
+	L, C, H float64
+}
+
+// OKLab converts the colour channels of c, ignoring alpha.
+func (c Color) OKLab() OKLab {
+	r, g, b := toLinear(c.R), toLinear(c.G), toLinear(c.B)
+
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	return OKLab{
+		L: 0.2104542553*l + 0.7936177850*m - 0.0040720468*s,
+		A: 1.9779984951*l - 2.4285922050*m + 0.4505937099*s,
+		B: 0.0259040371*l + 0.7827717662*m - 0.8086757660*s,
+	}
+}
+
+// OKLCH converts the colour channels of c, ignoring alpha.
+func (c Color) OKLCH() OKLCH {
+	return c.OKLab().LCH()
+}
+
+// LCH returns the cylindrical form of o.
+func (o OKLab) LCH() OKLCH {
+	h := math.Atan2(o.B, o.A) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return OKLCH{L: o.L, C: math.Hypot(o.A, o.B), H: h}
+}
+
+// Lab returns the rectangular form of o.
+func (o OKLCH) Lab() OKLab {
+	rad := o.H * math.Pi / 180
+	return OKLab{L: o.L, A: o.C * math.Cos(rad), B: o.C * math.Sin(rad)}
+}
+
+func (o OKLab) linear() (r, g, b float64) {
+	l := o.L + 0.3963377774*o.A + 0.2158037573*o.B
+	m := o.L - 0.1055613458*o.A - 0.0638541728*o.B
+	s := o.L - 0.0894841775*o.A - 1.2914855480*o.B
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return +4.0767416621*l - 3.3077115913*m + 0.2309699292*s,
+		-1.2684380046*l + 2.6097574011*m - 0.3413193965*s,
+		-0.0041960863*l - 0.7034186147*m + 1.7076147010*s
+}
+
+func (o OKLab) inGamut() bool {
+	const eps = 1e-4
+	r, g, b := o.linear()
+	return r >= -eps && r <= 1+eps && g >= -eps && g <= 1+eps && b >= -eps && b <= 1+eps
+}
+
+// FromOKLab converts o to sRGB with the given alpha. Out-of-gamut colours are
+// brought into range by reducing chroma while holding lightness and hue.
+func FromOKLab(o OKLab, alpha uint8) Color {
+	if !o.inGamut() {
+		lch := o.LCH()
+		lo, hi := 0.0, lch.C
+		for i := 0; i < 24; i++ {
+			mid := (lo + hi) / 2
+			if (OKLCH{L: lch.L, C: mid, H: lch.H}).Lab().inGamut() {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		o = OKLCH{L: lch.L, C: lo, H: lch.H}.Lab()
+	}
+	r, g, b := o.linear()
+	return Color{R: to8(fromLinear(r)), G: to8(fromLinear(g)), B: to8(fromLinear(b)), A: alpha}
+}
+
+// FromOKLCH converts o to sRGB with the given alpha, clipping chroma as
+// FromOKLab does.
+func FromOKLCH(o OKLCH, alpha uint8) Color {
+	return FromOKLab(o.Lab(), alpha)
+}
+
+// Gray returns the neutral grey with OKLab lightness l.
+func Gray(l float64, alpha uint8) Color {
+	return FromOKLab(OKLab{L: l}, alpha)
+}
+
+// Desaturate returns the neutral grey with the same OKLab lightness and
+// alpha as c.
+func (c Color) Desaturate() Color {
+	return Gray(c.OKLab().L, c.A)
+}
+
+// Chromatic reports whether c carries a visible hue.
+func (c Color) Chromatic() bool {
+	return c.OKLCH().C > 0.01
+}
+
+// Distance returns the Euclidean distance between two colours in OKLab,
+// ignoring alpha. A distance of about 0.02 is a just noticeable difference.
+func Distance(a, b Color) float64 {
+	la, lb := a.OKLab(), b.OKLab()
+	return math.Sqrt((la.L-lb.L)*(la.L-lb.L) + (la.A-lb.A)*(la.A-lb.A) + (la.B-lb.B)*(la.B-lb.B))
+}
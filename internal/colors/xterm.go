@@ -0,0 +1,33 @@
+package colors
+
+// cubeLevels are the channel values of xterm's 6×6×6 colour cube.
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// Xterm returns colour i of xterm's 256 colour palette for i from 16: the
+// colour cube, then the grey ramp. Colours 0 to 15 are the terminal's own
+// and have no fixed value; Xterm returns black for them.
+func Xterm(i uint8) Color {
+	switch {
+	case i < 16:
+		return Color{A: 0xff}
+	case i < 232:
+		i -= 16
+		return Color{R: cubeLevels[i/36], G: cubeLevels[i/6%6], B: cubeLevels[i%6], A: 0xff}
+	default:
+		v := 8 + 10*(i-232)
+		return Color{R: v, G: v, B: v, A: 0xff}
+	}
+}
+
+// Nearest256 returns the index of the xterm colour from 16 to 255 closest
+// to c by CIEDE2000. It leaves out 0 to 15, which the terminal's theme
+// decides.
+func Nearest256(c Color) uint8 {
+	best, dist := uint8(16), DeltaE2000(c, Xterm(16))
+	for i := 17; i < 256; i++ {
+		if d := DeltaE2000(c, Xterm(uint8(i))); d < dist {
+			best, dist = uint8(i), d
+		}
+	}
+	return best
+}
@@ -0,0 +1,196 @@
+// Package coverage checks that the theme's token rules colour real code as
+// intended. A fixture pairs a source file with the scope stacks VS Code's
+// grammars assign to its tokens (as shown by "Developer: Inspect Editor
+// Tokens and Scopes") and the palette role each token should be painted in.
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// Ext is the extension of fixture files. The source file they describe has
+// the same name without it, e.g. portal.ts.scopes describes portal.ts.
+const Ext = ".scopes"
+
+// Token is one fixture line.
+type Token struct {
+	// Line is the line of the token in the source file.
+	Line int
+	// Column is the column the token starts at, from 1, or 0 when unknown.
+	Column int
+	Text   string
+	// Expect is a palette role, a hex colour, or "-" for no expectation.
+	Expect string
+	// Scopes is the scope stack, outermost first.
+	Scopes []string
+}
+
+// Fixture is a parsed .scopes file.
+type Fixture struct {
+	// Name is the source file path relative to the fixture directory.
+	Name   string
+	Tokens []Token
+}
+
+// LoadDir loads every fixture below dir, sorted by name.
+func LoadDir(dir string) ([]*Fixture, error) {
+	var fs []*Fixture
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, Ext) {
+			return err
+		}
+		f, err := Load(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, strings.TrimSuffix(path, Ext))
+		if err != nil {
+			return err
+		}
+		f.Name = filepath.ToSlash(rel)
+		fs = append(fs, f)
+		return nil
+	})
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Name < fs[j].Name })
+	return fs, err
+}
+
+// Load parses the fixture at path and locates its tokens, in order, in the
+// source file next to it.
+func Load(path string) (*Fixture, error) {
+	srcPath := strings.TrimSuffix(path, Ext)
+	src, err := os.ReadFile(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Close()
+
+	f := &Fixture{Name: filepath.Base(srcPath)}
+	text := string(src)
+	offset := 0
+	sc := bufio.NewScanner(data)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: want text, role and scopes separated by tabs", path, n)
+		}
+		tok := Token{Text: fields[0], Expect: fields[1], Scopes: strings.Fields(fields[2])}
+		i := strings.Index(text[offset:], tok.Text)
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: %q does not occur in %s after line %d",
+				path, n, tok.Text, filepath.Base(srcPath), strings.Count(text[:offset], "\n")+1)
+		}
+		offset += i
+		tok.Line = strings.Count(text[:offset], "\n") + 1
+		offset += len(tok.Text)
+		f.Tokens = append(f.Tokens, tok)
+	}
+	return f, sc.Err()
+}
+
+// Result is the outcome of checking one token.
+type Result struct {
+	Token
+	Style theme.Style
+	// Want is the expected foreground, empty when there is no expectation.
+	Want string
+	// Problem describes why the token fails, empty when it passes.
+	Problem string
+}
+
+func (r Result) String() string {
+	pos := fmt.Sprint(r.Line)
+	if r.Column > 0 {
+		pos += fmt.Sprintf(":%d", r.Column)
+	}
+	s := fmt.Sprintf("%s: %q is %s", pos, r.Text, r.Style.Foreground.Value)
+	if r.Style.Foreground.Rule >= 0 {
+		s += fmt.Sprintf(" from rule %d (%s)", r.Style.Foreground.Rule, r.Style.Foreground.Selector)
+	}
+	if r.Problem != "" {
+		s += ": " + r.Problem
+	}
+	return s
+}
+
+// Report is the result of checking one fixture.
+type Report struct {
+	Fixture *Fixture
+	Results []Result
+	// Failed counts results with a problem.
+	Failed int
+}
+
+// Check resolves every token of f against t.
+func Check(f *Fixture, t *theme.Theme, p *palette.Palette) *Report {
+	r := &Report{Fixture: f}
+	for _, tok := range f.Tokens {
+		res := Result{Token: tok, Style: t.Match(tok.Scopes...)}
+		switch {
+		case tok.Expect == "-":
+		case strings.HasPrefix(tok.Expect, "#"):
+			if c, err := colors.ParseHex(tok.Expect); err != nil {
+				res.Problem = err.Error()
+			} else {
+				res.Want = c.Hex()
+			}
+		default:
+			if _, ok := palette.Lookup(palette.Role(tok.Expect)); !ok {
+				res.Problem = fmt.Sprintf("unknown role %q", tok.Expect)
+			} else {
+				res.Want = p.Hex(palette.Role(tok.Expect))
+			}
+		}
+		if res.Problem == "" && res.Want != "" {
+			got, err := colors.ParseHex(res.Style.Foreground.Value)
+			switch {
+			case err != nil:
+				res.Problem = err.Error()
+			case res.Style.Foreground.Rule < 0 && palette.Role(tok.Expect) != palette.Foreground:
+				res.Problem = fmt.Sprintf("no rule colours %s, want %s %s", tok.Scopes[len(tok.Scopes)-1], tok.Expect, res.Want)
+			case got.Hex() != res.Want:
+				res.Problem = fmt.Sprintf("want %s %s", tok.Expect, res.Want)
+			}
+		}
+		if res.Problem != "" {
+			r.Failed++
+		}
+		r.Results = append(r.Results, res)
+	}
+	return r
+}
+
+// Unused returns the indexes of the token rules that colour no fixture token.
+func Unused(t *theme.Theme, reports []*Report) []int {
+	used := map[int]bool{}
+	for _, r := range reports {
+		for _, res := range r.Results {
+			used[res.Style.Foreground.Rule] = true
+			used[res.Style.FontStyle.Rule] = true
+		}
+	}
+	var out []int
+	for i := range t.TokenColors {
+		if !used[i] {
+			out = append(out, i)
+		}
+	}
+	return out
+}
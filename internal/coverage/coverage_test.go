@@ -0,0 +1,62 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func TestFixtures(t *testing.T) {
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scopes, err := LoadDir("../../testdata/scopes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	syntax, err := LoadSyntaxDir("../../testdata/syntax")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scopes) == 0 || len(syntax) == 0 {
+		t.Fatalf("found %d scope fixtures and %d syntax tests", len(scopes), len(syntax))
+	}
+	var reports []*Report
+	for _, f := range scopes {
+		reports = append(reports, Check(f, v.Theme, p))
+	}
+	for _, f := range syntax {
+		reports = append(reports, CheckSyntax(f, v.Theme, p))
+	}
+	for _, r := range reports {
+		for _, res := range r.Results {
+			if res.Problem != "" {
+				t.Errorf("%s: %s", r.Fixture.Name, res)
+			}
+		}
+	}
+}
+
+func TestParseSyntax(t *testing.T) {
+	src := "// SYNTAX TEST \"source.go\"\n" +
+		"func main() {\n" +
+		"// <---- keyword.function.go role:keyword\n" +
+		"//   ^^^^ entity.name.function.go role:function fontStyle:none\n"
+	f, err := ParseSyntax("main.go", src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Scope != "source.go" || len(f.Assertions) != 2 {
+		t.Fatalf("ParseSyntax = %+v", f)
+	}
+	a := f.Assertions[1]
+	if a.Text != "main" || a.Col != 5 || a.End != 9 || a.Role != palette.Function || !a.hasStyle {
+		t.Errorf("caret assertion = %+v", a)
+	}
+}
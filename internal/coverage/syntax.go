@@ -0,0 +1,298 @@
+package coverage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// Syntax test files are source files annotated in the style of Sublime
+// Text and vscode-tmgrammar-test. The first line names the root scope:
+//
+//	// SYNTAX TEST "source.go"
+//
+// and whatever precedes SYNTAX TEST is the comment token. Below any source
+// line, comment lines with carets point at a range of its columns, and
+// "<-" or "<~~--" (tildes skip columns, dashes are the length) point at
+// the columns hidden under the comment token, counting from where it starts:
+//
+//	func main() {
+//	// <---- keyword.function.go role:keyword
+//	//   ^^^^ entity.name.function.go role:function fontStyle:none
+//
+// The words after the carets are the scopes VS Code's grammar gives the
+// token, innermost last and without the root scope, and the expectations:
+// role:NAME (a palette role), color:#RRGGBB and fontStyle:STYLE, where
+// STYLE is "none" or styles joined by commas. Columns count characters, so
+// a tab is one column.
+
+// syntaxHeader marks the first line of a syntax test file.
+const syntaxHeader = "SYNTAX TEST"
+
+// Assertion is one caret line.
+type Assertion struct {
+	// Line is the annotated source line; Col and End are the rune columns
+	// of the range, from 0, end exclusive.
+	Line, Col, End int
+	Text           string
+	Scopes         []string
+	Role           palette.Role
+	Color          string
+	FontStyle      string
+	// hasStyle distinguishes fontStyle:none from no expectation.
+	hasStyle bool
+}
+
+// SyntaxFixture is a parsed syntax test file.
+type SyntaxFixture struct {
+	Name       string
+	Scope      string
+	Assertions []Assertion
+}
+
+// LoadSyntaxDir loads every file below dir whose first line is a syntax
+// test header, sorted by name.
+func LoadSyntaxDir(dir string) ([]*SyntaxFixture, error) {
+	var fs []*SyntaxFixture
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		first, _, _ := strings.Cut(string(data), "\n")
+		if !strings.Contains(first, syntaxHeader) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := ParseSyntax(filepath.ToSlash(rel), string(data))
+		if err != nil {
+			return err
+		}
+		fs = append(fs, f)
+		return nil
+	})
+	sort.Slice(fs, func(i, j int) bool { return fs[i].Name < fs[j].Name })
+	return fs, err
+}
+
+// ParseSyntax parses the syntax test file src; name is used in errors.
+func ParseSyntax(name, src string) (*SyntaxFixture, error) {
+	lines := strings.Split(strings.TrimSuffix(src, "\n"), "\n")
+	token, rest, ok := strings.Cut(lines[0], syntaxHeader)
+	token = strings.TrimSpace(token)
+	fields := strings.Split(rest, `"`)
+	if !ok || token == "" || len(fields) < 3 || fields[1] == "" {
+		return nil, fmt.Errorf(`%s:1: want <comment> SYNTAX TEST "<root scope>"`, name)
+	}
+	f := &SyntaxFixture{Name: name, Scope: fields[1]}
+	source := -1
+	for i, line := range lines[1:] {
+		n := i + 2
+		carets, ok := assertionBody(line, token)
+		if !ok {
+			source = n
+			continue
+		}
+		if source < 0 {
+			return nil, fmt.Errorf("%s:%d: assertion before any source line", name, n)
+		}
+		as, err := parseAssertion(line, carets)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, n, err)
+		}
+		text := []rune(lines[source-1])
+		for _, a := range as {
+			if a.End > len(text) {
+				return nil, fmt.Errorf("%s:%d: columns %d-%d are past the end of line %d", name, n, a.Col+1, a.End, source)
+			}
+			a.Line, a.Text = source, string(text[a.Col:a.End])
+			f.Assertions = append(f.Assertions, a)
+		}
+	}
+	if len(f.Assertions) == 0 {
+		return nil, fmt.Errorf("%s: no assertions", name)
+	}
+	return f, nil
+}
+
+// assertionBody returns the byte offset of the first marker of an
+// assertion line, and false for source lines.
+func assertionBody(line, token string) (int, bool) {
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	if !strings.HasPrefix(line[indent:], token) {
+		return 0, false
+	}
+	i := indent + len(token)
+	for i < len(line) && line[i] == ' ' {
+		i++
+	}
+	if i < len(line) && (line[i] == '^' || strings.HasPrefix(line[i:], "<-") || strings.HasPrefix(line[i:], "<~")) {
+		return i, true
+	}
+	return 0, false
+}
+
+// parseAssertion reads the ranges starting at byte offset at, then the
+// scopes and expectations that apply to all of them.
+func parseAssertion(line string, at int) ([]Assertion, error) {
+	var ranges [][2]int
+	i := at
+	if line[i] == '<' {
+		j := i + 1
+		skip, length := 0, 0
+		for ; j < len(line) && line[j] == '~'; j++ {
+			skip++
+		}
+		for ; j < len(line) && line[j] == '-'; j++ {
+			length++
+		}
+		if length == 0 {
+			return nil, fmt.Errorf("%q has no dashes", line[i:j])
+		}
+		// The arrow counts from the column the comment token starts at.
+		base := utf8.RuneCountInString(line[:len(line)-len(strings.TrimLeft(line, " \t"))])
+		ranges = append(ranges, [2]int{base + skip, base + skip + length})
+		i = j
+	} else {
+		for i < len(line) && (line[i] == '^' || line[i] == ' ') {
+			if line[i] == '^' {
+				start := i
+				for i < len(line) && line[i] == '^' {
+					i++
+				}
+				col := utf8.RuneCountInString(line[:start])
+				ranges = append(ranges, [2]int{col, col + i - start})
+				continue
+			}
+			i++
+		}
+	}
+
+	var a Assertion
+	expects := 0
+	for _, w := range strings.Fields(line[i:]) {
+		key, value, ok := strings.Cut(w, ":")
+		switch {
+		case ok && key == "role":
+			if _, known := palette.Lookup(palette.Role(value)); !known {
+				return nil, fmt.Errorf("unknown role %q", value)
+			}
+			a.Role = palette.Role(value)
+		case ok && key == "color":
+			c, err := colors.ParseHex(value)
+			if err != nil {
+				return nil, err
+			}
+			a.Color = c.Hex()
+		case ok && key == "fontStyle":
+			a.FontStyle, a.hasStyle = normalizeStyle(value), true
+		case ok && !strings.Contains(key, "."):
+			return nil, fmt.Errorf("unknown expectation %q (want role:, color: or fontStyle:)", w)
+		default:
+			a.Scopes = append(a.Scopes, w)
+			continue
+		}
+		expects++
+	}
+	if expects == 0 {
+		return nil, fmt.Errorf("no role:, color: or fontStyle: expectation")
+	}
+	var out []Assertion
+	for _, r := range ranges {
+		a.Col, a.End = r[0], r[1]
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// normalizeStyle sorts the styles of a fontStyle value, separated by
+// commas or spaces; "none" and "" are no style.
+func normalizeStyle(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(words) == 1 && words[0] == "none" {
+		return ""
+	}
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// CheckSyntax resolves every assertion of f against t. Results carry the
+// column of the range and the full scope stack.
+func CheckSyntax(f *SyntaxFixture, t *theme.Theme, p *palette.Palette) *Report {
+	r := &Report{Fixture: &Fixture{Name: f.Name}}
+	for _, a := range f.Assertions {
+		stack := append([]string{f.Scope}, a.Scopes...)
+		res := Result{
+			Token: Token{Line: a.Line, Column: a.Col + 1, Text: a.Text, Scopes: stack},
+			Style: t.Match(stack...),
+		}
+		got, err := colors.ParseHex(res.Style.Foreground.Value)
+		var problems []string
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		want := func(what, wantHex string) {
+			if err == nil && got.Opaque().Hex() != wantHex {
+				problems = append(problems, fmt.Sprintf("%s wants %s, got %s%s", what, wantHex, got.Opaque().Hex(), rolesOf(p, got)))
+			}
+		}
+		if a.Role != "" {
+			want("role:"+string(a.Role), p.Hex(a.Role))
+			res.Want = p.Hex(a.Role)
+		}
+		if a.Color != "" {
+			want("color:"+a.Color, a.Color)
+			res.Want = a.Color
+		}
+		if a.hasStyle {
+			if gotStyle := normalizeStyle(res.Style.FontStyle.Value); gotStyle != a.FontStyle {
+				msg := fmt.Sprintf("fontStyle:%s wants %q, got %q", styleName(a.FontStyle), a.FontStyle, gotStyle)
+				if res.Style.FontStyle.Rule >= 0 {
+					msg += fmt.Sprintf(" from rule %d (%s)", res.Style.FontStyle.Rule, res.Style.FontStyle.Selector)
+				}
+				problems = append(problems, msg)
+			}
+		}
+		if len(problems) > 0 {
+			res.Problem = strings.Join(problems, "; ") + "; scopes " + strings.Join(stack, " ")
+			r.Failed++
+		}
+		r.Results = append(r.Results, res)
+	}
+	return r
+}
+
+func styleName(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return strings.ReplaceAll(s, " ", ",")
+}
+
+// rolesOf names the syntax roles painted in c, to make a wrong colour
+// recognisable.
+func rolesOf(p *palette.Palette, c colors.Color) string {
+	var names []string
+	for _, r := range palette.SyntaxRoles() {
+		if p.Hex(r) == c.Opaque().Hex() {
+			names = append(names, string(r))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(names, ", ") + ")"
+}
@@ -0,0 +1,159 @@
+// Package devcontainer generates a dev container feature that installs the
+// theme and the terminal configs exported from it for the container user.
+package devcontainer
+
+import (
+	"archive/zip"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/profile"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// FeatureID is the id of the generated feature.
+const FeatureID = "caffeinated-rust"
+
+// Formats are the exports the feature installs into the user's home.
+var Formats = []string{"shell", "dircolors", "tmux", "git"}
+
+//go:embed install.sh.tmpl
+var installTemplate string
+
+var install = template.Must(template.New("install.sh").Parse(installTemplate))
+
+// Options configures the feature.
+type Options struct {
+	Variant  theme.Variant
+	Manifest *manifest.Manifest
+	Settings *profile.Settings
+	// VSIX is the packaged extension. When nil the feature asks VS Code to
+	// install the extension from the marketplace instead.
+	VSIX *zip.Reader
+}
+
+// Feature is the devcontainer-feature.json document.
+type Feature struct {
+	ID               string                   `json:"id"`
+	Version          string                   `json:"version"`
+	Name             string                   `json:"name"`
+	Description      string                   `json:"description"`
+	DocumentationURL string                   `json:"documentationURL,omitempty"`
+	Options          map[string]FeatureOption `json:"options"`
+	Customizations   Customizations           `json:"customizations"`
+	InstallsAfter    []string                 `json:"installsAfter"`
+}
+
+// FeatureOption is one user-facing option of the feature.
+type FeatureOption struct {
+	Type        string `json:"type"`
+	Default     any    `json:"default"`
+	Description string `json:"description"`
+}
+
+// Customizations carries the tool specific settings of the feature.
+type Customizations struct {
+	VSCode struct {
+		Extensions []string          `json:"extensions,omitempty"`
+		Settings   *profile.Settings `json:"settings"`
+	} `json:"vscode"`
+}
+
+// Generate returns the files of the feature directory.
+func Generate(o Options) ([]export.File, error) {
+	m := o.Manifest
+	extID := strings.ToLower(m.ExtensionID())
+	base := export.BaseName(o.Variant)
+
+	f := Feature{
+		ID:               FeatureID,
+		Version:          m.Version,
+		Name:             m.DisplayName + " theme",
+		Description:      fmt.Sprintf("Installs the %s theme and matching shell, tmux, git and dircolors configs.", m.DisplayName),
+		DocumentationURL: m.Repository.URL,
+		Options: map[string]FeatureOption{
+			"installConfigs": {
+				Type:        "boolean",
+				Default:     true,
+				Description: "Install the shell, tmux, git and dircolors configs into the remote user's home.",
+			},
+		},
+		InstallsAfter: []string{"ghcr.io/devcontainers/features/common-utils"},
+	}
+	f.Customizations.VSCode.Settings = o.Settings
+	if o.VSIX == nil {
+		f.Customizations.VSCode.Extensions = []string{extID}
+	}
+	doc, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files := []export.File{{Path: "devcontainer-feature.json", Data: append(doc, '\n')}}
+
+	for _, format := range Formats {
+		fs, err := export.Run(format, o.Variant)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range fs {
+			file.Path = path.Join("configs", file.Path)
+			files = append(files, file)
+		}
+	}
+
+	var extension string
+	if o.VSIX != nil {
+		extension = fmt.Sprintf("%s-%s", extID, m.Version)
+		fs, err := unpack(o.VSIX)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, fs...)
+	}
+
+	var script bytes.Buffer
+	err = install.Execute(&script, map[string]string{
+		"Name":      m.DisplayName,
+		"ConfigDir": export.ConfigDir,
+		"Base":      base,
+		"Extension": extension,
+	})
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, export.File{Path: "install.sh", Data: script.Bytes(), Mode: 0o755})
+	return files, nil
+}
+
+// unpack copies the extension/ folder of a VSIX, which is the directory VS
+// Code itself installs.
+func unpack(vsix *zip.Reader) ([]export.File, error) {
+	var files []export.File
+	for _, zf := range vsix.File {
+		if !strings.HasPrefix(zf.Name, "extension/") || strings.HasSuffix(zf.Name, "/") {
+			continue
+		}
+		r, err := zf.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, export.File{Path: zf.Name, Data: data})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("devcontainer: VSIX has no extension/ folder")
+	}
+	return files, nil
+}
@@ -0,0 +1,136 @@
+package devcontainer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/profile"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// vsixReader returns a minimal package holding an extension/ folder.
+func vsixReader(t *testing.T) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range map[string]string{
+		"extension.vsixmanifest":  "<PackageManifest/>",
+		"extension/package.json":  `{"name": "caffeinated-rust"}`,
+		"extension/themes/a.json": "{}",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr
+}
+
+// TestInstall generates the feature, runs its install script twice against
+// a temporary home directory and checks that every config was installed and
+// every include line added exactly once.
+func TestInstall(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	m, err := manifest.Load("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings, err := profile.NewSettings(v, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := user.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := export.BaseName(v)
+
+	for _, vsix := range []bool{false, true} {
+		t.Run(fmt.Sprintf("vsix=%v", vsix), func(t *testing.T) {
+			opts := Options{Variant: v, Manifest: m, Settings: settings}
+			if vsix {
+				opts.VSIX = vsixReader(t)
+			}
+			files, err := Generate(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir, home := t.TempDir(), t.TempDir()
+			if err := export.Write(dir, files); err != nil {
+				t.Fatal(err)
+			}
+			for run := 1; run <= 2; run++ {
+				cmd := exec.Command("sh", filepath.Join(dir, "install.sh"))
+				cmd.Env = append(os.Environ(), "_REMOTE_USER="+u.Username, "_REMOTE_USER_HOME="+home)
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("install.sh run %d: %v\n%s", run, err, out)
+				}
+			}
+
+			configDir := filepath.Join(home, filepath.FromSlash(export.ConfigDir))
+			configs, err := os.ReadDir(filepath.Join(dir, "configs"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range configs {
+				if _, err := os.Stat(filepath.Join(configDir, c.Name())); err != nil {
+					t.Errorf("config not installed: %s", c.Name())
+				}
+			}
+			for file, want := range map[string]string{
+				".tmux.conf": base + ".tmux.conf",
+				".gitconfig": base + ".gitconfig",
+				".bashrc":    base + ".sh",
+			} {
+				data, err := os.ReadFile(filepath.Join(home, file))
+				if err != nil {
+					t.Error(err)
+					continue
+				}
+				lines := 0
+				for _, line := range strings.Split(string(data), "\n") {
+					if strings.Contains(line, filepath.Join(configDir, want)) {
+						lines++
+					}
+				}
+				if lines != 1 {
+					t.Errorf("~/%s references %s on %d lines after two runs, want 1", file, want, lines)
+				}
+			}
+
+			extensions, _ := filepath.Glob(filepath.Join(home, ".vscode-server", "extensions", "*", "package.json"))
+			want := 0
+			if vsix {
+				want = 1
+			}
+			if len(extensions) != want {
+				t.Errorf("%d extensions in ~/.vscode-server/extensions, want %d", len(extensions), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package dotfiles installs exported theme configs into a home directory.
+// It only touches tools whose config files already exist, adds include
+// lines instead of rewriting user configs, and records every change in a
+// journal so that an install can be rolled back.
+package dotfiles
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// Target describes one tool the installer knows about.
+type Target struct {
+	Tool string
+	// Formats are the exports installed into export.ConfigDir.
+	Formats []string
+	// Detect lists config paths relative to the home directory. The tool is
+	// considered installed when one of them exists; the first existing one
+	// receives the include line.
+	Detect []string
+	// Include renders the line that pulls the installed file (an absolute
+	// path) into the tool's config. It is nil for files that need no include.
+	Include func(path string) string
+	// IncludeFile is the export (by suffix) the include line refers to.
+	IncludeFile string
+}
+
+func shellInclude(path string) string {
+	return fmt.Sprintf("[ -r %q ] && . %q", path, path)
+}
+
+// Targets lists the supported tools.
+var Targets = []Target{
+	{
+		Tool:        "git",
+		Formats:     []string{"git"},
+		Detect:      []string{".gitconfig", ".config/git/config"},
+		Include:     func(path string) string { return "[include]\n\tpath = " + path },
+		IncludeFile: ".gitconfig",
+	},
+	{
+		Tool:        "tmux",
+		Formats:     []string{"tmux"},
+		Detect:      []string{".tmux.conf", ".config/tmux/tmux.conf"},
+		Include:     func(path string) string { return fmt.Sprintf("source-file %q", path) },
+		IncludeFile: ".tmux.conf",
+	},
+	{
+		Tool:        "kitty",
+		Formats:     []string{"kitty"},
+		Detect:      []string{".config/kitty/kitty.conf"},
+		Include:     func(path string) string { return "include " + path },
+		IncludeFile: ".kitty.conf",
+	},
+	{
+		Tool:        "zsh",
+		Formats:     []string{"shell", "dircolors"},
+		Detect:      []string{".zshrc"},
+		Include:     shellInclude,
+		IncludeFile: ".sh",
+	},
+	{
+		Tool:        "bash",
+		Formats:     []string{"shell", "dircolors"},
+		Detect:      []string{".bashrc"},
+		Include:     shellInclude,
+		IncludeFile: ".sh",
+	},
+}
+
+// Step is one planned change.
+type Step struct {
+	Tool string
+	// Path is relative to the home directory.
+	Path string
+	Data []byte
+	Mode os.FileMode
+	// Include is the line appended to Path, for include steps.
+	Include string
+	// Exists reports whether Path exists before the step runs.
+	Exists bool
+}
+
+func (s Step) String() string {
+	switch {
+	case s.Include != "":
+		return fmt.Sprintf("%-6s append to ~/%s: %s", s.Tool, s.Path, strings.ReplaceAll(s.Include, "\n", `\n`))
+	case s.Exists:
+		return fmt.Sprintf("%-6s update ~/%s", s.Tool, s.Path)
+	default:
+		return fmt.Sprintf("%-6s create ~/%s", s.Tool, s.Path)
+	}
+}
+
+// Detect returns the targets whose tools have a config in home, optionally
+// restricted to the named tools.
+func Detect(home string, only []string) []Target {
+	var found []Target
+	for _, t := range Targets {
+		if len(only) > 0 && !contains(only, t.Tool) {
+			continue
+		}
+		if _, ok := firstExisting(home, t.Detect); ok {
+			found = append(found, t)
+		}
+	}
+	return found
+}
+
+// Plan computes the steps needed to install v for the detected targets.
+// Files that are already installed with identical content and include lines
+// that are already present produce no step, so planning after an install
+// yields nothing.
+func Plan(home string, v theme.Variant, targets []Target) ([]Step, error) {
+	var steps []Step
+	planned := map[string]bool{}
+	for _, t := range targets {
+		var includePath string
+		for _, format := range t.Formats {
+			files, err := export.Run(format, v)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range files {
+				rel := filepath.ToSlash(filepath.Join(export.ConfigDir, f.Path))
+				if strings.HasSuffix(f.Path, t.IncludeFile) {
+					includePath = filepath.Join(home, filepath.FromSlash(rel))
+				}
+				if planned[rel] {
+					continue
+				}
+				planned[rel] = true
+				current, err := os.ReadFile(filepath.Join(home, filepath.FromSlash(rel)))
+				exists := err == nil
+				if exists && bytes.Equal(current, f.Data) {
+					continue
+				}
+				steps = append(steps, Step{Tool: t.Tool, Path: rel, Data: f.Data, Mode: f.Mode, Exists: exists})
+			}
+		}
+		if t.Include == nil || includePath == "" {
+			continue
+		}
+		config, _ := firstExisting(home, t.Detect)
+		current, err := os.ReadFile(filepath.Join(home, filepath.FromSlash(config)))
+		if err != nil {
+			return nil, err
+		}
+		line := t.Include(includePath)
+		if hasInclude(current, line) {
+			continue
+		}
+		steps = append(steps, Step{Tool: t.Tool, Path: config, Include: line, Exists: true})
+	}
+	return steps, nil
+}
+
+// hasInclude reports whether every line of include already appears in
+// config, ignoring surrounding whitespace.
+func hasInclude(config []byte, include string) bool {
+	lines := map[string]bool{}
+	for _, l := range strings.Split(string(config), "\n") {
+		lines[strings.TrimSpace(l)] = true
+	}
+	for _, l := range strings.Split(include, "\n") {
+		if !lines[strings.TrimSpace(l)] {
+			return false
+		}
+	}
+	return true
+}
+
+func firstExisting(home string, paths []string) (string, bool) {
+	for _, p := range paths {
+		if _, err := os.Stat(filepath.Join(home, filepath.FromSlash(p))); err == nil {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
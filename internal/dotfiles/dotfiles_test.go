@@ -0,0 +1,203 @@
+package dotfiles
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// fakeHome returns a home directory with a .zshrc and a .gitconfig.
+func fakeHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	writeFile(t, home, ".zshrc", "export EDITOR=vim\n")
+	writeFile(t, home, ".gitconfig", "[user]\n\tname = Someone\n")
+	return home
+}
+
+func writeFile(t *testing.T, home, rel, data string) {
+	t.Helper()
+	path := filepath.Join(home, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, home, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(home, filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func install(t *testing.T, home string) *Journal {
+	t.Helper()
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps, err := Plan(home, v, Detect(home, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) == 0 {
+		t.Fatal("nothing to install")
+	}
+	j, err := Apply(home, v.ID, steps, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := Plan(home, v, Detect(home, nil)); err != nil || len(again) != 0 {
+		t.Fatalf("planning after the install gave %v, %v; want nothing", again, err)
+	}
+	return j
+}
+
+func TestRollbackRestoresHome(t *testing.T) {
+	home := fakeHome(t)
+	j := install(t, home)
+	if !strings.Contains(readFile(t, home, ".zshrc"), includeHeader) {
+		t.Fatal("no include block in .zshrc")
+	}
+	if _, err := Rollback(home, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, home, ".zshrc"); got != "export EDITOR=vim\n" {
+		t.Errorf(".zshrc after rollback = %q", got)
+	}
+	if got := readFile(t, home, ".gitconfig"); got != "[user]\n\tname = Someone\n" {
+		t.Errorf(".gitconfig after rollback = %q", got)
+	}
+	for _, a := range j.Actions {
+		if a.Backup == "" {
+			if _, err := os.Stat(filepath.Join(home, a.Path)); !os.IsNotExist(err) {
+				t.Errorf("~/%s was not removed", a.Path)
+			}
+		}
+	}
+	if ids, _ := Installs(home); len(ids) != 0 {
+		t.Errorf("installs after rollback = %v", ids)
+	}
+}
+
+func TestRollbackKeepsLaterEdits(t *testing.T) {
+	home := fakeHome(t)
+	j := install(t, home)
+	writeFile(t, home, ".zshrc", readFile(t, home, ".zshrc")+"alias k=kubectl\n")
+	if _, err := Rollback(home, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readFile(t, home, ".zshrc"), "export EDITOR=vim\nalias k=kubectl\n"; got != want {
+		t.Errorf(".zshrc after rollback = %q, want %q", got, want)
+	}
+}
+
+func TestRollbackRefusesChangedFiles(t *testing.T) {
+	home := fakeHome(t)
+	j := install(t, home)
+	var written string
+	for _, a := range j.Actions {
+		if a.Hash != "" {
+			written = a.Path
+			break
+		}
+	}
+	if written == "" {
+		t.Fatal("the install wrote no config file")
+	}
+	writeFile(t, home, written, "# mine now\n")
+	zshrc := readFile(t, home, ".zshrc")
+
+	_, err := Rollback(home, j.ID)
+	if err == nil || !strings.Contains(err.Error(), written) {
+		t.Fatalf("Rollback = %v, want an error naming ~/%s", err, written)
+	}
+	if got := readFile(t, home, written); got != "# mine now\n" {
+		t.Errorf("~/%s was replaced: %q", written, got)
+	}
+	if got := readFile(t, home, ".zshrc"); got != zshrc {
+		t.Errorf(".zshrc changed by a refused rollback")
+	}
+	if ids, _ := Installs(home); len(ids) != 1 {
+		t.Errorf("installs after a refused rollback = %v, want the journal kept", ids)
+	}
+}
+
+func TestRollbackRefusesEditedBlock(t *testing.T) {
+	home := fakeHome(t)
+	j := install(t, home)
+	edited := strings.Replace(readFile(t, home, ".zshrc"), includeHeader, "# theme", 1)
+	writeFile(t, home, ".zshrc", edited)
+	if _, err := Rollback(home, j.ID); err == nil {
+		t.Fatal("Rollback of an edited include block succeeded")
+	}
+	if got := readFile(t, home, ".zshrc"); got != edited {
+		t.Errorf(".zshrc changed by a refused rollback")
+	}
+}
+
+func TestRollbackWithoutTrailingNewline(t *testing.T) {
+	home := fakeHome(t)
+	writeFile(t, home, ".zshrc", "export EDITOR=vim")
+	j := install(t, home)
+	if _, err := Rollback(home, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, home, ".zshrc"); got != "export EDITOR=vim" {
+		t.Errorf(".zshrc after rollback = %q", got)
+	}
+}
+
+func TestRollbackFailedInstall(t *testing.T) {
+	home := fakeHome(t)
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps, err := Plan(home, v, Detect(home, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A directory where the last config file goes makes that step fail
+	// after the earlier ones, including an include block, were applied.
+	var failing string
+	for _, s := range steps {
+		if s.Include == "" {
+			failing = s.Path
+		}
+	}
+	writeFile(t, home, failing+"/keep", "mine\n")
+
+	j, err := Apply(home, v.ID, steps, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err == nil {
+		t.Fatal("Apply succeeded with a directory in the way")
+	}
+	if j == nil || j.Actions[len(j.Actions)-1].Path != failing {
+		t.Fatalf("journal %+v does not end with the failed step on ~/%s", j, failing)
+	}
+	if !strings.Contains(readFile(t, home, ".gitconfig"), includeHeader) {
+		t.Fatal("the include block before the failed step was not applied")
+	}
+
+	if _, err := Rollback(home, j.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, home, ".gitconfig"); got != "[user]\n\tname = Someone\n" {
+		t.Errorf(".gitconfig after rollback = %q", got)
+	}
+	if got := readFile(t, home, failing+"/keep"); got != "mine\n" {
+		t.Errorf("~/%s/keep after rollback = %q", failing, got)
+	}
+	if ids, _ := Installs(home); len(ids) != 0 {
+		t.Errorf("installs after rollback = %v", ids)
+	}
+}
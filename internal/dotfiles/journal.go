@@ -0,0 +1,273 @@
+package dotfiles
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+)
+
+// backupDir holds one directory per install, named by its timestamp, with
+// the journal and copies of every file the install changed.
+var backupDir = filepath.Join(filepath.FromSlash(export.ConfigDir), "backups")
+
+// Journal records an install so that it can be rolled back.
+type Journal struct {
+	ID      string   `json:"id"`
+	Variant string   `json:"variant"`
+	Actions []Action `json:"actions"`
+}
+
+// Action is one changed file. Paths are relative to the home directory.
+// Backup is empty when the install created the file. An action with neither
+// Appended nor Hash was recorded but never completed, because the install
+// failed while applying it.
+type Action struct {
+	Path   string `json:"path"`
+	Backup string `json:"backup,omitempty"`
+	// Appended is the block an include step added to a user config; rolling
+	// back removes just that block and keeps later edits.
+	Appended string `json:"appended,omitempty"`
+	// Hash is the SHA-256 of a file the install wrote. Rolling back refuses
+	// to replace the file once it no longer matches.
+	Hash string `json:"hash,omitempty"`
+}
+
+// includeHeader opens every block an install appends to a user config.
+const includeHeader = "# Caffeinated Rust theme"
+
+// Apply performs steps, backing up every file it changes, and writes the
+// journal. It returns nil without writing anything when there are no steps.
+func Apply(home, variant string, steps []Step, now time.Time) (*Journal, error) {
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	id := now.UTC().Format("20060102T150405Z")
+	dir := filepath.Join(home, backupDir, id)
+	for n := 2; ; n++ {
+		if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", now.UTC().Format("20060102T150405Z"), n)
+		dir = filepath.Join(home, backupDir, id)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	j := &Journal{ID: id, Variant: variant}
+	for _, s := range steps {
+		path := filepath.Join(home, filepath.FromSlash(s.Path))
+		a := Action{Path: s.Path}
+		if s.Exists {
+			a.Backup = filepath.ToSlash(filepath.Join(backupDir, id, s.Path))
+			if err := copyFile(path, filepath.Join(home, filepath.FromSlash(a.Backup))); err != nil {
+				return j, err
+			}
+		}
+		// Record the action before touching the file, so that rolling back
+		// a failed install also puts back the file it failed on.
+		j.Actions = append(j.Actions, a)
+		if err := writeJournal(dir, j); err != nil {
+			return j, err
+		}
+		appended, err := applyStep(path, s)
+		if err != nil {
+			return j, err
+		}
+		last := &j.Actions[len(j.Actions)-1]
+		if s.Include != "" {
+			last.Appended = appended
+		} else {
+			last.Hash = hash(s.Data)
+		}
+		if err := writeJournal(dir, j); err != nil {
+			return j, err
+		}
+	}
+	return j, nil
+}
+
+// applyStep writes a file or appends an include block, returning the
+// appended text.
+func applyStep(path string, s Step) (string, error) {
+	if s.Include == "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", err
+		}
+		mode := s.Mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		return "", os.WriteFile(path, s.Data, mode)
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	text := "\n" + includeHeader + "\n" + s.Include + "\n"
+	if len(current) > 0 && current[len(current)-1] != '\n' {
+		text = "\n" + text
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	return text, f.Close()
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Installs lists the journal IDs in home, oldest first.
+func Installs(home string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(home, backupDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		if _, err := os.Stat(filepath.Join(home, backupDir, e.Name(), "journal.json")); err == nil {
+			ids = append(ids, e.Name())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Rollback undoes the install with the given ID, or the latest install when
+// id is empty: include blocks are cut out of the user configs, backed up
+// files are restored and created files removed. A file the install failed
+// on is restored from its backup, or removed if it did not exist before.
+// Edits made since the install are kept: it changes nothing and returns an
+// error if a file the install wrote has been changed or an include block
+// can no longer be found.
+func Rollback(home, id string) (*Journal, error) {
+	if id == "" {
+		ids, err := Installs(home)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no install to roll back in ~/%s", filepath.ToSlash(backupDir))
+		}
+		id = ids[len(ids)-1]
+	}
+	dir := filepath.Join(home, backupDir, id)
+	data, err := os.ReadFile(filepath.Join(dir, "journal.json"))
+	if err != nil {
+		return nil, err
+	}
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+
+	// Work out every change before making any, so that a conflict leaves
+	// the home directory as it is.
+	cut := map[int][]byte{}
+	var conflicts []string
+	for i, a := range j.Actions {
+		if a.partial() {
+			continue
+		}
+		path := filepath.Join(home, filepath.FromSlash(a.Path))
+		current, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && a.Appended == "" {
+			continue
+		}
+		if err != nil {
+			return &j, err
+		}
+		switch {
+		case a.Appended != "":
+			at := strings.LastIndex(string(current), a.Appended)
+			if at < 0 {
+				conflicts = append(conflicts, fmt.Sprintf("~/%s: the %q block was changed; remove it by hand", a.Path, includeHeader))
+				continue
+			}
+			cut[i] = append(current[:at:at], current[at+len(a.Appended):]...)
+		case hash(current) != a.Hash:
+			conflicts = append(conflicts, fmt.Sprintf("~/%s: changed since the install", a.Path))
+		}
+	}
+	if len(conflicts) > 0 {
+		return &j, fmt.Errorf("not rolling back %s:\n\t%s", id, strings.Join(conflicts, "\n\t"))
+	}
+
+	for i := len(j.Actions) - 1; i >= 0; i-- {
+		a := j.Actions[i]
+		path := filepath.Join(home, filepath.FromSlash(a.Path))
+		if data, ok := cut[i]; ok {
+			info, err := os.Stat(path)
+			if err != nil {
+				return &j, err
+			}
+			if err := os.WriteFile(path, data, info.Mode().Perm()); err != nil {
+				return &j, err
+			}
+			continue
+		}
+		if a.Backup == "" {
+			if a.partial() {
+				// Leave alone whatever was in the way of the new file.
+				if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+					continue
+				}
+			}
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return &j, err
+			}
+			continue
+		}
+		if err := copyFile(filepath.Join(home, filepath.FromSlash(a.Backup)), path); err != nil {
+			return &j, err
+		}
+	}
+	return &j, os.RemoveAll(dir)
+}
+
+// partial reports whether the install failed while applying a.
+func (a Action) partial() bool {
+	return a.Appended == "" && a.Hash == ""
+}
+
+func writeJournal(dir string, j *Journal) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "journal.json"), append(data, '\n'), 0o644)
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, info.Mode().Perm())
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unicode/utf16"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "ase", Description: "Adobe Swatch Exchange for Illustrator, Photoshop and Affinity", Generate: ase})
+}
+
+// ASE block types.
+const (
+	aseGroupStart uint16 = 0xC001
+	aseGroupEnd   uint16 = 0xC002
+	aseColor      uint16 = 0x0001
+)
+
+// aseGlobal is the colour type of ordinary (non-spot) swatches.
+const aseGlobal uint16 = 0
+
+func ase(v theme.Variant, p *palette.Palette) ([]File, error) {
+	data := EncodeASE(Swatches(v, p))
+	return []File{{Path: BaseName(v) + ".ase", Data: data}}, nil
+}
+
+// EncodeASE writes groups in Adobe Swatch Exchange format version 1.0:
+// big-endian blocks with UTF-16 names and RGB colours as float32.
+func EncodeASE(groups []SwatchGroup) []byte {
+	var body bytes.Buffer
+	blocks := 0
+	block := func(typ uint16, payload []byte) {
+		binary.Write(&body, binary.BigEndian, typ)
+		binary.Write(&body, binary.BigEndian, uint32(len(payload)))
+		body.Write(payload)
+		blocks++
+	}
+	for _, g := range groups {
+		block(aseGroupStart, aseName(g.Name))
+		for _, s := range g.Swatches {
+			var b bytes.Buffer
+			b.Write(aseName(s.Name))
+			b.WriteString("RGB ")
+			for _, ch := range []uint8{s.Color.R, s.Color.G, s.Color.B} {
+				binary.Write(&b, binary.BigEndian, float32(ch)/255)
+			}
+			binary.Write(&b, binary.BigEndian, aseGlobal)
+			block(aseColor, b.Bytes())
+		}
+		block(aseGroupEnd, nil)
+	}
+	var out bytes.Buffer
+	out.WriteString("ASEF")
+	binary.Write(&out, binary.BigEndian, [2]uint16{1, 0})
+	binary.Write(&out, binary.BigEndian, uint32(blocks))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+// aseName encodes a name as its length in UTF-16 code units, including a
+// terminating zero, followed by the units.
+func aseName(s string) []byte {
+	units := append(utf16.Encode([]rune(s)), 0)
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, uint16(len(units)))
+	binary.Write(&b, binary.BigEndian, units)
+	return b.Bytes()
+}
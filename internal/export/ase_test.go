@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+)
+
+// TestASERoundTrip decodes the export again: the format is binary and
+// tools reject malformed files silently.
+func TestASERoundTrip(t *testing.T) {
+	v := defaultVariant(t)
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := Run("ase", v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := decodeASE(bytes.NewReader(files[0].Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sameSwatches(Swatches(v, p), back); err != nil {
+		t.Error(err)
+	}
+}
+
+// decodeASE reads an Adobe Swatch Exchange file. Only RGB swatches are
+// supported; swatches outside a group are returned in a group with an
+// empty name.
+func decodeASE(r io.Reader) ([]SwatchGroup, error) {
+	var head struct {
+		Magic   [4]byte
+		Version [2]uint16
+		Blocks  uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &head); err != nil {
+		return nil, err
+	}
+	if string(head.Magic[:]) != "ASEF" {
+		return nil, fmt.Errorf("not an ASE file")
+	}
+	if head.Version[0] != 1 {
+		return nil, fmt.Errorf("unsupported ASE version %d.%d", head.Version[0], head.Version[1])
+	}
+	var groups []SwatchGroup
+	var cur *SwatchGroup
+	for i := uint32(0); i < head.Blocks; i++ {
+		var typ uint16
+		var n uint32
+		if err := binary.Read(r, binary.BigEndian, &typ); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+		pr := bytes.NewReader(payload)
+		switch typ {
+		case aseGroupStart:
+			if cur != nil {
+				return nil, fmt.Errorf("block %d: nested group", i)
+			}
+			name, err := readASEName(pr)
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+			groups = append(groups, SwatchGroup{Name: name})
+			cur = &groups[len(groups)-1]
+		case aseGroupEnd:
+			if cur == nil {
+				return nil, fmt.Errorf("block %d: group end outside a group", i)
+			}
+			cur = nil
+		case aseColor:
+			s, err := readASEColor(pr)
+			if err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+			if cur == nil {
+				groups = append(groups, SwatchGroup{Swatches: []Swatch{s}})
+			} else {
+				cur.Swatches = append(cur.Swatches, s)
+			}
+		default:
+			return nil, fmt.Errorf("block %d: unknown type %#04x", i, typ)
+		}
+	}
+	if cur != nil {
+		return nil, fmt.Errorf("group %q is not closed", cur.Name)
+	}
+	if _, err := r.Read(make([]byte, 1)); err != io.EOF {
+		return nil, fmt.Errorf("data after the last block")
+	}
+	return groups, nil
+}
+
+func readASEName(r io.Reader) (string, error) {
+	var n uint16
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return "", err
+	}
+	if n == 0 {
+		return "", fmt.Errorf("name without terminator")
+	}
+	units := make([]uint16, n)
+	if err := binary.Read(r, binary.BigEndian, units); err != nil {
+		return "", err
+	}
+	if units[n-1] != 0 {
+		return "", fmt.Errorf("name without terminator")
+	}
+	return string(utf16.Decode(units[:n-1])), nil
+}
+
+func readASEColor(r *bytes.Reader) (Swatch, error) {
+	name, err := readASEName(r)
+	if err != nil {
+		return Swatch{}, err
+	}
+	var model [4]byte
+	if _, err := io.ReadFull(r, model[:]); err != nil {
+		return Swatch{}, err
+	}
+	if string(model[:]) != "RGB " {
+		return Swatch{}, fmt.Errorf("swatch %q: colour model %q is not supported", name, model[:])
+	}
+	var rgb [3]float32
+	var typ uint16
+	if err := binary.Read(r, binary.BigEndian, &rgb); err != nil {
+		return Swatch{}, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &typ); err != nil {
+		return Swatch{}, err
+	}
+	if r.Len() != 0 {
+		return Swatch{}, fmt.Errorf("swatch %q: %d unexpected bytes", name, r.Len())
+	}
+	to8 := func(f float32) uint8 { return uint8(math.Round(math.Max(0, math.Min(1, float64(f))) * 255)) }
+	return Swatch{Name: name, Color: colors.Color{R: to8(rgb[0]), G: to8(rgb[1]), B: to8(rgb[2]), A: 0xff}}, nil
+}
+
+// sameSwatches reports the first difference between two swatch lists.
+func sameSwatches(want, got []SwatchGroup) error {
+	if len(want) != len(got) {
+		return fmt.Errorf("%d groups, want %d", len(got), len(want))
+	}
+	for i, g := range want {
+		if got[i].Name != g.Name {
+			return fmt.Errorf("group %d is %q, want %q", i, got[i].Name, g.Name)
+		}
+		if len(got[i].Swatches) != len(g.Swatches) {
+			return fmt.Errorf("group %q has %d swatches, want %d", g.Name, len(got[i].Swatches), len(g.Swatches))
+		}
+		for j, s := range g.Swatches {
+			if got[i].Swatches[j] != s {
+				return fmt.Errorf("swatch %q %s decoded as %q %s", s.Name, s.Color.Hex(), got[i].Swatches[j].Name, got[i].Swatches[j].Color.Hex())
+			}
+		}
+	}
+	return nil
+}
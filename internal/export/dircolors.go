@@ -0,0 +1,61 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "dircolors", Description: "GNU dircolors database for ls", Generate: dircolors})
+}
+
+// dircolorsExtensions groups file extensions by the role they are shown in.
+var dircolorsExtensions = []struct {
+	role palette.Role
+	exts []string
+}{
+	{palette.Keyword, []string{".tar", ".tgz", ".gz", ".xz", ".zst", ".bz2", ".zip", ".7z", ".rar", ".deb", ".rpm", ".vsix"}},
+	{palette.String, []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".mp4", ".mkv", ".mp3", ".flac"}},
+	{palette.Function, []string{".go", ".py", ".rs", ".sh", ".js", ".ts", ".c", ".h"}},
+	{palette.Type, []string{".json", ".yaml", ".yml", ".toml", ".ini", ".conf", ".mod", ".sum"}},
+	{palette.Muted, []string{".log", ".bak", ".tmp", ".swp", ".orig", ".lock"}},
+}
+
+func dircolors(v theme.Variant, p *palette.Palette) ([]File, error) {
+	var b strings.Builder
+	b.WriteString(header("#", "dircolors", v))
+	b.WriteString("\nCOLORTERM ?*\nTERM *\n\n")
+	entry := func(key, sgr string) {
+		fmt.Fprintf(&b, "%-12s %s\n", key, sgr)
+	}
+	entry("RESET", "0")
+	entry("NORMAL", "0")
+	entry("FILE", "0")
+	entry("DIR", fg(p.Color(palette.Info), "1"))
+	entry("LINK", fg(p.Color(palette.Property)))
+	entry("MULTIHARDLINK", "0")
+	entry("FIFO", fg(p.Color(palette.Warning)))
+	entry("SOCK", fg(p.Color(palette.Keyword)))
+	entry("DOOR", fg(p.Color(palette.Keyword)))
+	entry("BLK", fg(p.Color(palette.Warning), "1"))
+	entry("CHR", fg(p.Color(palette.Warning), "1"))
+	entry("ORPHAN", fg(p.Color(palette.Error))+";"+bg(p.Color(palette.Surface)))
+	entry("MISSING", fg(p.Color(palette.Error)))
+	entry("SETUID", fg(p.Color(palette.Background))+";"+bg(p.Color(palette.Error)))
+	entry("SETGID", fg(p.Color(palette.Background))+";"+bg(p.Color(palette.Warning)))
+	entry("CAPABILITY", "0")
+	entry("STICKY_OTHER_WRITABLE", fg(p.Color(palette.Background))+";"+bg(p.Color(palette.Added)))
+	entry("OTHER_WRITABLE", fg(p.Color(palette.Info))+";"+bg(p.Color(palette.Surface)))
+	entry("STICKY", fg(p.Color(palette.Foreground))+";"+bg(p.Color(palette.Info)))
+	entry("EXEC", fg(p.Color(palette.Added), "1"))
+	for _, g := range dircolorsExtensions {
+		fmt.Fprintf(&b, "\n# %s\n", g.role)
+		for _, ext := range g.exts {
+			entry(ext, fg(p.Color(g.role)))
+		}
+	}
+	return []File{{Path: BaseName(v) + ".dircolors", Data: []byte(b.String())}}, nil
+}
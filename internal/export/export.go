@@ -0,0 +1,112 @@
+// Package export renders a theme variant into configuration files for tools
+// outside the editor. Each format registers an Exporter from its own file.
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// ConfigDir is where installers place exported files, relative to the
+// user's home directory.
+const ConfigDir = ".config/caffeinated-rust"
+
+// File is one generated file. Path is slash separated and relative to the
+// export directory.
+type File struct {
+	Path string
+	Data []byte
+	Mode os.FileMode
+}
+
+// Exporter renders one format.
+type Exporter struct {
+	Name        string
+	Description string
+	Generate    func(v theme.Variant, p *palette.Palette) ([]File, error)
+}
+
+var registry = map[string]Exporter{}
+
+// Register adds an exporter. It is called from init functions.
+func Register(e Exporter) {
+	if _, dup := registry[e.Name]; dup {
+		panic("export: duplicate exporter " + e.Name)
+	}
+	registry[e.Name] = e
+}
+
+// Lookup returns the exporter for a format name.
+func Lookup(name string) (Exporter, error) {
+	e, ok := registry[name]
+	if !ok {
+		return Exporter{}, fmt.Errorf("unknown export format %q (have %s)", name, strings.Join(Names(), ", "))
+	}
+	return e, nil
+}
+
+// Names returns the registered format names, sorted.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for n := range registry {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run generates the named format for v.
+func Run(name string, v theme.Variant) ([]File, error) {
+	e, err := Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		return nil, err
+	}
+	files, err := e.Generate(v, p)
+	if err != nil {
+		return nil, fmt.Errorf("export %s: %w", name, err)
+	}
+	return files, nil
+}
+
+// Write stores files under dir.
+func Write(dir string, files []File) error {
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		if err := os.WriteFile(path, f.Data, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BaseName returns the file name stem for a variant: "caffeinated-rust" for
+// the default variant and "caffeinated-rust-<id>" for the others.
+func BaseName(v theme.Variant) string {
+	if v.ID == theme.DefaultVariant || v.ID == "" {
+		return "caffeinated-rust"
+	}
+	return "caffeinated-rust-" + v.ID
+}
+
+// header returns the generated-file banner using the given comment prefix.
+func header(comment, format string, v theme.Variant) string {
+	return fmt.Sprintf("%s %s for %s.\n%s Generated by \"caffeinated export -format %s\"; do not edit.\n",
+		comment, v.Theme.Name, format, comment, format)
+}
@@ -0,0 +1,117 @@
+package export
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata/<format>")
+
+// defaultVariant loads the default variant from the repository root.
+func defaultVariant(t *testing.T) theme.Variant {
+	t.Helper()
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// TestGolden compares the default variant's export of every format that
+// has a testdata/<format> directory with the files in it, which must be
+// exactly those exported. File paths are relative to the format's
+// directory, so a leading "<format>/" is dropped. Run with -update to
+// rewrite them; to add goldens for a format, create its directory first.
+func TestGolden(t *testing.T) {
+	v := defaultVariant(t)
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			dir := filepath.Join("testdata", name)
+			if _, err := os.Stat(dir); os.IsNotExist(err) {
+				t.Skipf("no golden files in %s", dir)
+			}
+			files, err := Run(name, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range files {
+				files[i].Path = strings.TrimPrefix(files[i].Path, name+"/")
+			}
+			if *update {
+				if err := os.RemoveAll(dir); err != nil {
+					t.Fatal(err)
+				}
+				if err := Write(dir, files); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			checkGolden(t, dir, files)
+		})
+	}
+}
+
+func checkGolden(t *testing.T, dir string, files []File) {
+	t.Helper()
+	want := map[string]bool{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		want[filepath.ToSlash(rel)] = true
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if !want[f.Path] {
+			t.Errorf("%s: no golden file", f.Path)
+			continue
+		}
+		delete(want, f.Path)
+		golden, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(golden, f.Data) {
+			t.Errorf("%s differs from the golden file; rerun with -update if the change is intended:\n%s", f.Path, diffLines(string(golden), string(f.Data)))
+		}
+	}
+	var stale []string
+	for path := range want {
+		stale = append(stale, path)
+	}
+	sort.Strings(stale)
+	for _, path := range stale {
+		t.Errorf("%s: golden file is no longer generated", path)
+	}
+}
+
+// diffLines describes the lines where got differs from want.
+func diffLines(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < max(len(w), len(g)); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			fmt.Fprintf(&b, "\tline %d: want %q, got %q\n", i+1, wl, gl)
+		}
+	}
+	return b.String()
+}
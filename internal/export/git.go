@@ -0,0 +1,71 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "git", Description: "git color.* settings for diff, status, branch and grep", Generate: git})
+}
+
+func git(v theme.Variant, p *palette.Palette) ([]File, error) {
+	// Hex colours must be quoted: an unquoted # starts a gitconfig comment.
+	c := func(r palette.Role, attrs ...string) string {
+		return strings.TrimSpace(fmt.Sprintf("%q %s", p.Hex(r), strings.Join(attrs, " ")))
+	}
+	sections := []struct {
+		name   string
+		values [][2]string
+	}{
+		{`color "diff"`, [][2]string{
+			{"meta", c(palette.Warning, "bold")},
+			{"frag", c(palette.Info)},
+			{"func", c(palette.Function)},
+			{"commit", c(palette.Modified)},
+			{"old", c(palette.Deleted)},
+			{"new", c(palette.Added)},
+			{"whitespace", `normal ` + c(palette.Error)},
+		}},
+		{`color "status"`, [][2]string{
+			{"header", c(palette.Muted)},
+			{"branch", c(palette.Accent, "bold")},
+			{"nobranch", c(palette.Error, "bold")},
+			{"added", c(palette.Added)},
+			{"changed", c(palette.Modified)},
+			{"untracked", c(palette.Muted)},
+			{"unmerged", c(palette.Error)},
+		}},
+		{`color "branch"`, [][2]string{
+			{"current", c(palette.Accent, "bold")},
+			{"local", c(palette.Foreground)},
+			{"remote", c(palette.Info)},
+			{"upstream", c(palette.Constant)},
+		}},
+		{`color "decorate"`, [][2]string{
+			{"HEAD", c(palette.Keyword, "bold")},
+			{"branch", c(palette.Accent)},
+			{"remoteBranch", c(palette.Info)},
+			{"tag", c(palette.String)},
+			{"stash", c(palette.Muted)},
+		}},
+		{`color "grep"`, [][2]string{
+			{"match", c(palette.Background) + " " + fmt.Sprintf("%q", p.Hex(palette.FindMatch))},
+			{"filename", c(palette.Property)},
+			{"lineNumber", c(palette.Muted)},
+			{"separator", c(palette.Muted)},
+		}},
+	}
+	var b strings.Builder
+	b.WriteString(header("#", "git", v))
+	for _, s := range sections {
+		fmt.Fprintf(&b, "\n[%s]\n", s.name)
+		for _, kv := range s.values {
+			fmt.Fprintf(&b, "\t%s = %s\n", kv[0], kv[1])
+		}
+	}
+	return []File{{Path: BaseName(v) + ".gitconfig", Data: []byte(b.String())}}, nil
+}
@@ -0,0 +1,230 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "jq", Description: "JQ_COLORS for jq and the jq-based yq, and yq and fx wrappers, coloured like JSON and YAML in the editor", Generate: jq})
+}
+
+// jqFields are the JQ_COLORS fields in order, each with the JSON scope
+// stack the editor gives that kind of value.
+var jqFields = []struct {
+	name  string
+	scope []string
+}{
+	{"null", []string{"source.json", "constant.language.json"}},
+	{"false", []string{"source.json", "constant.language.json"}},
+	{"true", []string{"source.json", "constant.language.json"}},
+	{"numbers", []string{"source.json", "constant.numeric.json"}},
+	{"strings", []string{"source.json", "meta.structure.dictionary.value.json", "string.quoted.double.json"}},
+	{"arrays", []string{"source.json", "punctuation.definition.array.begin.json"}},
+	{"objects", []string{"source.json", "punctuation.definition.dictionary.begin.json"}},
+	{"object keys", []string{"source.json", "meta.structure.dictionary.json", "support.type.property-name.json"}},
+}
+
+// yqCodes are the fixed SGR colours mikefarah/yq prints with -C, each with
+// the YAML scope stack the editor gives that kind of value. yq has one
+// colour for all strings; plain scalars are the common case.
+var yqCodes = []struct {
+	code, name string
+	scope      []string
+}{
+	{"95", "numbers and booleans", []string{"source.yaml", "constant.numeric.integer.yaml"}},
+	{"36", "keys", []string{"source.yaml", "entity.name.tag.yaml"}},
+	{"93", "anchors and aliases", []string{"source.yaml", "variable.other.alias.yaml"}},
+	{"32", "strings", []string{"source.yaml", "string.unquoted.plain.out.yaml"}},
+	{"90", "comments", []string{"source.yaml", "comment.line.number-sign.yaml"}},
+}
+
+// fxSlots are the terminal colours fx's default theme (FX_THEME=1) paints
+// JSON values in, each with the jq field of that kind of value. fx colours
+// null with 243 of the 256-colour palette and false like true.
+var fxSlots = []struct {
+	slot        int
+	name, field string
+}{
+	{4, "keys", "object keys"},
+	{2, "strings", "strings"},
+	{6, "numbers", "numbers"},
+	{5, "booleans", "true"},
+	{243, "null", "null"},
+}
+
+// tokenSGR returns the SGR parameters of the token a scope stack resolves
+// to: its font style, then its colour formatted by color.
+func tokenSGR(t *theme.Theme, stack []string, color func(colors.Color) string) (string, error) {
+	st := t.Match(stack...)
+	c, err := colors.ParseHex(st.Foreground.Value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", strings.Join(stack, " "), err)
+	}
+	var attrs []string
+	for _, w := range strings.Fields(st.FontStyle.Value) {
+		switch w {
+		case "bold":
+			attrs = append(attrs, "1")
+		case "italic":
+			attrs = append(attrs, "3")
+		case "underline":
+			attrs = append(attrs, "4")
+		}
+	}
+	return strings.Join(append(attrs, color(c.Opaque())), ";"), nil
+}
+
+// jsonTokenColor returns the colour of the JSON token of a jq field.
+func jsonTokenColor(t *theme.Theme, field string) (colors.Color, error) {
+	for _, f := range jqFields {
+		if f.name == field {
+			return colors.ParseHex(t.Match(f.scope...).Foreground.Value)
+		}
+	}
+	return colors.Color{}, fmt.Errorf("no jq field %q", field)
+}
+
+func jq(v theme.Variant, p *palette.Palette) ([]File, error) {
+	// jq before 1.7.1 reads seven fields and takes at most 12 characters a
+	// colour, too few for a 24-bit escape, so the default value uses the
+	// 256 colour cube and the 24-bit one is left for newer releases.
+	var cube, full []string
+	for _, f := range jqFields {
+		c, err := tokenSGR(v.Theme, f.scope, func(c colors.Color) string { return fmt.Sprintf("38;5;%d", colors.Nearest256(c)) })
+		if err != nil {
+			return nil, err
+		}
+		if len(c) > 12 {
+			return nil, fmt.Errorf("JQ_COLORS %s: %q is longer than jq takes", f.name, c)
+		}
+		cube = append(cube, c)
+		c, err = tokenSGR(v.Theme, f.scope, func(c colors.Color) string { return fg(c) })
+		if err != nil {
+			return nil, err
+		}
+		full = append(full, c)
+	}
+	var names []string
+	for _, f := range jqFields {
+		names = append(names, f.name)
+	}
+
+	var b strings.Builder
+	b.WriteString(header("#", "jq", v))
+	fmt.Fprintf(&b, `# Source it from ~/.bashrc or ~/.zshrc. The fields, in jq's order, are
+# %s.
+#
+# The python yq (kislyuk/yq) prints through jq and takes these colours too;
+# for mikefarah/yq, source %[4]s.yq.sh, and for fx, %[4]s.fx.sh.
+
+export JQ_COLORS='%[2]s'
+
+# For jq releases that take 24-bit colours:
+# export JQ_COLORS='%[3]s'
+`, strings.Join(names, ", "), strings.Join(cube, ":"), strings.Join(full, ":"), BaseName(v))
+
+	yq, err := yqWrapper(v)
+	if err != nil {
+		return nil, err
+	}
+	fx, err := fxWrapper(v)
+	if err != nil {
+		return nil, err
+	}
+	return []File{
+		{Path: BaseName(v) + ".jq.sh", Data: []byte(b.String())},
+		{Path: BaseName(v) + ".yq.sh", Data: yq},
+		{Path: BaseName(v) + ".fx.sh", Data: fx},
+	}, nil
+}
+
+// yqWrapper writes a yq shell function that maps the fixed colours of
+// mikefarah/yq onto the editor's YAML colours with sed. It leaves yq alone
+// when colour is off or it would not print: NO_COLOR, a pipe, -M and -i.
+func yqWrapper(v theme.Variant) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString(header("#", "jq", v))
+	b.WriteString(`# Source it from ~/.bashrc or ~/.zshrc; it needs bash or zsh, whose
+# PIPESTATUS and pipestatus give it yq's exit status. mikefarah/yq has fixed
+# colours; this yq function recolours them in 24-bit colour:
+`)
+	var exprs []string
+	for _, c := range yqCodes {
+		sgr, err := tokenSGR(v.Theme, c.scope, func(c colors.Color) string { return fg(c) })
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "#   %-21s %s\n", c.name, strings.Join(c.scope[1:], " "))
+		exprs = append(exprs, fmt.Sprintf("\t\t-e \"s/${e}\\[%sm/${e}[%sm/g\"", c.code, sgr))
+	}
+	b.WriteString(`
+yq() {
+	case " $* " in
+	*" -i "* | *" --inplace "* | *" --inplace="* | *" -M "* | *" --no-colors "*)
+		command yq "$@"
+		return
+		;;
+	esac
+	if [ -n "${NO_COLOR-}" ] || [ ! -t 1 ]; then
+		command yq "$@"
+		return
+	fi
+	local e
+	e=$(printf '\033')
+	command yq -C "$@" | sed \
+`)
+	b.WriteString(strings.Join(exprs, " \\\n"))
+	b.WriteString(`
+	return "${PIPESTATUS[0]:-${pipestatus[1]}}"
+}
+`)
+	return []byte(b.String()), nil
+}
+
+// fxWrapper writes an fx shell function. fx has only built-in themes, so
+// while it browses a document the function points the terminal colours of
+// the default theme at the editor's JSON colours with OSC 4, and resets
+// them with OSC 104 when fx exits. Output that stays on the screen, from a
+// query or a pipe, is left in the terminal's own colours, since resetting
+// the palette would repaint it.
+func fxWrapper(v theme.Variant) ([]byte, error) {
+	var b strings.Builder
+	b.WriteString(header("#", "jq", v))
+	b.WriteString(`# Source it from ~/.bashrc or ~/.zshrc. fx has only built-in themes; its
+# default one paints JSON in terminal colours, which this fx function sets
+# to the editor's JSON colours while fx is open:
+`)
+	var set, slots []string
+	for _, s := range fxSlots {
+		c, err := jsonTokenColor(v.Theme, s.field)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "#   %-4d %s\n", s.slot, s.name)
+		set = append(set, fmt.Sprintf(`\033]4;%d;rgb:%02x/%02x/%02x\007`, s.slot, c.R, c.G, c.B))
+		slots = append(slots, fmt.Sprint(s.slot))
+	}
+	b.WriteString(`
+fx() {
+	if [ -n "${NO_COLOR-}" ] || [ ! -t 1 ] || [ $# -gt 1 ] || { [ $# -eq 1 ] && [ ! -t 0 ]; }; then
+		command fx "$@"
+		return
+	fi
+	printf '`)
+	b.WriteString(strings.Join(set, ""))
+	b.WriteString(`'
+	FX_THEME=1 command fx "$@"
+	local rc=$?
+	printf '\033]104;`)
+	b.WriteString(strings.Join(slots, ";"))
+	b.WriteString(`\007'
+	return $rc
+}
+`)
+	return []byte(b.String()), nil
+}
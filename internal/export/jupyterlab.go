@@ -0,0 +1,346 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "jupyterlab", Description: "JupyterLab 4 theme extension package", Generate: jupyterlab})
+}
+
+// jupyterVersion is the version of the generated package. Bump it when the
+// package layout or the variable mapping changes.
+const jupyterVersion = "0.1.0"
+
+// jupyterMirror maps the CodeMirror token classes JupyterLab styles to a
+// scope stack, matched against tokenColors like a token in that position.
+// Notebooks are mostly Python, so code tokens use Python scopes.
+var jupyterMirror = []struct {
+	name  string
+	stack []string
+	// role is used instead of a scope when set.
+	role palette.Role
+}{
+	{"keyword", []string{"source.python", "keyword.control.flow.python"}, ""},
+	{"atom", []string{"source.python", "constant.language.python"}, ""},
+	{"number", []string{"source.python", "constant.numeric.dec.python"}, ""},
+	{"def", []string{"source.python", "meta.function.python", "entity.name.function.python"}, ""},
+	{"variable", []string{"source.python", "variable.other.python"}, ""},
+	{"variable-2", []string{"source.python", "variable.parameter.function.language.python"}, ""},
+	{"variable-3", []string{"source.python", "entity.name.type.class.python"}, ""},
+	{"punctuation", []string{"source.python", "punctuation.separator.element.python"}, ""},
+	{"property", []string{"source.python", "variable.other.property.python"}, ""},
+	{"operator", []string{"source.python", "keyword.operator.arithmetic.python"}, ""},
+	{"comment", []string{"source.python", "comment.line.number-sign.python"}, ""},
+	{"string", []string{"source.python", "string.quoted.single.python"}, ""},
+	{"string-2", []string{"source.python", "string.regexp.quoted.single.python"}, ""},
+	{"meta", []string{"source.python", "meta.function.decorator.python", "entity.name.function.decorator.python"}, ""},
+	{"qualifier", []string{"source.css", "entity.other.attribute-name.class.css"}, ""},
+	{"builtin", []string{"source.python", "support.function.builtin.python"}, ""},
+	{"bracket", []string{"source.python", "punctuation.parenthesis.begin.python"}, ""},
+	{"tag", []string{"text.html.basic", "entity.name.tag.html"}, ""},
+	{"attribute", []string{"text.html.basic", "entity.other.attribute-name.html"}, ""},
+	{"header", []string{"text.html.markdown", "markup.heading.markdown"}, ""},
+	{"quote", []string{"text.html.markdown", "markup.quote.markdown"}, ""},
+	{"link", []string{"text.html.markdown", "markup.underline.link.markdown"}, ""},
+	{"error", nil, palette.Error},
+	{"hr", []string{"text.html.markdown", "meta.separator.markdown"}, ""},
+}
+
+// jupyterlab writes a JupyterLab 4 theme extension: the CSS variables, the
+// plugin registering them with the theme manager and the npm and Python
+// packaging that "pip install ." builds into a prebuilt extension.
+func jupyterlab(v theme.Variant, p *palette.Palette) ([]File, error) {
+	eff, err := colorreg.Resolve(v.Theme)
+	if err != nil {
+		return nil, err
+	}
+	npm := "jupyterlab-" + BaseName(v)
+	py := strings.ReplaceAll(npm, "-", "_")
+	dir := "jupyterlab/" + npm + "/"
+	vars := jupyterVars(v, p, eff)
+
+	var css strings.Builder
+	css.WriteString("/*\n" + header(" *", "jupyterlab", v) + " */\n\n:root {\n")
+	for _, kv := range vars {
+		fmt.Fprintf(&css, "  %s: %s;\n", kv[0], kv[1])
+	}
+	css.WriteString("}\n")
+
+	pkg := map[string]any{
+		"name":        npm,
+		"version":     jupyterVersion,
+		"description": v.Theme.Name + " theme for JupyterLab, generated from the VS Code theme.",
+		"keywords":    []string{"jupyter", "jupyterlab", "jupyterlab-extension", "jupyterlab-theme"},
+		"license":     "MIT",
+		"author":      map[string]string{"name": "Caffeinated-Minds"},
+		"repository":  map[string]string{"type": "git", "url": "https://github.com/caffeinated-minds/caffeinated-rust.git"},
+		"files":       []string{"lib/**/*.js", "style/**/*.css", "style/index.js"},
+		"main":        "lib/index.js",
+		"types":       "lib/index.d.ts",
+		"style":       "style/index.css",
+		"styleModule": "style/index.js",
+		"sideEffects": []string{"style/*.css", "style/index.js"},
+		"scripts": map[string]string{
+			"build":                  "jlpm build:lib && jlpm build:labextension:dev",
+			"build:prod":             "jlpm build:lib && jlpm build:labextension",
+			"build:lib":              "tsc",
+			"build:labextension":     "jupyter labextension build .",
+			"build:labextension:dev": "jupyter labextension build --development True .",
+		},
+		"dependencies": map[string]string{
+			"@jupyterlab/application": "^4.0.0",
+			"@jupyterlab/apputils":    "^4.0.0",
+		},
+		"devDependencies": map[string]string{
+			"@jupyterlab/builder": "^4.0.0",
+			"typescript":          "~5.0.2",
+		},
+		"jupyterlab": map[string]any{
+			"extension": true,
+			"outputDir": py + "/labextension",
+			"themePath": "style/index.css",
+		},
+	}
+	var pkgJSON bytes.Buffer
+	enc := json.NewEncoder(&pkgJSON)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(pkg); err != nil {
+		return nil, err
+	}
+
+	files := []File{
+		{Path: dir + "package.json", Data: pkgJSON.Bytes()},
+		{Path: dir + "pyproject.toml", Data: []byte(jupyterPyproject(npm, py))},
+		{Path: dir + "install.json", Data: []byte(fmt.Sprintf("{\n  \"packageManager\": \"python\",\n  \"packageName\": %q,\n  \"uninstallInstructions\": \"Use your Python package manager (pip, conda, etc.) to uninstall the package %s\"\n}\n", py, py))},
+		{Path: dir + "tsconfig.json", Data: []byte(jupyterTSConfig)},
+		{Path: dir + "src/index.ts", Data: []byte(jupyterPlugin(v, npm))},
+		{Path: dir + "style/index.css", Data: []byte("@import url('./variables.css');\n")},
+		{Path: dir + "style/index.js", Data: []byte("import './index.css';\n")},
+		{Path: dir + "style/variables.css", Data: []byte(css.String())},
+		{Path: dir + py + "/__init__.py", Data: []byte(jupyterInit(npm))},
+		{Path: dir + "README.md", Data: []byte(fmt.Sprintf("# %s\n\nThe %s theme for JupyterLab 4, generated from the VS Code theme by `caffeinated export -format jupyterlab`; do not edit.\n\nInstall it with\n\n```sh\npip install .\n```\n\nand pick **Settings > Theme > %s**.\n", npm, v.Theme.Name, v.Theme.Name))},
+	}
+	return files, nil
+}
+
+// jupyterVars lists the CSS variables in output order.
+func jupyterVars(v theme.Variant, p *palette.Palette, eff *colorreg.Effective) [][2]string {
+	bg := p.Color(palette.Background)
+	// wb reads an effective workbench colour, flattened onto the editor
+	// background because JupyterLab layers its surfaces differently.
+	wb := func(id string, fallback palette.Role) colors.Color {
+		if c, ok := eff.Color(id); ok {
+			return c.Over(bg)
+		}
+		return p.Color(fallback).Over(bg)
+	}
+	mix := func(c colors.Color, a float64, under colors.Color) colors.Color {
+		return c.WithAlpha(uint8(a*255 + 0.5)).Over(under)
+	}
+	var out [][2]string
+	set := func(name string, c colors.Color) {
+		out = append(out, [2]string{name, c.Opaque().Hex()})
+	}
+	ref := func(name, to string) {
+		out = append(out, [2]string{name, "var(" + to + ")"})
+	}
+
+	fg := p.Color(palette.Foreground)
+	muted := p.Color(palette.Muted)
+	border := p.Color(palette.Border).Over(bg)
+	surface := wb("input.background", palette.Surface)
+
+	// Layout runs from the notebook background to the lightest panel
+	// chrome; the inverse layout and fonts run the other way.
+	layout := []colors.Color{bg, surface, border, mix(muted, 0.5, border), muted}
+	for i, c := range layout {
+		set(fmt.Sprintf("--jp-layout-color%d", i), c)
+	}
+	inverse := []colors.Color{fg, wb("foreground", palette.Foreground), wb("descriptionForeground", palette.Muted), muted, border}
+	for i, c := range inverse {
+		set(fmt.Sprintf("--jp-inverse-layout-color%d", i), c)
+	}
+	fonts := []colors.Color{fg, wb("foreground", palette.Foreground), wb("descriptionForeground", palette.Muted), wb("disabledForeground", palette.Muted)}
+	for i, c := range fonts {
+		set(fmt.Sprintf("--jp-ui-font-color%d", i), c)
+		ref(fmt.Sprintf("--jp-content-font-color%d", i), fmt.Sprintf("--jp-ui-font-color%d", i))
+	}
+	for i, c := range []colors.Color{bg, surface, border, muted} {
+		set(fmt.Sprintf("--jp-ui-inverse-font-color%d", i), c)
+	}
+	set("--jp-content-link-color", p.Color(palette.Info))
+	for i, c := range []colors.Color{wb("input.border", palette.Border), border, wb("sideBar.border", palette.Border), surface} {
+		set(fmt.Sprintf("--jp-border-color%d", i), c)
+	}
+
+	// State colours fade from the role into the background.
+	ramp := func(prefix string, c colors.Color, n int) {
+		for i, a := range []float64{1, 1, 0.7, 0.45, 0.25}[:n] {
+			set(fmt.Sprintf("%s%d", prefix, i), mix(c, a, bg))
+		}
+	}
+	ramp("--jp-brand-color", p.Color(palette.Accent), 5)
+	ramp("--jp-accent-color", p.Color(palette.Keyword), 4)
+	ramp("--jp-warn-color", p.Color(palette.Warning), 4)
+	ramp("--jp-error-color", p.Color(palette.Error), 4)
+	ramp("--jp-success-color", p.Color(palette.Added), 4)
+	ramp("--jp-info-color", p.Color(palette.Info), 4)
+
+	ref("--jp-cell-editor-background", "--jp-layout-color1")
+	ref("--jp-cell-editor-border-color", "--jp-border-color1")
+	ref("--jp-cell-editor-active-background", "--jp-layout-color0")
+	set("--jp-cell-prompt-not-active-font-color", muted)
+	set("--jp-cell-inprompt-font-color", p.Color(palette.Accent))
+	set("--jp-cell-outprompt-font-color", p.Color(palette.Keyword))
+	set("--jp-notebook-multiselected-color", p.Color(palette.Selection).Over(bg))
+	set("--jp-rendermime-error-background", wb("inputValidation.errorBackground", palette.Error))
+	ref("--jp-rendermime-table-row-background", "--jp-layout-color1")
+	set("--jp-rendermime-table-row-hover-background", wb("list.hoverBackground", palette.Selection))
+	ref("--jp-input-background", "--jp-layout-color1")
+	set("--jp-input-border-color", wb("input.border", palette.Border))
+	ref("--jp-input-active-border-color", "--jp-brand-color1")
+	ref("--jp-input-active-background", "--jp-layout-color1")
+	set("--jp-editor-selected-background", wb("editor.inactiveSelectionBackground", palette.Selection))
+	set("--jp-editor-selected-focused-background", wb("editor.selectionBackground", palette.Selection))
+	set("--jp-editor-cursor-color", wb("editorCursor.foreground", palette.Foreground))
+	ref("--jp-scrollbar-background-color", "--jp-layout-color0")
+	set("--jp-scrollbar-thumb-color", wb("scrollbarSlider.background", palette.Border))
+	set("--jp-search-selected-match-background-color", wb("editor.findMatchBackground", palette.FindMatch))
+	set("--jp-search-unselected-match-background-color", wb("editor.findMatchHighlightBackground", palette.FindMatch))
+
+	for _, m := range jupyterMirror {
+		if m.role != "" {
+			set("--jp-mirror-editor-"+m.name+"-color", p.Color(m.role).Over(bg))
+			continue
+		}
+		c, err := colors.ParseHex(v.Theme.Match(m.stack...).Foreground.Value)
+		if err != nil {
+			c = fg
+		}
+		set("--jp-mirror-editor-"+m.name+"-color", c.Over(bg))
+	}
+	return out
+}
+
+func jupyterPlugin(v theme.Variant, npm string) string {
+	return fmt.Sprintf(`// %s theme for JupyterLab.
+// Generated by "caffeinated export -format jupyterlab"; do not edit.
+
+import {
+  JupyterFrontEnd,
+  JupyterFrontEndPlugin
+} from '@jupyterlab/application';
+
+import { IThemeManager } from '@jupyterlab/apputils';
+
+const plugin: JupyterFrontEndPlugin<void> = {
+  id: '%s:plugin',
+  description: '%s',
+  requires: [IThemeManager],
+  autoStart: true,
+  activate: (app: JupyterFrontEnd, manager: IThemeManager) => {
+    const style = '%s/index.css';
+    manager.register({
+      name: '%s',
+      isLight: false,
+      themeScrollbars: true,
+      load: () => manager.loadCSS(style),
+      unload: () => Promise.resolve(undefined)
+    });
+  }
+};
+
+export default plugin;
+`, v.Theme.Name, npm, "The "+v.Theme.Name+" theme.", npm, v.Theme.Name)
+}
+
+const jupyterTSConfig = `{
+  "compilerOptions": {
+    "allowSyntheticDefaultImports": true,
+    "composite": true,
+    "declaration": true,
+    "esModuleInterop": true,
+    "incremental": true,
+    "jsx": "react",
+    "module": "esnext",
+    "moduleResolution": "node",
+    "noEmitOnError": true,
+    "noImplicitAny": true,
+    "noUnusedLocals": true,
+    "preserveWatchOutput": true,
+    "resolveJsonModule": true,
+    "outDir": "lib",
+    "rootDir": "src",
+    "strict": true,
+    "strictNullChecks": true,
+    "target": "ES2018",
+    "types": []
+  },
+  "include": ["src/*"]
+}
+`
+
+func jupyterPyproject(npm, py string) string {
+	return fmt.Sprintf(`[build-system]
+requires = ["hatchling>=1.5.0", "jupyterlab>=4.0.0,<5", "hatch-nodejs-version>=0.3.2"]
+build-backend = "hatchling.build"
+
+[project]
+name = %q
+readme = "README.md"
+license = { text = "MIT" }
+requires-python = ">=3.8"
+classifiers = [
+    "Framework :: Jupyter",
+    "Framework :: Jupyter :: JupyterLab",
+    "Framework :: Jupyter :: JupyterLab :: 4",
+    "Framework :: Jupyter :: JupyterLab :: Extensions",
+    "Framework :: Jupyter :: JupyterLab :: Extensions :: Prebuilt",
+    "Framework :: Jupyter :: JupyterLab :: Extensions :: Themes",
+    "License :: OSI Approved :: MIT License",
+]
+dynamic = ["version", "description", "authors", "urls", "keywords"]
+
+[tool.hatch.version]
+source = "nodejs"
+
+[tool.hatch.metadata.hooks.nodejs]
+fields = ["description", "authors", "urls"]
+
+[tool.hatch.build.targets.sdist]
+artifacts = ["%[2]s/labextension"]
+
+[tool.hatch.build.targets.wheel.shared-data]
+"%[2]s/labextension" = "share/jupyter/labextensions/%[3]s"
+"install.json" = "share/jupyter/labextensions/%[3]s/install.json"
+
+[tool.hatch.build.hooks.jupyter-builder]
+dependencies = ["hatch-jupyter-builder>=0.5"]
+build-function = "hatch_jupyter_builder.npm_builder"
+ensured-targets = ["%[2]s/labextension/package.json"]
+skip-if-exists = ["%[2]s/labextension/package.json"]
+
+[tool.hatch.build.hooks.jupyter-builder.build-kwargs]
+build_cmd = "build:prod"
+npm = ["jlpm"]
+`, py, py, npm)
+}
+
+func jupyterInit(npm string) string {
+	return fmt.Sprintf(`"""Generated by "caffeinated export -format jupyterlab"; do not edit."""
+
+
+def _jupyter_labextension_paths():
+    return [{"src": "labextension", "dest": %q}]
+`, npm)
+}
@@ -0,0 +1,152 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// A broken theme package only shows up as a blank JupyterLab, so every
+// variant's package is checked as JupyterLab reads it.
+func TestJupyterLabPackage(t *testing.T) {
+	vs, err := theme.LoadVariants("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vs {
+		files, err := Run("jupyterlab", v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		npm := "jupyterlab-" + BaseName(v)
+		if err := checkJupyterLab(files, "jupyterlab/"+npm+"/", npm, v.Theme.Name); err != nil {
+			t.Errorf("%s: %v", v.ID, err)
+		}
+	}
+}
+
+func TestJupyterLabCheckFindsMissingVariable(t *testing.T) {
+	v := defaultVariant(t)
+	files, err := Run("jupyterlab", v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	npm := "jupyterlab-" + BaseName(v)
+	dir := "jupyterlab/" + npm + "/"
+	for i, f := range files {
+		if f.Path == dir+"style/variables.css" {
+			files[i].Data = []byte(strings.Replace(string(f.Data), "--jp-layout-color0:", "--jp-layout-colour0:", 1))
+		}
+	}
+	if err := checkJupyterLab(files, dir, npm, v.Theme.Name); err == nil || !strings.Contains(err.Error(), "--jp-layout-color0") {
+		t.Errorf("check of a package without --jp-layout-color0 = %v", err)
+	}
+}
+
+// jupyterRequired are the variables the JupyterLab dark theme defines that
+// the generated theme must define too.
+var jupyterRequired = []string{
+	"--jp-border-color0", "--jp-border-color1", "--jp-border-color2", "--jp-border-color3",
+	"--jp-ui-font-color0", "--jp-ui-font-color1", "--jp-ui-font-color2", "--jp-ui-font-color3",
+	"--jp-ui-inverse-font-color0", "--jp-ui-inverse-font-color1", "--jp-ui-inverse-font-color2", "--jp-ui-inverse-font-color3",
+	"--jp-content-font-color0", "--jp-content-font-color1", "--jp-content-font-color2", "--jp-content-font-color3",
+	"--jp-content-link-color",
+	"--jp-layout-color0", "--jp-layout-color1", "--jp-layout-color2", "--jp-layout-color3", "--jp-layout-color4",
+	"--jp-inverse-layout-color0", "--jp-inverse-layout-color1", "--jp-inverse-layout-color2", "--jp-inverse-layout-color3", "--jp-inverse-layout-color4",
+	"--jp-brand-color0", "--jp-brand-color1", "--jp-brand-color2", "--jp-brand-color3", "--jp-brand-color4",
+	"--jp-accent-color0", "--jp-accent-color1", "--jp-accent-color2", "--jp-accent-color3",
+	"--jp-warn-color0", "--jp-warn-color1", "--jp-warn-color2", "--jp-warn-color3",
+	"--jp-error-color0", "--jp-error-color1", "--jp-error-color2", "--jp-error-color3",
+	"--jp-success-color0", "--jp-success-color1", "--jp-success-color2", "--jp-success-color3",
+	"--jp-info-color0", "--jp-info-color1", "--jp-info-color2", "--jp-info-color3",
+	"--jp-cell-editor-background", "--jp-cell-editor-border-color", "--jp-cell-editor-active-background",
+	"--jp-cell-prompt-not-active-font-color", "--jp-cell-inprompt-font-color", "--jp-cell-outprompt-font-color",
+	"--jp-notebook-multiselected-color", "--jp-rendermime-error-background",
+	"--jp-rendermime-table-row-background", "--jp-rendermime-table-row-hover-background",
+	"--jp-input-background", "--jp-input-border-color", "--jp-input-active-border-color", "--jp-input-active-background",
+	"--jp-editor-selected-background", "--jp-editor-selected-focused-background", "--jp-editor-cursor-color",
+	"--jp-scrollbar-background-color", "--jp-scrollbar-thumb-color",
+	"--jp-search-selected-match-background-color", "--jp-search-unselected-match-background-color",
+}
+
+var cssDecl = regexp.MustCompile(`^\s*(--[a-z0-9-]+):\s*([^;]+);\s*$`)
+
+// checkJupyterLab checks the generated package the way JupyterLab reads
+// it: the theme path in package.json must exist, the plugin must load the
+// CSS under the package name, and the variables must define every colour
+// the dark theme defines, each as a colour or a reference to a defined
+// variable.
+func checkJupyterLab(files []File, dir, npm, name string) error {
+	byPath := map[string][]byte{}
+	for _, f := range files {
+		byPath[strings.TrimPrefix(f.Path, dir)] = f.Data
+	}
+	var pkg struct {
+		Name       string
+		Style      string
+		Jupyterlab struct {
+			Extension bool
+			ThemePath string
+		}
+	}
+	if err := json.Unmarshal(byPath["package.json"], &pkg); err != nil {
+		return fmt.Errorf("package.json: %w", err)
+	}
+	if pkg.Name != npm || !pkg.Jupyterlab.Extension {
+		return fmt.Errorf("package.json: %q is not a JupyterLab extension named %q", pkg.Name, npm)
+	}
+	for _, p := range []string{pkg.Style, pkg.Jupyterlab.ThemePath} {
+		if _, ok := byPath[p]; !ok {
+			return fmt.Errorf("package.json refers to %q, which is not generated", p)
+		}
+	}
+	index := string(byPath[pkg.Jupyterlab.ThemePath])
+	for _, m := range regexp.MustCompile(`@import url\('\./([^']+)'\)`).FindAllStringSubmatch(index, -1) {
+		if _, ok := byPath[path.Join(path.Dir(pkg.Jupyterlab.ThemePath), m[1])]; !ok {
+			return fmt.Errorf("%s imports %s, which is not generated", pkg.Jupyterlab.ThemePath, m[1])
+		}
+	}
+	plugin := string(byPath["src/index.ts"])
+	for _, want := range []string{"manager.register(", "name: '" + name + "'", "isLight: false", "'" + npm + "/index.css'"} {
+		if !strings.Contains(plugin, want) {
+			return fmt.Errorf("src/index.ts does not contain %s", want)
+		}
+	}
+
+	defined := map[string]string{}
+	for i, line := range strings.Split(string(byPath["style/variables.css"]), "\n") {
+		m := cssDecl.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if _, dup := defined[m[1]]; dup {
+			return fmt.Errorf("variables.css:%d: %s is defined twice", i+1, m[1])
+		}
+		defined[m[1]] = m[2]
+	}
+	for name, value := range defined {
+		if to, ok := strings.CutPrefix(value, "var("); ok {
+			if _, ok := defined[strings.TrimSuffix(to, ")")]; !ok {
+				return fmt.Errorf("%s refers to undefined %s", name, value)
+			}
+		} else if _, err := colors.ParseHex(value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	required := append([]string(nil), jupyterRequired...)
+	for _, m := range jupyterMirror {
+		required = append(required, "--jp-mirror-editor-"+m.name+"-color")
+	}
+	for _, r := range required {
+		if _, ok := defined[r]; !ok {
+			return fmt.Errorf("variables.css does not define %s", r)
+		}
+	}
+	return nil
+}
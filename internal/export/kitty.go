@@ -0,0 +1,45 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "kitty", Description: "kitty terminal colour scheme", Generate: kitty})
+}
+
+func kitty(v theme.Variant, p *palette.Palette) ([]File, error) {
+	var b strings.Builder
+	b.WriteString(header("#", "kitty", v))
+	b.WriteString("\n")
+	set := func(key string, r palette.Role) {
+		fmt.Fprintf(&b, "%-24s %s\n", key, p.Hex(r))
+	}
+	set("foreground", palette.Foreground)
+	set("background", palette.Background)
+	set("selection_foreground", palette.Foreground)
+	fmt.Fprintf(&b, "%-24s %s\n", "selection_background", p.Color(palette.Selection).Over(p.Color(palette.Background)).Hex())
+	set("cursor", palette.Foreground)
+	set("cursor_text_color", palette.Background)
+	set("url_color", palette.Info)
+	set("active_border_color", palette.Accent)
+	set("inactive_border_color", palette.Border)
+	set("bell_border_color", palette.Warning)
+	set("tab_bar_background", palette.Surface)
+	set("active_tab_foreground", palette.Foreground)
+	set("active_tab_background", palette.Background)
+	set("inactive_tab_foreground", palette.Muted)
+	set("inactive_tab_background", palette.Surface)
+	set("mark1_background", palette.FindMatch)
+	set("mark1_foreground", palette.Background)
+
+	b.WriteString("\n")
+	for i, c := range p.ANSI() {
+		fmt.Fprintf(&b, "%-24s %s\n", fmt.Sprintf("color%d", i), c.Opaque().Hex())
+	}
+	return []File{{Path: BaseName(v) + ".kitty.conf", Data: []byte(b.String())}}, nil
+}
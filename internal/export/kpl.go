@@ -0,0 +1,110 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "kpl", Description: "Krita palette", Generate: kpl})
+}
+
+// kplColumns is the width of the palette docker grid.
+const kplColumns = 8
+
+// kplSpace names the sRGB profile Krita ships with.
+const kplSpace = "sRGB-elle-V2-srgbtrc.icc"
+
+type kplColorSet struct {
+	XMLName  xml.Name   `xml:"ColorSet"`
+	Version  string     `xml:"version,attr"`
+	Name     string     `xml:"name,attr"`
+	Comment  string     `xml:"comment,attr"`
+	Columns  int        `xml:"columns,attr"`
+	Rows     int        `xml:"rows,attr"`
+	ReadOnly bool       `xml:"readonly,attr"`
+	Entries  []kplEntry `xml:"ColorSetEntry"`
+	Groups   []kplGroup `xml:"Group"`
+}
+
+type kplGroup struct {
+	Name    string     `xml:"name,attr"`
+	Rows    int        `xml:"rows,attr"`
+	Entries []kplEntry `xml:"ColorSetEntry"`
+}
+
+type kplEntry struct {
+	Name     string `xml:"name,attr"`
+	ID       string `xml:"id,attr"`
+	Spot     bool   `xml:"spot,attr"`
+	BitDepth string `xml:"bitdepth,attr"`
+	RGB      struct {
+		R     float64 `xml:"r,attr"`
+		G     float64 `xml:"g,attr"`
+		B     float64 `xml:"b,attr"`
+		Space string  `xml:"space,attr"`
+	} `xml:"RGB"`
+	Position struct {
+		Row    int `xml:"row,attr"`
+		Column int `xml:"column,attr"`
+	} `xml:"Position"`
+}
+
+func kplEntries(g SwatchGroup) ([]kplEntry, int) {
+	var es []kplEntry
+	for i, s := range g.Swatches {
+		e := kplEntry{Name: s.Name, ID: s.Color.Hex(), BitDepth: "U8"}
+		e.RGB.R, e.RGB.G, e.RGB.B = unit(s.Color.R), unit(s.Color.G), unit(s.Color.B)
+		e.RGB.Space = kplSpace
+		e.Position.Row, e.Position.Column = i/kplColumns, i%kplColumns
+		es = append(es, e)
+	}
+	return es, (len(g.Swatches) + kplColumns - 1) / kplColumns
+}
+
+// kpl writes a Krita palette: a zip holding a mimetype entry, which must
+// come first and be stored uncompressed, the colour set and its (empty)
+// list of embedded profiles. The first swatch group is the palette's
+// default group; the others become named groups.
+func kpl(v theme.Variant, p *palette.Palette) ([]File, error) {
+	groups := Swatches(v, p)
+	set := kplColorSet{Version: "2.0", Name: v.Theme.Name, Comment: "Generated by caffeinated export -format kpl from " + v.Label, Columns: kplColumns}
+	set.Entries, set.Rows = kplEntries(groups[0])
+	for _, g := range groups[1:] {
+		es, rows := kplEntries(g)
+		set.Groups = append(set.Groups, kplGroup{Name: g.Name, Rows: rows, Entries: es})
+	}
+	colorset, err := xml.MarshalIndent(set, "", " ")
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	entries := []struct {
+		name   string
+		data   []byte
+		method uint16
+	}{
+		{"mimetype", []byte("application/x-krita-palette"), zip.Store},
+		{"colorset.xml", append([]byte(xml.Header), colorset...), zip.Deflate},
+		{"profiles.xml", []byte(xml.Header + "<Profiles/>\n"), zip.Deflate},
+	}
+	for _, e := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: e.name, Method: e.method})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(e.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return []File{{Path: BaseName(v) + ".kpl", Data: b.Bytes()}}, nil
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "kube", Description: "kubecolor theme, stern colours and template, kube-ps1 colours, in 24-bit and 256 colours", Generate: kube})
+}
+
+// depth is how a config spells colours: 24-bit, or the nearest of xterm's
+// 256 (from the cube and grey ramp, not the 16 the terminal theme sets).
+type depth struct {
+	suffix string
+	// kubecolor, sgr and ps1 format a colour for each tool.
+	kubecolor func(colors.Color) string
+	sgr       func(colors.Color) string
+	ps1       func(colors.Color) string
+}
+
+var depths = []depth{
+	{
+		suffix:    "",
+		kubecolor: func(c colors.Color) string { return "fg=" + strings.ToLower(c.Hex()) },
+		sgr:       func(c colors.Color) string { return fg(c) },
+		ps1:       func(c colors.Color) string { return c.Hex() },
+	},
+	{
+		suffix:    "-256",
+		kubecolor: func(c colors.Color) string { return fmt.Sprintf("fg=%d", colors.Nearest256(c)) },
+		sgr:       func(c colors.Color) string { return fmt.Sprintf("38;5;%d", colors.Nearest256(c)) },
+		ps1:       func(c colors.Color) string { return fmt.Sprint(colors.Nearest256(c)) },
+	},
+}
+
+// kubecolorTheme maps kubecolor's theme keys to roles. Data is coloured as
+// the editor colours YAML: keys as tags, strings and numbers in their
+// syntax roles; statuses take the diagnostic roles.
+var kubecolorTheme = []struct {
+	key  string
+	role palette.Role
+	bold bool
+}{
+	{"base.key", palette.Tag, false},
+	{"base.info", palette.Info, false},
+	{"base.primary", palette.Accent, false},
+	{"base.secondary", palette.Keyword, false},
+	{"base.success", palette.Added, false},
+	{"base.warning", palette.Warning, false},
+	{"base.danger", palette.Error, false},
+	{"base.muted", palette.Muted, false},
+	{"data.key", palette.Tag, false},
+	{"data.string", palette.String, false},
+	{"data.number", palette.Constant, false},
+	{"data.true", palette.Added, false},
+	{"data.false", palette.Deleted, false},
+	{"data.null", palette.Muted, false},
+	{"status.success", palette.Added, false},
+	{"status.warning", palette.Warning, false},
+	{"status.error", palette.Error, false},
+	{"table.header", palette.Foreground, true},
+	{"stderr.error", palette.Error, false},
+}
+
+// sternPods are the roles stern cycles through for pods; containers take
+// the same colours underlined.
+var sternPods = []palette.Role{palette.Accent, palette.Info, palette.Warning, palette.String, palette.Keyword}
+
+// kubeHeader is header for the file of one tool of the kube format.
+func kubeHeader(tool string, v theme.Variant) string {
+	return fmt.Sprintf("# %s for %s.\n# Generated by \"caffeinated export -format kube\"; do not edit.\n", v.Theme.Name, tool)
+}
+
+func kube(v theme.Variant, p *palette.Palette) ([]File, error) {
+	var files []File
+	for _, d := range depths {
+		name := BaseName(v) + d.suffix
+		files = append(files,
+			File{Path: name + ".kubecolor.yaml", Data: []byte(kubecolorConfig(v, p, d))},
+			File{Path: name + ".stern.yaml", Data: []byte(sternConfig(v, p, d))},
+			File{Path: name + ".kube-ps1.sh", Data: []byte(kubePS1(v, p, d))},
+		)
+	}
+	return files, nil
+}
+
+func kubecolorConfig(v theme.Variant, p *palette.Palette, d depth) string {
+	var b strings.Builder
+	b.WriteString(kubeHeader("kubecolor", v))
+	b.WriteString("# Save it as ~/.kube/color.yaml.\n\npreset: dark\ntheme:\n")
+	section := ""
+	for _, t := range kubecolorTheme {
+		sec, key, _ := strings.Cut(t.key, ".")
+		if sec != section {
+			fmt.Fprintf(&b, "  %s:\n", sec)
+			section = sec
+		}
+		value := d.kubecolor(p.Color(t.role))
+		if t.bold {
+			value += ":bold"
+		}
+		// true, false and null would be read as YAML values, not keys.
+		if key == "true" || key == "false" || key == "null" {
+			key = fmt.Sprintf("%q", key)
+		}
+		fmt.Fprintf(&b, "    %s: %q\n", key, value)
+	}
+	return b.String()
+}
+
+func sternConfig(v theme.Variant, p *palette.Palette, d depth) string {
+	var pods, containers []string
+	for _, r := range sternPods {
+		pods = append(pods, d.sgr(p.Color(r)))
+		containers = append(containers, "4;"+d.sgr(p.Color(r)))
+	}
+	// Template strings are Go string literals, so \x1b is an escape.
+	muted := `{{"\x1b[` + d.sgr(p.Color(palette.Muted)) + `m"}}`
+	reset := `{{"\x1b[0m"}}`
+	tmpl := muted + `{{.Namespace}}` + reset + ` {{color .PodColor .PodName}} {{color .ContainerColor .ContainerName}} {{.Message}}{{"\n"}}`
+
+	var b strings.Builder
+	b.WriteString(kubeHeader("stern", v))
+	b.WriteString("# Save it as ~/.config/stern/config.yaml.\n\n")
+	fmt.Fprintf(&b, "pod-colors: %q\n", strings.Join(pods, ","))
+	fmt.Fprintf(&b, "container-colors: %q\n", strings.Join(containers, ","))
+	fmt.Fprintf(&b, "template: '%s'\n", tmpl)
+	return b.String()
+}
+
+func kubePS1(v theme.Variant, p *palette.Palette, d depth) string {
+	var b strings.Builder
+	b.WriteString(kubeHeader("kube-ps1", v))
+	if d.suffix == "" {
+		b.WriteString("# Source it after kube-ps1.sh. Hex colours work in zsh only; bash users\n# want the 256 colour file.\n\n")
+	} else {
+		b.WriteString("# Source it after kube-ps1.sh, in bash or zsh.\n\n")
+	}
+	set := func(name string, r palette.Role) {
+		fmt.Fprintf(&b, "KUBE_PS1_%s_COLOR='%s'\n", name, d.ps1(p.Color(r)))
+	}
+	set("SYMBOL", palette.Info)
+	set("CTX", palette.Keyword)
+	set("NS", palette.Property)
+	// An empty background leaves the prompt's own.
+	b.WriteString("KUBE_PS1_BG_COLOR=\n")
+	return b.String()
+}
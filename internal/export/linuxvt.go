@@ -0,0 +1,172 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "linuxvt", Description: "Linux virtual console palette: setvtrgb file, kernel parameters and an ESC ] P script", Generate: linuxVT})
+}
+
+// The console has no default foreground and background of its own: text is
+// drawn in entry 7 on entry 0, and bold text in the bright entry of its
+// colour. The theme's bright colours repeat the normal ones, which would
+// make bold invisible, so vtPalette pulls each pair apart and keeps the
+// normal colours readable on entry 0.
+const (
+	// vtBrightDelta is the CIEDE2000 difference kept between a normal
+	// colour and its bright entry.
+	vtBrightDelta = 8
+	// vtContrast is the contrast a normal colour keeps against entry 0.
+	vtContrast = 3
+)
+
+// vtAdjustment records a colour changed for the console.
+type vtAdjustment struct {
+	index    int
+	from, to colors.Color
+	why      string
+}
+
+func (a vtAdjustment) String() string {
+	return fmt.Sprintf("%2d %-13s %s -> %s: %s", a.index, palette.ANSINames[a.index], a.from.Hex(), a.to.Hex(), a.why)
+}
+
+// withL returns c at OKLab lightness l, keeping its hue; greys stay
+// neutral.
+func withL(c colors.Color, l float64) colors.Color {
+	if !c.Chromatic() {
+		return colors.Gray(l, 0xff)
+	}
+	o := c.OKLab()
+	o.L = l
+	return colors.FromOKLab(o, 0xff)
+}
+
+// searchL returns the lightness between lo and hi closest to lo at which
+// ok(withL(c, l)) holds, or false if it does not hold even at hi. ok must
+// hold from some point on.
+func searchL(c colors.Color, lo, hi float64, ok func(colors.Color) bool) (colors.Color, bool) {
+	if !ok(withL(c, hi)) {
+		return c, false
+	}
+	for i := 0; i < 32; i++ {
+		mid := (lo + hi) / 2
+		if ok(withL(c, mid)) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return withL(c, hi), true
+}
+
+func vtPalette(ansi [16]colors.Color, bg colors.Color) ([16]colors.Color, []vtAdjustment, error) {
+	var pal [16]colors.Color
+	for i, c := range ansi {
+		pal[i] = c.Over(bg)
+	}
+	var adj []vtAdjustment
+	set := func(i int, c colors.Color, why string) {
+		if c != pal[i] {
+			adj = append(adj, vtAdjustment{i, pal[i], c, why})
+			pal[i] = c
+		}
+	}
+	for i := 1; i < 8; i++ {
+		readable := func(c colors.Color) bool { return colors.Contrast(c, pal[0]) >= vtContrast }
+		if readable(pal[i]) {
+			continue
+		}
+		c, ok := searchL(pal[i], pal[i].OKLab().L, 1, readable)
+		if !ok {
+			return pal, nil, fmt.Errorf("%s cannot be made readable on %s", palette.ANSINames[i], pal[0].Hex())
+		}
+		set(i, c, fmt.Sprintf("contrast %.1f on black", colors.Contrast(c, pal[0])))
+	}
+	for i := 0; i < 8; i++ {
+		normal := pal[i]
+		distinct := func(c colors.Color) bool { return colors.DeltaE2000(c, normal) >= vtBrightDelta }
+		if distinct(pal[i+8]) {
+			continue
+		}
+		why := fmt.Sprintf("distinct from %s", palette.ANSINames[i])
+		if c, ok := searchL(pal[i+8], max(pal[i+8].OKLab().L, normal.OKLab().L), 1, distinct); ok {
+			set(i+8, c, why)
+			continue
+		}
+		// The bright colour is as light as it gets (white): darken the
+		// normal one instead, as far as its contrast allows.
+		if i == 0 {
+			return pal, nil, fmt.Errorf("%s cannot be told from %s", palette.ANSINames[8], palette.ANSINames[0])
+		}
+		bright := withL(pal[i+8], 1)
+		set(i+8, bright, why)
+		c, ok := searchL(normal, normal.OKLab().L, 0, func(c colors.Color) bool {
+			return colors.DeltaE2000(c, bright) >= vtBrightDelta
+		})
+		if !ok || colors.Contrast(c, pal[0]) < vtContrast {
+			return pal, nil, fmt.Errorf("%s and %s cannot be told apart on the console", palette.ANSINames[i], palette.ANSINames[i+8])
+		}
+		set(i, c, fmt.Sprintf("distinct from %s", palette.ANSINames[i+8]))
+	}
+	return pal, adj, nil
+}
+
+func linuxVT(v theme.Variant, p *palette.Palette) ([]File, error) {
+	name := BaseName(v)
+	pal, adj, err := vtPalette(p.ANSI(), p.Color(palette.Background))
+	if err != nil {
+		return nil, err
+	}
+	channel := func(f func(colors.Color) uint8, sep string) string {
+		vs := make([]string, len(pal))
+		for i, c := range pal {
+			vs[i] = strconv.Itoa(int(f(c)))
+		}
+		return strings.Join(vs, sep)
+	}
+	red := func(c colors.Color) uint8 { return c.R }
+	grn := func(c colors.Color) uint8 { return c.G }
+	blu := func(c colors.Color) uint8 { return c.B }
+
+	// setvtrgb takes no comments: three lines of 16 decimal values.
+	vtrgb := channel(red, ",") + "\n" + channel(grn, ",") + "\n" + channel(blu, ",") + "\n"
+	cmdline := fmt.Sprintf("vt.default_red=%s vt.default_grn=%s vt.default_blu=%s\n", channel(red, ","), channel(grn, ","), channel(blu, ","))
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString(header("#", "linuxvt", v))
+	fmt.Fprintf(&b, `#
+# Sets the palette of the Linux virtual console with ESC ] P. Run it or
+# source it from your shell profile; outside the console (TERM=linux) it
+# does nothing, and printf '\033]R' restores the kernel palette.
+#
+# To have the palette from boot, load %[1]s.vtrgb with setvtrgb
+# from a boot service, or add the line in %[1]s.cmdline to the
+# kernel command line.
+`, name)
+	if len(adj) > 0 {
+		b.WriteString("#\n# Adjusted for the console, where bold text takes the bright colour:\n")
+		for _, a := range adj {
+			fmt.Fprintf(&b, "#   %s\n", a)
+		}
+	}
+	b.WriteString("\nif [ \"$TERM\" = linux ]; then\n")
+	for i, c := range pal {
+		fmt.Fprintf(&b, "\tprintf '\\033]P%X%02x%02x%02x'\n", i, c.R, c.G, c.B)
+	}
+	b.WriteString("\t# Repaint the screen in the new background.\n\tprintf '\\033[H\\033[2J'\nfi\n")
+
+	return []File{
+		{Path: "linuxvt/" + name + ".vtrgb", Data: []byte(vtrgb)},
+		{Path: "linuxvt/" + name + ".cmdline", Data: []byte(cmdline)},
+		{Path: "linuxvt/" + name + ".sh", Data: []byte(b.String()), Mode: 0o755},
+	}, nil
+}
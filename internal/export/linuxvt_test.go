@@ -0,0 +1,88 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func TestLinuxVT(t *testing.T) {
+	vs, err := theme.LoadVariants("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vs {
+		p, err := palette.New(v.Theme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pal, _, err := vtPalette(p.ANSI(), p.Color(palette.Background))
+		if err != nil {
+			t.Fatalf("%s: %v", v.ID, err)
+		}
+		files, err := Run("linuxvt", v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var vtrgb *File
+		for i, f := range files {
+			if strings.HasSuffix(f.Path, ".vtrgb") {
+				vtrgb = &files[i]
+			}
+		}
+		if vtrgb == nil {
+			t.Fatalf("%s: no .vtrgb file", v.ID)
+		}
+		if err := checkVTRGB(string(vtrgb.Data), pal); err != nil {
+			t.Errorf("%s: %v", v.ID, err)
+		}
+	}
+}
+
+// checkVTRGB reads a setvtrgb file back, as setvtrgb does, and checks it
+// against pal and the console rules.
+func checkVTRGB(data string, pal [16]colors.Color) error {
+	lines := strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+	if len(lines) != 3 {
+		return fmt.Errorf("vtrgb: %d lines, want 3", len(lines))
+	}
+	var got [16]colors.Color
+	for ch, line := range lines {
+		fields := strings.Split(line, ",")
+		if len(fields) != 16 {
+			return fmt.Errorf("vtrgb: line %d has %d values, want 16", ch+1, len(fields))
+		}
+		for i, f := range fields {
+			n, err := strconv.ParseUint(f, 10, 8)
+			if err != nil {
+				return fmt.Errorf("vtrgb: line %d: %w", ch+1, err)
+			}
+			switch ch {
+			case 0:
+				got[i].R = uint8(n)
+			case 1:
+				got[i].G = uint8(n)
+			case 2:
+				got[i].B = uint8(n)
+			}
+			got[i].A = 0xff
+		}
+	}
+	if got != pal {
+		return fmt.Errorf("vtrgb: does not read back as the palette")
+	}
+	for i := 0; i < 8; i++ {
+		if d := colors.DeltaE2000(got[i], got[i+8]); d < vtBrightDelta-0.01 {
+			return fmt.Errorf("vtrgb: %s and %s differ by ΔE2000 %.1f", palette.ANSINames[i], palette.ANSINames[i+8], d)
+		}
+		if c := colors.Contrast(got[i], got[0]); i > 0 && c < vtContrast-0.01 {
+			return fmt.Errorf("vtrgb: %s has contrast %.1f on %s", palette.ANSINames[i], c, palette.ANSINames[0])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "lnav", Description: "lnav theme with log levels in the diagnostic colours and JSON coloured as in jq", Generate: lnav})
+}
+
+// lnavStyle is a style in an lnav theme. Colours are "$var" references to
+// the theme's vars.
+type lnavStyle struct {
+	Color      string `json:"color,omitempty"`
+	Background string `json:"background-color,omitempty"`
+	Bold       bool   `json:"bold,omitempty"`
+	Underline  bool   `json:"underline,omitempty"`
+}
+
+type lnavHighlight struct {
+	Pattern string    `json:"pattern"`
+	Style   lnavStyle `json:"style"`
+}
+
+type lnavTheme struct {
+	Vars       map[string]string        `json:"vars"`
+	Styles     map[string]lnavStyle     `json:"styles"`
+	Syntax     map[string]lnavStyle     `json:"syntax-styles"`
+	Status     map[string]lnavStyle     `json:"status-styles"`
+	LogLevels  map[string]lnavStyle     `json:"log-level-styles"`
+	Highlights map[string]lnavHighlight `json:"highlights"`
+}
+
+// lnavRoles are the palette roles the theme defines as vars.
+var lnavRoles = []palette.Role{
+	palette.Background, palette.Surface, palette.Border, palette.Foreground, palette.Muted,
+	palette.Accent, palette.Selection, palette.Error, palette.Warning, palette.Info, palette.FindMatch,
+	palette.Added, palette.Modified, palette.Deleted,
+	palette.Comment, palette.String, palette.Keyword, palette.Function, palette.Type,
+	palette.Constant, palette.Variable, palette.Property, palette.Tag, palette.Punctuation,
+}
+
+// lnavVar is the var name of a role: lnav takes letters, digits, - and _.
+func lnavVar(r palette.Role) string {
+	var b strings.Builder
+	for _, c := range string(r) {
+		if c >= 'A' && c <= 'Z' {
+			b.WriteByte('-')
+			c += 'a' - 'A'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func lnav(v theme.Variant, p *palette.Palette) ([]File, error) {
+	name := BaseName(v)
+	t := lnavTheme{
+		Vars:       map[string]string{},
+		Styles:     map[string]lnavStyle{},
+		Syntax:     map[string]lnavStyle{},
+		Status:     map[string]lnavStyle{},
+		LogLevels:  map[string]lnavStyle{},
+		Highlights: map[string]lnavHighlight{},
+	}
+	bg := p.Color(palette.Background)
+	for _, r := range lnavRoles {
+		// lnav has no alpha: translucent roles are laid over the background.
+		t.Vars[lnavVar(r)] = p.Color(r).Over(bg).Opaque().Hex()
+	}
+	ref := func(r palette.Role) string { return "$" + lnavVar(r) }
+	on := func(fg, bg palette.Role) lnavStyle { return lnavStyle{Color: ref(fg), Background: ref(bg)} }
+	fg := func(r palette.Role) lnavStyle { return lnavStyle{Color: ref(r)} }
+
+	t.Styles["text"] = on(palette.Foreground, palette.Background)
+	t.Styles["alt-text"] = on(palette.Foreground, palette.Surface)
+	t.Styles["identifier"] = fg(palette.Accent)
+	t.Styles["hidden"] = fg(palette.Muted)
+	t.Styles["ok"] = lnavStyle{Color: ref(palette.Added), Bold: true}
+	t.Styles["info"] = fg(palette.Info)
+	t.Styles["warning"] = fg(palette.Warning)
+	t.Styles["error"] = fg(palette.Error)
+	t.Styles["cursor-line"] = lnavStyle{Color: ref(palette.Foreground), Background: ref(palette.Selection), Bold: true}
+	t.Styles["selected-text"] = lnavStyle{Background: ref(palette.Selection)}
+	t.Styles["adjusted-time"] = fg(palette.Modified)
+	t.Styles["skewed-time"] = lnavStyle{Color: ref(palette.Warning), Underline: true}
+	t.Styles["offset-time"] = fg(palette.Muted)
+	t.Styles["invalid-msg"] = lnavStyle{Color: ref(palette.Error), Underline: true}
+	t.Styles["popup"] = on(palette.Foreground, palette.Surface)
+	t.Styles["focused"] = on(palette.Foreground, palette.Selection)
+	t.Styles["scrollbar"] = on(palette.Background, palette.Border)
+	t.Styles["h1"] = lnavStyle{Color: ref(palette.Keyword), Bold: true}
+	t.Styles["h2"] = lnavStyle{Color: ref(palette.Keyword), Underline: true}
+	t.Styles["h3"] = fg(palette.Keyword)
+	t.Styles["hr"] = fg(palette.Border)
+	t.Styles["hyperlink"] = lnavStyle{Color: ref(palette.Accent), Underline: true}
+	t.Styles["list-glyph"] = fg(palette.Punctuation)
+	t.Styles["breadcrumb"] = fg(palette.Muted)
+	t.Styles["table-border"] = fg(palette.Border)
+	t.Styles["table-header"] = lnavStyle{Color: ref(palette.Foreground), Bold: true}
+	t.Styles["quote-border"] = fg(palette.Border)
+	t.Styles["quoted-text"] = fg(palette.Comment)
+
+	// JSON in log messages takes the colours jq gets, read from the same
+	// JSON scopes into json-* vars; lnav styles have no italic, so only the
+	// colour is kept.
+	for _, s := range []struct{ style, field string }{
+		{"string", "strings"},
+		{"number", "numbers"},
+		{"null", "null"},
+		{"symbol", "object keys"},
+	} {
+		c, err := jsonTokenColor(v.Theme, s.field)
+		if err != nil {
+			return nil, err
+		}
+		name := "json-" + strings.ReplaceAll(s.field, " ", "-")
+		t.Vars[name] = c.Opaque().Hex()
+		t.Syntax[s.style] = lnavStyle{Color: "$" + name}
+	}
+	t.Syntax["keyword"] = fg(palette.Keyword)
+	t.Syntax["comment"] = fg(palette.Comment)
+	t.Syntax["doc-directive"] = fg(palette.Keyword)
+	t.Syntax["variable"] = fg(palette.Variable)
+	t.Syntax["type"] = fg(palette.Type)
+	t.Syntax["function"] = fg(palette.Function)
+	t.Syntax["separators-references-accessors"] = fg(palette.Punctuation)
+	t.Syntax["re-special"] = fg(palette.Keyword)
+	t.Syntax["re-repeat"] = fg(palette.Constant)
+	t.Syntax["diff-delete"] = fg(palette.Deleted)
+	t.Syntax["diff-add"] = fg(palette.Added)
+	t.Syntax["diff-section"] = fg(palette.Info)
+	t.Syntax["file"] = fg(palette.Accent)
+
+	t.Status["text"] = on(palette.Foreground, palette.Surface)
+	t.Status["title"] = lnavStyle{Color: ref(palette.Background), Background: ref(palette.Accent), Bold: true}
+	t.Status["subtitle"] = on(palette.Foreground, palette.Border)
+	t.Status["info"] = fg(palette.Info)
+	t.Status["warn"] = fg(palette.Warning)
+	t.Status["alert"] = fg(palette.Error)
+	t.Status["active"] = fg(palette.Added)
+	t.Status["inactive"] = fg(palette.Muted)
+	t.Status["hotkey"] = lnavStyle{Color: ref(palette.Accent), Underline: true}
+	t.Status["title-hotkey"] = lnavStyle{Color: ref(palette.Background), Background: ref(palette.Accent), Underline: true}
+	t.Status["disabled-title"] = on(palette.Muted, palette.Surface)
+
+	// Log levels follow the diagnostics: debug and trace are muted, the
+	// rest take the squiggle colours, brightest for the worst.
+	for _, l := range []string{"trace", "debug5", "debug4", "debug3", "debug2", "debug"} {
+		t.LogLevels[l] = fg(palette.Muted)
+	}
+	t.LogLevels["info"] = fg(palette.Info)
+	t.LogLevels["stats"] = fg(palette.Info)
+	t.LogLevels["notice"] = fg(palette.Info)
+	t.LogLevels["warning"] = fg(palette.Warning)
+	t.LogLevels["error"] = fg(palette.Error)
+	t.LogLevels["critical"] = lnavStyle{Color: ref(palette.Error), Bold: true}
+	t.LogLevels["fatal"] = lnavStyle{Color: ref(palette.Background), Background: ref(palette.Error), Bold: true}
+	t.LogLevels["invalid"] = lnavStyle{Color: ref(palette.Error), Underline: true}
+
+	// Highlights are marked like find matches in the editor.
+	match := on(palette.Background, palette.FindMatch)
+	t.Highlights["ipv4"] = lnavHighlight{Pattern: `\b(?<!\d\.)\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b(?!\.\d)`, Style: match}
+	t.Highlights["uuid"] = lnavHighlight{Pattern: `\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`, Style: match}
+
+	config := map[string]any{
+		"$schema": "https://lnav.org/schemas/config-v1.schema.json",
+		"ui": map[string]any{
+			"theme-defs": map[string]lnavTheme{name: t},
+		},
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(config); err != nil {
+		return nil, err
+	}
+	return []File{{Path: "lnav/" + name + ".json", Data: buf.Bytes()}}, nil
+}
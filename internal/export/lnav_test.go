@@ -0,0 +1,67 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func TestLnav(t *testing.T) {
+	vs, err := theme.LoadVariants("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vs {
+		files, err := Run("lnav", v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(files) != 1 {
+			t.Fatalf("%s: %d files, want 1", v.ID, len(files))
+		}
+		if err := checkLnav(files[0].Data, BaseName(v)); err != nil {
+			t.Errorf("%s: %v", v.ID, err)
+		}
+	}
+}
+
+var (
+	lnavRef = regexp.MustCompile(`: "\$([a-z0-9_-]+)"`)
+	lnavHex = regexp.MustCompile(`^#[0-9A-F]{6}$`)
+)
+
+// checkLnav decodes the theme as lnav would find it and checks that every
+// var its styles refer to is defined with a colour.
+func checkLnav(data []byte, name string) error {
+	var config struct {
+		UI struct {
+			ThemeDefs map[string]lnavTheme `json:"theme-defs"`
+		} `json:"ui"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return err
+	}
+	t, ok := config.UI.ThemeDefs[name]
+	if !ok {
+		return fmt.Errorf("no theme %q", name)
+	}
+	for k, v := range t.Vars {
+		if !lnavHex.MatchString(v) {
+			return fmt.Errorf("var %s: %q is not a colour", k, v)
+		}
+	}
+	for _, m := range lnavRef.FindAllSubmatch(data, -1) {
+		if _, ok := t.Vars[string(m[1])]; !ok {
+			return fmt.Errorf("$%s is not defined", m[1])
+		}
+	}
+	for _, l := range []string{"error", "warning", "info"} {
+		if t.LogLevels[l].Color == "" {
+			return fmt.Errorf("log level %s has no colour", l)
+		}
+	}
+	return nil
+}
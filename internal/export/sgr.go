@@ -0,0 +1,19 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+)
+
+// fg returns the 24-bit SGR parameters selecting c as the foreground,
+// preceded by any extra attributes such as "1" for bold.
+func fg(c colors.Color, attrs ...string) string {
+	return strings.Join(append(attrs, fmt.Sprintf("38;2;%d;%d;%d", c.R, c.G, c.B)), ";")
+}
+
+// bg returns the 24-bit SGR parameters selecting c as the background.
+func bg(c colors.Color) string {
+	return fmt.Sprintf("48;2;%d;%d;%d", c.R, c.G, c.B)
+}
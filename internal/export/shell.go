@@ -0,0 +1,50 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "shell", Description: "POSIX shell snippet setting LS_COLORS, GREP_COLORS and less colours", Generate: shell})
+}
+
+func shell(v theme.Variant, p *palette.Palette) ([]File, error) {
+	name := BaseName(v)
+	var b strings.Builder
+	b.WriteString(header("#", "shell", v))
+	b.WriteString("# Source it from ~/.bashrc or ~/.zshrc.\n\n")
+	fmt.Fprintf(&b, "CAFFEINATED_RUST_CONFIG=\"${CAFFEINATED_RUST_CONFIG:-$HOME/%s}\"\n\n", ConfigDir)
+
+	fmt.Fprintf(&b, "if command -v dircolors >/dev/null 2>&1 && [ -r \"$CAFFEINATED_RUST_CONFIG/%s.dircolors\" ]; then\n", name)
+	fmt.Fprintf(&b, "\teval \"$(dircolors -b \"$CAFFEINATED_RUST_CONFIG/%s.dircolors\")\"\n", name)
+	b.WriteString("fi\n\n")
+
+	grep := []string{
+		"mt=" + fg(p.Color(palette.Background)) + ";" + bg(p.Color(palette.FindMatch)),
+		"fn=" + fg(p.Color(palette.Property)),
+		"ln=" + fg(p.Color(palette.Muted)),
+		"bn=" + fg(p.Color(palette.Muted)),
+		"se=" + fg(p.Color(palette.Muted)),
+	}
+	fmt.Fprintf(&b, "export GREP_COLORS='%s'\n\n", strings.Join(grep, ":"))
+
+	// Colours for man pages shown through less.
+	termcap := []struct {
+		name, sgr string
+	}{
+		{"md", fg(p.Color(palette.Keyword), "1")},
+		{"me", "0"},
+		{"us", fg(p.Color(palette.Function), "4")},
+		{"ue", "0"},
+		{"so", fg(p.Color(palette.Foreground)) + ";" + bg(p.Color(palette.Selection))},
+		{"se", "0"},
+	}
+	for _, t := range termcap {
+		fmt.Fprintf(&b, "export LESS_TERMCAP_%s=\"$(printf '\\033[%sm')\"\n", t.name, t.sgr)
+	}
+	return []File{{Path: name + ".sh", Data: []byte(b.String())}}, nil
+}
@@ -0,0 +1,128 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// The designer formats (ase, gpl, kpl, sketch) share one swatch list: the
+// palette roles grouped by kind, then the 16 terminal colours. Names are
+// "<theme>/<group>/<role>", which Figma and Sketch show as nested folders
+// and which keep the swatches of several variants apart in one library.
+
+func init() {
+	Register(Exporter{Name: "gpl", Description: "GIMP and Inkscape palette", Generate: gpl})
+	Register(Exporter{Name: "sketch", Description: "Sketch palette JSON, also read by Figma palette plugins", Generate: sketch})
+}
+
+// Swatch is a named opaque colour.
+type Swatch struct {
+	Name  string
+	Color colors.Color
+}
+
+// SwatchGroup is a named list of swatches.
+type SwatchGroup struct {
+	Name     string
+	Swatches []Swatch
+}
+
+var swatchKinds = []struct {
+	kind palette.Kind
+	name string
+}{
+	{palette.UI, "UI"},
+	{palette.Diagnostic, "Diagnostics"},
+	{palette.Git, "Git"},
+	{palette.Syntax, "Syntax"},
+}
+
+// Swatches lists the swatch groups of a variant. Translucent roles are
+// flattened onto the editor background, since design tools would show
+// them over white.
+func Swatches(v theme.Variant, p *palette.Palette) []SwatchGroup {
+	bg := p.Color(palette.Background)
+	var groups []SwatchGroup
+	for _, k := range swatchKinds {
+		g := SwatchGroup{Name: v.Theme.Name + "/" + k.name}
+		for _, d := range palette.Definitions {
+			if d.Kind == k.kind {
+				g.Swatches = append(g.Swatches, Swatch{Name: g.Name + "/" + string(d.Role), Color: p.Color(d.Role).Over(bg)})
+			}
+		}
+		groups = append(groups, g)
+	}
+	term := SwatchGroup{Name: v.Theme.Name + "/Terminal"}
+	for i, c := range p.ANSI() {
+		term.Swatches = append(term.Swatches, Swatch{Name: term.Name + "/" + ansiName(i), Color: c.Over(bg)})
+	}
+	return append(groups, term)
+}
+
+// ansiName turns "BrightBlack" into "bright black".
+func ansiName(i int) string {
+	name := palette.ANSINames[i]
+	if rest, ok := strings.CutPrefix(name, "Bright"); ok {
+		return "bright " + strings.ToLower(rest)
+	}
+	return strings.ToLower(name)
+}
+
+// unit returns a channel as a fraction, rounded to 6 places so that the
+// text formats stay readable.
+func unit(v uint8) float64 {
+	return math.Round(float64(v)/255*1e6) / 1e6
+}
+
+func gpl(v theme.Variant, p *palette.Palette) ([]File, error) {
+	var b strings.Builder
+	b.WriteString("GIMP Palette\n")
+	fmt.Fprintf(&b, "Name: %s\n", v.Theme.Name)
+	b.WriteString("Columns: 8\n")
+	b.WriteString(header("#", "gpl", v))
+	for _, g := range Swatches(v, p) {
+		fmt.Fprintf(&b, "# %s\n", g.Name)
+		for _, s := range g.Swatches {
+			fmt.Fprintf(&b, "%3d %3d %3d\t%s\n", s.Color.R, s.Color.G, s.Color.B, s.Name)
+		}
+	}
+	return []File{{Path: BaseName(v) + ".gpl", Data: []byte(b.String())}}, nil
+}
+
+// sketchColor is a colour of a Sketch palette. Hex is not part of Sketch's
+// format, which ignores it; Figma palette importers read it instead.
+type sketchColor struct {
+	Name  string  `json:"name"`
+	Red   float64 `json:"red"`
+	Green float64 `json:"green"`
+	Blue  float64 `json:"blue"`
+	Alpha float64 `json:"alpha"`
+	Hex   string  `json:"hex"`
+}
+
+func sketch(v theme.Variant, p *palette.Palette) ([]File, error) {
+	doc := struct {
+		CompatibleVersion string        `json:"compatibleVersion"`
+		PluginVersion     string        `json:"pluginVersion"`
+		Name              string        `json:"name"`
+		Colors            []sketchColor `json:"colors"`
+	}{CompatibleVersion: "2.0", PluginVersion: "2.22", Name: v.Theme.Name}
+	for _, g := range Swatches(v, p) {
+		for _, s := range g.Swatches {
+			doc.Colors = append(doc.Colors, sketchColor{
+				Name: s.Name, Red: unit(s.Color.R), Green: unit(s.Color.G), Blue: unit(s.Color.B), Alpha: 1, Hex: s.Color.Hex(),
+			})
+		}
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []File{{Path: BaseName(v) + ".sketchpalette", Data: append(data, '\n')}}, nil
+}
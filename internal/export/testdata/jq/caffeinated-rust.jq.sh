@@ -0,0 +1,12 @@
+# Caffeinated Rust for jq.
+# Generated by "caffeinated export -format jq"; do not edit.
+# Source it from ~/.bashrc or ~/.zshrc. The fields, in jq's order, are
+# null, false, true, numbers, strings, arrays, objects, object keys.
+#
+# The python yq (kislyuk/yq) prints through jq and takes these colours too;
+# for mikefarah/yq, source caffeinated-rust.yq.sh, and for fx, caffeinated-rust.fx.sh.
+
+export JQ_COLORS='38;5;75:38;5;75:38;5;75:38;5;75:3;38;5;216:38;5;255:38;5;255:38;5;75'
+
+# For jq releases that take 24-bit colours:
+# export JQ_COLORS='38;2;112;175;255:38;2;112;175;255:38;2;112;175;255:38;2;112;175;255:3;38;2;247;160;114:38;2;237;237;237:38;2;237;237;237:38;2;112;175;255'
@@ -0,0 +1,32 @@
+# Caffeinated Rust for jq.
+# Generated by "caffeinated export -format jq"; do not edit.
+# Source it from ~/.bashrc or ~/.zshrc; it needs bash or zsh, whose
+# PIPESTATUS and pipestatus give it yq's exit status. mikefarah/yq has fixed
+# colours; this yq function recolours them in 24-bit colour:
+#   numbers and booleans  constant.numeric.integer.yaml
+#   keys                  entity.name.tag.yaml
+#   anchors and aliases   variable.other.alias.yaml
+#   strings               string.unquoted.plain.out.yaml
+#   comments              comment.line.number-sign.yaml
+
+yq() {
+	case " $* " in
+	*" -i "* | *" --inplace "* | *" --inplace="* | *" -M "* | *" --no-colors "*)
+		command yq "$@"
+		return
+		;;
+	esac
+	if [ -n "${NO_COLOR-}" ] || [ ! -t 1 ]; then
+		command yq "$@"
+		return
+	fi
+	local e
+	e=$(printf '\033')
+	command yq -C "$@" | sed \
+		-e "s/${e}\[95m/${e}[38;2;112;175;255m/g" \
+		-e "s/${e}\[36m/${e}[38;2;118;199;165m/g" \
+		-e "s/${e}\[93m/${e}[38;2;237;237;237m/g" \
+		-e "s/${e}\[32m/${e}[38;2;237;237;237m/g" \
+		-e "s/${e}\[90m/${e}[3;38;2;108;108;108m/g"
+	return "${PIPESTATUS[0]:-${pipestatus[1]}}"
+}
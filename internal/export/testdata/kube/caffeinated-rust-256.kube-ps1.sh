@@ -0,0 +1,8 @@
+# Caffeinated Rust for kube-ps1.
+# Generated by "caffeinated export -format kube"; do not edit.
+# Source it after kube-ps1.sh, in bash or zsh.
+
+KUBE_PS1_SYMBOL_COLOR='75'
+KUBE_PS1_CTX_COLOR='160'
+KUBE_PS1_NS_COLOR='115'
+KUBE_PS1_BG_COLOR=
@@ -0,0 +1,9 @@
+# Caffeinated Rust for kube-ps1.
+# Generated by "caffeinated export -format kube"; do not edit.
+# Source it after kube-ps1.sh. Hex colours work in zsh only; bash users
+# want the 256 colour file.
+
+KUBE_PS1_SYMBOL_COLOR='#70AFFF'
+KUBE_PS1_CTX_COLOR='#B7410E'
+KUBE_PS1_NS_COLOR='#76C7A5'
+KUBE_PS1_BG_COLOR=
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "tmux", Description: "tmux status line, pane and mode styles", Generate: tmux})
+}
+
+func tmux(v theme.Variant, p *palette.Palette) ([]File, error) {
+	hex := p.Hex
+	var b strings.Builder
+	b.WriteString(header("#", "tmux", v))
+	set := func(option, value string) {
+		fmt.Fprintf(&b, "set -g %s %q\n", option, value)
+	}
+	b.WriteString("\n")
+	set("status-style", "bg="+hex(palette.Background)+",fg="+hex(palette.Foreground))
+	set("status-left-style", "bg="+hex(palette.Accent)+",fg="+hex(palette.Background)+",bold")
+	set("status-right-style", "fg="+hex(palette.Muted))
+	set("window-status-style", "fg="+hex(palette.Muted))
+	set("window-status-current-style", "fg="+hex(palette.Accent)+",bold")
+	set("window-status-activity-style", "fg="+hex(palette.Warning))
+	set("window-status-bell-style", "fg="+hex(palette.Error)+",bold")
+	set("pane-border-style", "fg="+hex(palette.Border))
+	set("pane-active-border-style", "fg="+hex(palette.Accent))
+	set("message-style", "bg="+hex(palette.Surface)+",fg="+hex(palette.Foreground))
+	set("message-command-style", "bg="+hex(palette.Surface)+",fg="+hex(palette.Accent))
+	set("mode-style", "bg="+hex(palette.Selection)+",fg="+hex(palette.Foreground))
+	set("copy-mode-match-style", "bg="+hex(palette.FindMatch)+",fg="+hex(palette.Background))
+	set("copy-mode-current-match-style", "bg="+hex(palette.String)+",fg="+hex(palette.Background))
+	set("clock-mode-colour", hex(palette.Accent))
+	set("display-panes-colour", hex(palette.Muted))
+	set("display-panes-active-colour", hex(palette.Accent))
+	return []File{{Path: BaseName(v) + ".tmux.conf", Data: []byte(b.String())}}, nil
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func init() {
+	Register(Exporter{Name: "userstyles", Description: "Stylus userstyles for GitHub and GitLab code and diff views", Generate: userstyles})
+}
+
+// userstyleVersion is the @version of the generated styles, which Stylus
+// compares when checking for updates. Bump it when the mapping changes.
+const userstyleVersion = "0.1.0"
+
+// userstylesJSON maps the classes of each site's code and diff views to a
+// scope stack, matched against tokenColors like a token in that position,
+// or to a palette role. The sites highlight in their own way for every
+// language; the stacks are Go's because Go is what we review.
+//
+//go:embed userstyles.json
+var userstylesJSON []byte
+
+type userstyleSite struct {
+	ID      string          `json:"id"`
+	Name    string          `json:"name"`
+	Domains []string        `json:"domains"`
+	Rules   []userstyleRule `json:"rules"`
+}
+
+// userstyleRule sets the colour of Selector from a scope stack or a role,
+// and its background from a role at the given alpha (opaque when 0).
+// Variables are custom properties the site's own CSS reads, set to the
+// same colour, so that views the selectors miss follow too.
+type userstyleRule struct {
+	Selector   string       `json:"selector"`
+	Scope      string       `json:"scope"`
+	Role       palette.Role `json:"role"`
+	Background palette.Role `json:"background"`
+	Alpha      float64      `json:"alpha"`
+	Variables  []string     `json:"variables"`
+}
+
+func loadUserstyles() ([]userstyleSite, error) {
+	var doc struct {
+		Sites []userstyleSite `json:"sites"`
+	}
+	if err := json.Unmarshal(userstylesJSON, &doc); err != nil {
+		return nil, fmt.Errorf("userstyles.json: %w", err)
+	}
+	for _, s := range doc.Sites {
+		for _, r := range s.Rules {
+			for _, role := range []palette.Role{r.Role, r.Background} {
+				if _, ok := palette.Lookup(role); role != "" && !ok {
+					return nil, fmt.Errorf("userstyles.json: %s %s: unknown role %q", s.ID, r.Selector, role)
+				}
+			}
+			if r.Scope != "" && r.Role != "" {
+				return nil, fmt.Errorf("userstyles.json: %s %s: set scope or role, not both", s.ID, r.Selector)
+			}
+		}
+	}
+	return doc.Sites, nil
+}
+
+// declaration is one resolved CSS property.
+type declaration struct{ property, value string }
+
+func (r userstyleRule) resolve(t *theme.Theme, p *palette.Palette) []declaration {
+	var ds []declaration
+	switch {
+	case r.Scope != "":
+		st := t.Match(strings.Fields(r.Scope)...)
+		ds = append(ds, declaration{"color", strings.ToUpper(st.Foreground.Value)})
+		ds = append(ds, fontStyle(st.FontStyle.Value)...)
+	case r.Role != "":
+		ds = append(ds, declaration{"color", p.Hex(r.Role)})
+		ds = append(ds, fontStyle(p.FontStyle(r.Role))...)
+	}
+	if r.Background != "" {
+		c := p.Color(r.Background)
+		if r.Alpha > 0 {
+			c = c.WithAlpha(uint8(math.Round(r.Alpha * 255)))
+		}
+		ds = append(ds, declaration{"background-color", c.Hex()})
+	}
+	return ds
+}
+
+func fontStyle(s string) []declaration {
+	var ds []declaration
+	for _, w := range strings.Fields(s) {
+		switch w {
+		case "italic":
+			ds = append(ds, declaration{"font-style", "italic"})
+		case "bold":
+			ds = append(ds, declaration{"font-weight", "bold"})
+		case "underline":
+			ds = append(ds, declaration{"text-decoration", "underline"})
+		}
+	}
+	return ds
+}
+
+func userstyles(v theme.Variant, p *palette.Palette) ([]File, error) {
+	sites, err := loadUserstyles()
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, s := range sites {
+		files = append(files, File{Path: fmt.Sprintf("userstyles/%s-%s.user.css", BaseName(v), s.ID), Data: userstyle(s, v, p)})
+	}
+	return files, nil
+}
+
+func userstyle(s userstyleSite, v theme.Variant, p *palette.Palette) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `/* ==UserStyle==
+@name         %[1]s for %[2]s
+@namespace    github.com/caffeinated-minds/caffeinated-rust
+@version      %[3]s
+@description  %[1]s colours for %[2]s code, blob and diff views.
+@author       Caffeinated Minds
+@license      MIT
+==/UserStyle== */
+/* Generated by "caffeinated export -format userstyles"; do not edit. */
+
+`, v.Theme.Name, s.Name, userstyleVersion)
+
+	domains := make([]string, len(s.Domains))
+	for i, d := range s.Domains {
+		domains[i] = fmt.Sprintf("domain(%q)", d)
+	}
+	fmt.Fprintf(&b, "@-moz-document %s {\n", strings.Join(domains, ", "))
+	var vars []declaration
+	for _, r := range s.Rules {
+		ds := r.resolve(v.Theme, p)
+		for _, name := range r.Variables {
+			// Background variables take the background, the others the
+			// colour.
+			want := "color"
+			if lower := strings.ToLower(name); strings.Contains(lower, "bg") || strings.Contains(lower, "background") {
+				want = "background-color"
+			}
+			for _, d := range ds {
+				if d.property == want {
+					vars = append(vars, declaration{name, d.value})
+				}
+			}
+		}
+	}
+	if len(vars) > 0 {
+		b.WriteString("  :root {\n")
+		for _, d := range vars {
+			fmt.Fprintf(&b, "    %s: %s !important;\n", d.property, d.value)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, r := range s.Rules {
+		b.WriteString("\n")
+		selectors := strings.Split(r.Selector, ",")
+		for i, sel := range selectors {
+			sep := ","
+			if i == len(selectors)-1 {
+				sep = " {"
+			}
+			fmt.Fprintf(&b, "  %s%s\n", strings.TrimSpace(sel), sep)
+		}
+		for _, d := range r.resolve(v.Theme, p) {
+			fmt.Fprintf(&b, "    %s: %s !important;\n", d.property, d.value)
+		}
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
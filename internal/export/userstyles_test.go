@@ -0,0 +1,315 @@
+package export
+
+import (
+	"embed"
+	"fmt"
+	"html"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// userstyleFixtures are trimmed copies of the sites' markup whose elements
+// say which role they must end up in.
+//
+//go:embed testdata/pages/*.html
+var userstyleFixtures embed.FS
+
+func TestUserstyles(t *testing.T) {
+	sites, err := loadUserstyles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vs, err := theme.LoadVariants("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vs {
+		p, err := palette.New(v.Theme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range sites {
+			if err := checkUserstyle(s.ID, userstyle(s, v, p), p); err != nil {
+				t.Errorf("%s: %v", v.ID, err)
+			}
+		}
+	}
+}
+
+func TestUserstyleCheckFindsRenamedClass(t *testing.T) {
+	sites, err := loadUserstyles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := defaultVariant(t)
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	css := strings.Replace(string(userstyle(sites[0], v, p)), ".pl-k", ".pl-keyword", 1)
+	err = checkUserstyle(sites[0].ID, []byte(css), p)
+	if err == nil || !strings.Contains(err.Error(), ".pl-keyword matches nothing") {
+		t.Errorf("check of a renamed class = %v", err)
+	}
+}
+
+var (
+	cssComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssRule    = regexp.MustCompile(`([^{}]+)\{([^{}]*)\}`)
+	htmlTag    = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	htmlAttr   = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)\s*=\s*"([^"]*)"`)
+	htmlNote   = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+type cssSelector struct {
+	// compounds are the descendant parts, outermost first; each is a tag
+	// (or "") and classes.
+	compounds []cssCompound
+	text      string
+}
+
+type cssCompound struct {
+	tag     string
+	classes []string
+}
+
+type styleDecl struct {
+	selector cssSelector
+	property string
+	value    string
+	// specificity is classes*100 + tags; order is the source position.
+	specificity, order int
+}
+
+func parseCSS(css []byte) ([]styleDecl, []cssSelector, error) {
+	var decls []styleDecl
+	var sels []cssSelector
+	order := 0
+	for _, m := range cssRule.FindAllSubmatch(cssComment.ReplaceAll(css, nil), -1) {
+		head := strings.TrimSpace(string(m[1]))
+		if i := strings.LastIndex(head, "{"); i >= 0 {
+			head = head[i+1:]
+		}
+		for _, text := range strings.Split(head, ",") {
+			text = strings.TrimSpace(text)
+			if strings.ContainsAny(text, ":[>+~#") {
+				// :root and attribute selectors only set variables.
+				continue
+			}
+			sel := cssSelector{text: text}
+			spec := 0
+			for _, part := range strings.Fields(text) {
+				classes := strings.Split(part, ".")
+				c := cssCompound{tag: classes[0], classes: classes[1:]}
+				spec += 100 * len(c.classes)
+				if c.tag != "" {
+					spec++
+				}
+				sel.compounds = append(sel.compounds, c)
+			}
+			sels = append(sels, sel)
+			for _, d := range strings.Split(string(m[2]), ";") {
+				prop, value, ok := strings.Cut(d, ":")
+				if !ok {
+					continue
+				}
+				value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "!important"))
+				decls = append(decls, styleDecl{sel, strings.TrimSpace(prop), value, spec, order})
+				order++
+			}
+		}
+	}
+	if len(sels) == 0 {
+		return nil, nil, fmt.Errorf("no rules")
+	}
+	return decls, sels, nil
+}
+
+type htmlElement struct {
+	tag     string
+	classes map[string]bool
+	attrs   map[string]string
+	parent  *htmlElement
+	line    int
+}
+
+var voidElements = map[string]bool{"br": true, "hr": true, "img": true, "input": true, "meta": true, "link": true, "col": true, "wbr": true}
+
+func parseHTML(src string) []*htmlElement {
+	src = htmlNote.ReplaceAllStringFunc(src, func(s string) string {
+		return strings.Repeat("\n", strings.Count(s, "\n"))
+	})
+	var all []*htmlElement
+	var stack []*htmlElement
+	for _, m := range htmlTag.FindAllStringSubmatchIndex(src, -1) {
+		closing, tag := src[m[2]:m[3]] == "/", strings.ToLower(src[m[4]:m[5]])
+		if closing {
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].tag == tag {
+					stack = stack[:i]
+					break
+				}
+			}
+			continue
+		}
+		e := &htmlElement{tag: tag, classes: map[string]bool{}, attrs: map[string]string{}, line: strings.Count(src[:m[0]], "\n") + 1}
+		for _, a := range htmlAttr.FindAllStringSubmatch(src[m[6]:m[7]], -1) {
+			e.attrs[a[1]] = html.UnescapeString(a[2])
+		}
+		for _, c := range strings.Fields(e.attrs["class"]) {
+			e.classes[c] = true
+		}
+		if len(stack) > 0 {
+			e.parent = stack[len(stack)-1]
+		}
+		all = append(all, e)
+		if !voidElements[tag] && !strings.HasSuffix(src[m[6]:m[7]], "/") {
+			stack = append(stack, e)
+		}
+	}
+	return all
+}
+
+func (c cssCompound) matches(e *htmlElement) bool {
+	if c.tag != "" && c.tag != e.tag {
+		return false
+	}
+	for _, cl := range c.classes {
+		if !e.classes[cl] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s cssSelector) matches(e *htmlElement) bool {
+	i := len(s.compounds) - 1
+	if !s.compounds[i].matches(e) {
+		return false
+	}
+	for i--; i >= 0; i-- {
+		for e = e.parent; e != nil && !s.compounds[i].matches(e); e = e.parent {
+		}
+		if e == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// computed returns the cascaded value of property for e, inheriting from
+// its ancestors when inherit is set.
+func computed(decls []styleDecl, e *htmlElement, property string, inherit bool) (string, bool) {
+	for ; e != nil; e = e.parent {
+		best := -1
+		for i, d := range decls {
+			if d.property != property || !d.selector.matches(e) {
+				continue
+			}
+			if best < 0 || d.specificity > decls[best].specificity ||
+				d.specificity == decls[best].specificity && d.order > decls[best].order {
+				best = i
+			}
+		}
+		if best >= 0 {
+			return decls[best].value, true
+		}
+		if !inherit {
+			break
+		}
+	}
+	return "", false
+}
+
+// checkUserstyle applies the generated CSS to the saved markup with a
+// small cascade: compound class and tag selectors, the descendant
+// combinator, specificity and source order, and inheritance of color and
+// font-style. It fails on an element whose data-color, data-background or
+// data-font-style does not come out as the named role, and on a selector
+// that matches nothing in the fixtures, which is how renamed classes show.
+func checkUserstyle(site string, css []byte, p *palette.Palette) error {
+	decls, sels, err := parseCSS(css)
+	if err != nil {
+		return fmt.Errorf("%s userstyle: %w", site, err)
+	}
+	names, err := userstyleFixtures.ReadDir("testdata/pages")
+	if err != nil {
+		return err
+	}
+	var problems []string
+	used := map[string]bool{}
+	fixtures := 0
+	for _, n := range names {
+		if !strings.HasPrefix(n.Name(), site+"-") {
+			continue
+		}
+		fixtures++
+		name := path.Join("testdata/pages", n.Name())
+		src, err := userstyleFixtures.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		for _, e := range parseHTML(string(src)) {
+			for _, s := range sels {
+				if s.matches(e) {
+					used[s.text] = true
+				}
+			}
+			for _, c := range []struct {
+				attr, property string
+				inherit        bool
+			}{
+				{"data-color", "color", true},
+				{"data-background", "background-color", false},
+				{"data-font-style", "font-style", true},
+			} {
+				want, ok := e.attrs[c.attr]
+				if !ok {
+					continue
+				}
+				got, _ := computed(decls, e, c.property, c.inherit)
+				if c.property == "font-style" {
+					if got != want {
+						problems = append(problems, fmt.Sprintf("%s:%d: <%s class=%q> has font-style %q, want %q", n.Name(), e.line, e.tag, e.attrs["class"], got, want))
+					}
+					continue
+				}
+				role := palette.Role(want)
+				if _, known := palette.Lookup(role); !known {
+					return fmt.Errorf("%s:%d: unknown role %q", n.Name(), e.line, want)
+				}
+				// Backgrounds may be translucent; the hue is what says
+				// which role they are.
+				c2, err := colors.ParseHex(got)
+				if err != nil || c2.Opaque() != p.Color(role).Opaque() {
+					problems = append(problems, fmt.Sprintf("%s:%d: <%s class=%q> has %s %q, want %s %s",
+						n.Name(), e.line, e.tag, e.attrs["class"], c.property, got, role, p.Hex(role)))
+				}
+			}
+		}
+	}
+	if fixtures == 0 {
+		return fmt.Errorf("%s userstyle: no fixtures in testdata/pages", site)
+	}
+	var unused []string
+	for _, s := range sels {
+		if !used[s.text] {
+			unused = append(unused, s.text)
+		}
+	}
+	sort.Strings(unused)
+	for _, s := range unused {
+		problems = append(problems, fmt.Sprintf("%s userstyle: %s matches nothing in the fixtures", site, s))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,459 @@
+// Package gallery generates the static files of a private extension
+// gallery from packaged VSIX files: the extension query response VS Code
+// posts to <serviceUrl>/extensionquery, the packages it downloads and the
+// assets (manifest, README, changelog, licence, icon) it shows on the
+// extension page, all at fixed URLs that a plain file server can serve.
+package gallery
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+)
+
+// Asset types VS Code requests by name.
+const (
+	AssetVSIX      = "Microsoft.VisualStudio.Services.VSIXPackage"
+	AssetVSIXMan   = "Microsoft.VisualStudio.Services.VsixManifest"
+	AssetManifest  = "Microsoft.VisualStudio.Code.Manifest"
+	AssetDetails   = "Microsoft.VisualStudio.Services.Content.Details"
+	AssetChangelog = "Microsoft.VisualStudio.Services.Content.Changelog"
+	AssetLicense   = "Microsoft.VisualStudio.Services.Content.License"
+	AssetIcon      = "Microsoft.VisualStudio.Services.Icons.Default"
+)
+
+// EngineProperty is the version property holding engines.vscode, which VS
+// Code matches against its own version to pick a compatible release.
+const EngineProperty = "Microsoft.VisualStudio.Code.Engine"
+
+// QueryPath is where VS Code posts extension queries, under serviceUrl.
+const QueryPath = "extensionquery"
+
+// Extension is one packaged version of an extension.
+type Extension struct {
+	Manifest *manifest.Manifest
+	// VSIX is the package as read.
+	VSIX []byte
+	// Assets maps asset types to their contents in the package.
+	Assets map[string][]byte
+	// Properties are the vsixmanifest properties, in package order.
+	Properties []Property
+	Updated    time.Time
+}
+
+// Property is a key and value of a version's properties.
+type Property struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// vsixManifest is the part of extension.vsixmanifest the gallery reads.
+type vsixManifest struct {
+	Metadata struct {
+		Identity struct {
+			ID        string `xml:"Id,attr"`
+			Version   string `xml:"Version,attr"`
+			Publisher string `xml:"Publisher,attr"`
+		}
+		Properties []struct {
+			ID    string `xml:"Id,attr"`
+			Value string `xml:"Value,attr"`
+		} `xml:"Properties>Property"`
+		Icon string
+	}
+	Assets []struct {
+		Type string `xml:"Type,attr"`
+		Path string `xml:"Path,attr"`
+	} `xml:"Assets>Asset"`
+}
+
+// The names vsce accepts for a publisher and an extension, the semantic
+// version it requires, and the dotted identifiers asset types are. All of
+// them become path segments of the gallery, so anything else is refused
+// rather than written.
+var (
+	namePattern      = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]*$`)
+	versionPattern   = regexp.MustCompile(`^(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+	assetTypePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(\.[A-Za-z][A-Za-z0-9]*)*$`)
+)
+
+// Read reads a VSIX. The identity and properties come from the
+// vsixmanifest; the engine, categories and keywords from the package.json
+// it lists, which must agree with it.
+func Read(data []byte) (*Extension, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	entries := map[string]*zip.File{}
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+	read := func(name string) ([]byte, error) {
+		f, ok := entries[name]
+		if !ok {
+			return nil, fmt.Errorf("no %s in the package", name)
+		}
+		r, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+
+	raw, err := read("extension.vsixmanifest")
+	if err != nil {
+		return nil, err
+	}
+	var vm vsixManifest
+	if err := xml.Unmarshal(raw, &vm); err != nil {
+		return nil, fmt.Errorf("extension.vsixmanifest: %w", err)
+	}
+	e := &Extension{VSIX: data, Assets: map[string][]byte{AssetVSIX: data, AssetVSIXMan: raw}}
+	paths := map[string]string{}
+	for _, a := range vm.Assets {
+		if !assetTypePattern.MatchString(a.Type) {
+			return nil, fmt.Errorf("extension.vsixmanifest: asset type %q is not a dotted identifier", a.Type)
+		}
+		paths[a.Type] = a.Path
+	}
+	if vm.Metadata.Icon != "" && paths[AssetIcon] == "" {
+		paths[AssetIcon] = vm.Metadata.Icon
+	}
+	if paths[AssetManifest] == "" {
+		paths[AssetManifest] = "extension/package.json"
+	}
+	for typ, p := range paths {
+		if e.Assets[typ], err = read(p); err != nil {
+			return nil, fmt.Errorf("asset %s: %w", typ, err)
+		}
+	}
+	if e.Manifest, err = manifest.Parse(e.Assets[AssetManifest]); err != nil {
+		return nil, fmt.Errorf("%s: %w", paths[AssetManifest], err)
+	}
+	m := e.Manifest
+	if m.Icon != "" && e.Assets[AssetIcon] == nil {
+		if e.Assets[AssetIcon], err = read(path.Join("extension", m.Icon)); err != nil {
+			return nil, fmt.Errorf("icon: %w", err)
+		}
+	}
+	id := vm.Metadata.Identity
+	if id.Publisher != m.Publisher || id.ID != m.Name || id.Version != m.Version {
+		return nil, fmt.Errorf("vsixmanifest is %s.%s@%s but package.json is %s@%s", id.Publisher, id.ID, id.Version, m.ExtensionID(), m.Version)
+	}
+	if m.Publisher == "" || m.Name == "" || m.Version == "" {
+		return nil, fmt.Errorf("package.json needs a publisher, name and version")
+	}
+	if !namePattern.MatchString(m.Publisher) {
+		return nil, fmt.Errorf("package.json: publisher %q is not a valid publisher name", m.Publisher)
+	}
+	if !namePattern.MatchString(m.Name) {
+		return nil, fmt.Errorf("package.json: name %q is not a valid extension name", m.Name)
+	}
+	if !versionPattern.MatchString(m.Version) {
+		return nil, fmt.Errorf("package.json: version %q is not a semantic version", m.Version)
+	}
+	engine := m.Engines["vscode"]
+	if engine == "" {
+		return nil, fmt.Errorf("%s: package.json has no engines.vscode", m.ExtensionID())
+	}
+	for _, p := range vm.Metadata.Properties {
+		if p.ID == EngineProperty && p.Value != engine {
+			return nil, fmt.Errorf("%s: vsixmanifest engine %s, package.json %s", m.ExtensionID(), p.Value, engine)
+		}
+		if p.ID != EngineProperty {
+			e.Properties = append(e.Properties, Property{p.ID, p.Value})
+		}
+	}
+	e.Properties = append([]Property{{EngineProperty, engine}}, e.Properties...)
+	// The package's own timestamp keeps the output the same for the same
+	// package.
+	e.Updated = entries[paths[AssetManifest]].Modified.UTC().Truncate(time.Second)
+	return e, nil
+}
+
+// Response is the extension query response.
+type Response struct {
+	Results []Result `json:"results"`
+}
+
+// Result is one result set of a query response.
+type Result struct {
+	Extensions     []GalleryExtension `json:"extensions"`
+	PagingToken    *string            `json:"pagingToken"`
+	ResultMetadata []ResultMetadata   `json:"resultMetadata"`
+}
+
+// ResultMetadata carries the total count VS Code pages by.
+type ResultMetadata struct {
+	MetadataType  string         `json:"metadataType"`
+	MetadataItems []MetadataItem `json:"metadataItems"`
+}
+
+// MetadataItem is a named count.
+type MetadataItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// GalleryExtension is an extension with all its versions, newest first.
+type GalleryExtension struct {
+	Publisher        Publisher            `json:"publisher"`
+	ExtensionID      string               `json:"extensionId"`
+	ExtensionName    string               `json:"extensionName"`
+	DisplayName      string               `json:"displayName"`
+	Flags            string               `json:"flags"`
+	LastUpdated      string               `json:"lastUpdated"`
+	PublishedDate    string               `json:"publishedDate"`
+	ReleaseDate      string               `json:"releaseDate"`
+	ShortDescription string               `json:"shortDescription"`
+	Versions         []Version            `json:"versions"`
+	Categories       []string             `json:"categories"`
+	Tags             []string             `json:"tags"`
+	Statistics       []any                `json:"statistics"`
+	Targets          []InstallationTarget `json:"installationTargets"`
+	DeploymentType   int                  `json:"deploymentType"`
+}
+
+// Publisher identifies the publisher of an extension.
+type Publisher struct {
+	PublisherID   string `json:"publisherId"`
+	PublisherName string `json:"publisherName"`
+	DisplayName   string `json:"displayName"`
+	Flags         string `json:"flags"`
+}
+
+// Version is one released version with its asset URLs.
+type Version struct {
+	Version          string     `json:"version"`
+	Flags            string     `json:"flags"`
+	LastUpdated      string     `json:"lastUpdated"`
+	Files            []Asset    `json:"files"`
+	Properties       []Property `json:"properties"`
+	AssetURI         string     `json:"assetUri"`
+	FallbackAssetURI string     `json:"fallbackAssetUri"`
+}
+
+// Asset is the URL of one asset of a version.
+type Asset struct {
+	AssetType string `json:"assetType"`
+	Source    string `json:"source"`
+}
+
+// InstallationTarget is the product an extension installs into.
+type InstallationTarget struct {
+	Target        string `json:"target"`
+	TargetVersion string `json:"targetVersion"`
+}
+
+// AssetDir is the directory, relative to the gallery root, holding the
+// assets of a version, each in a file named by its asset type.
+func AssetDir(publisher, name, version string) string {
+	return path.Join("assets", publisher, name, version)
+}
+
+// PackagePath is where VS Code downloads a version from, relative to the
+// gallery root.
+func PackagePath(publisher, name, version string) string {
+	return path.Join("publishers", publisher, "vsextensions", name, version, "vspackage")
+}
+
+// Generate lays out the gallery for the extensions, served from baseURL:
+// the query response, the product.json extensionsGallery setting and, for
+// each version, its package and assets.
+func Generate(exts []*Extension, baseURL string) ([]export.File, *Response, error) {
+	base := strings.TrimSuffix(baseURL, "/")
+	if base == "" {
+		return nil, nil, fmt.Errorf("gallery: no base URL")
+	}
+	byID := map[string][]*Extension{}
+	var ids []string
+	for _, e := range exts {
+		id := strings.ToLower(e.Manifest.ExtensionID())
+		if len(byID[id]) == 0 {
+			ids = append(ids, id)
+		}
+		for _, o := range byID[id] {
+			if o.Manifest.Version == e.Manifest.Version {
+				return nil, nil, fmt.Errorf("%s %s is given twice", e.Manifest.ExtensionID(), e.Manifest.Version)
+			}
+		}
+		byID[id] = append(byID[id], e)
+	}
+	sort.Strings(ids)
+
+	var files []export.File
+	result := Result{ResultMetadata: []ResultMetadata{{
+		MetadataType:  "ResultCount",
+		MetadataItems: []MetadataItem{{Name: "TotalCount", Count: len(ids)}},
+	}}}
+	for _, id := range ids {
+		vs := byID[id]
+		sort.Slice(vs, func(i, j int) bool { return CompareVersions(vs[i].Manifest.Version, vs[j].Manifest.Version) > 0 })
+		latest := vs[0].Manifest
+		ge := GalleryExtension{
+			Publisher: Publisher{
+				PublisherID:   nameUUID("publisher:" + strings.ToLower(latest.Publisher)),
+				PublisherName: latest.Publisher,
+				DisplayName:   latest.Publisher,
+				Flags:         "none",
+			},
+			ExtensionID:      nameUUID("extension:" + id),
+			ExtensionName:    latest.Name,
+			DisplayName:      latest.DisplayName,
+			Flags:            "validated, public",
+			ShortDescription: latest.Description,
+			Categories:       nonNil(latest.Categories),
+			Tags:             nonNil(latest.Keywords),
+			Statistics:       []any{},
+			Targets:          []InstallationTarget{{Target: "Microsoft.VisualStudio.Code"}},
+		}
+		if ge.DisplayName == "" {
+			ge.DisplayName = latest.Name
+		}
+		first := vs[0].Updated
+		for _, e := range vs {
+			m := e.Manifest
+			dir := AssetDir(m.Publisher, m.Name, m.Version)
+			v := Version{
+				Version:          m.Version,
+				Flags:            "validated",
+				LastUpdated:      e.Updated.Format(time.RFC3339),
+				Properties:       e.Properties,
+				AssetURI:         base + "/" + dir,
+				FallbackAssetURI: base + "/" + dir,
+			}
+			types := make([]string, 0, len(e.Assets))
+			for typ := range e.Assets {
+				types = append(types, typ)
+			}
+			sort.Strings(types)
+			for _, typ := range types {
+				files = append(files, export.File{Path: dir + "/" + typ, Data: e.Assets[typ]})
+				v.Files = append(v.Files, Asset{AssetType: typ, Source: base + "/" + dir + "/" + typ})
+			}
+			files = append(files, export.File{Path: PackagePath(m.Publisher, m.Name, m.Version), Data: e.VSIX})
+			ge.Versions = append(ge.Versions, v)
+			if e.Updated.Before(first) {
+				first = e.Updated
+			}
+		}
+		ge.LastUpdated = vs[0].Updated.Format(time.RFC3339)
+		ge.PublishedDate = first.Format(time.RFC3339)
+		ge.ReleaseDate = ge.PublishedDate
+		result.Extensions = append(result.Extensions, ge)
+	}
+
+	resp := &Response{Results: []Result{result}}
+	query, err := marshal(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+	product, err := marshal(map[string]map[string]string{
+		"extensionsGallery": {"serviceUrl": base},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	files = append(files,
+		export.File{Path: QueryPath, Data: query},
+		export.File{Path: "product.json", Data: product},
+	)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, resp, nil
+}
+
+func marshal(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// nameUUID derives a stable version 5 style UUID from a name. The gallery
+// only needs ids that stay the same between runs.
+func nameUUID(name string) string {
+	h := sha1.Sum([]byte(name))
+	h[6] = h[6]&0x0f | 0x50
+	h[8] = h[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
+
+// CompareVersions compares two semantic versions as VS Code orders
+// releases: by major, minor and patch, with a pre-release before its
+// release. It returns -1, 0 or 1.
+func CompareVersions(a, b string) int {
+	an, apre, _ := strings.Cut(a, "-")
+	bn, bpre, _ := strings.Cut(b, "-")
+	as, bs := strings.Split(an, "."), strings.Split(bn, ".")
+	for i := 0; i < 3; i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	case apre < bpre:
+		return -1
+	}
+	return 1
+}
+
+// EngineSatisfied reports whether VS Code at version vscode meets an
+// engines.vscode requirement: "*", an exact version, ">=x.y.z" or
+// "^x.y.z", the forms vsce accepts.
+func EngineSatisfied(engine, vscode string) bool {
+	engine = strings.TrimSpace(engine)
+	switch {
+	case engine == "*":
+		return true
+	case strings.HasPrefix(engine, ">="):
+		return CompareVersions(vscode, strings.TrimSpace(engine[2:])) >= 0
+	case strings.HasPrefix(engine, "^"):
+		min := engine[1:]
+		major, _, _ := strings.Cut(min, ".")
+		have, _, _ := strings.Cut(vscode, ".")
+		return major == have && CompareVersions(vscode, min) >= 0
+	}
+	return CompareVersions(vscode, engine) == 0
+}
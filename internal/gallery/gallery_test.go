@@ -0,0 +1,468 @@
+package gallery
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/vsix"
+)
+
+const root = "../.."
+
+// fixture is a package the tests build from the repository, with its own
+// version and engine.
+type fixture struct {
+	version, engine string
+}
+
+// packVSIX builds a VSIX from the files vsce would package from root, with
+// the version and engine of f, an icon and a vsixmanifest listing the
+// assets as vsce does. edit, if not nil, changes package.json before it is
+// packed.
+func packVSIX(t *testing.T, f fixture, updated time.Time, edit func(map[string]any)) []byte {
+	t.Helper()
+	pkg, err := vsix.Contents(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	doc["version"] = f.version
+	doc["engines"] = map[string]string{"vscode": f.engine}
+	doc["icon"] = "icon.png"
+	if edit != nil {
+		edit(doc)
+	}
+	pkgJSON, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := manifest.Parse(pkgJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contents := map[string][]byte{"extension/package.json": pkgJSON, "extension/icon.png": icon(t, m.Banner.Color)}
+	for _, file := range pkg.Files {
+		if file.Path == "package.json" {
+			continue
+		}
+		if contents["extension/"+file.Path], err = os.ReadFile(filepath.Join(root, filepath.FromSlash(file.Path))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assets := [][2]string{{AssetManifest, "extension/package.json"}}
+	for _, a := range [][2]string{
+		{AssetDetails, "extension/README.md"},
+		{AssetChangelog, "extension/CHANGELOG.md"},
+		{AssetLicense, "extension/LICENSE"},
+		{AssetIcon, "extension/icon.png"},
+	} {
+		if contents[a[1]] != nil {
+			assets = append(assets, a)
+		}
+	}
+	contents["extension.vsixmanifest"] = vsixManifestFor(m, f.engine, assets)
+	return zipFiles(t, contents, updated)
+}
+
+// vsixManifestFor writes the extension.vsixmanifest vsce would write for m.
+func vsixManifestFor(m *manifest.Manifest, engine string, assets [][2]string) []byte {
+	esc := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	var vm strings.Builder
+	fmt.Fprintf(&vm, `<?xml version="1.0" encoding="utf-8"?>
+<PackageManifest Version="2.0.0" xmlns="http://schemas.microsoft.com/developer/vsx-schema/2011" xmlns:d="http://schemas.microsoft.com/developer/vsx-schema-design/2011">
+  <Metadata>
+    <Identity Language="en-US" Id="%s" Version="%s" Publisher="%s" />
+    <DisplayName>%s</DisplayName>
+    <Description xml:space="preserve">%s</Description>
+    <Tags>%s</Tags>
+    <Categories>%s</Categories>
+    <GalleryFlags>Public</GalleryFlags>
+    <Properties>
+      <Property Id="%s" Value="%s" />
+      <Property Id="Microsoft.VisualStudio.Services.Links.Source" Value="%s" />
+    </Properties>
+    <Icon>extension/icon.png</Icon>
+  </Metadata>
+  <Installation>
+    <InstallationTarget Id="Microsoft.VisualStudio.Code" />
+  </Installation>
+  <Dependencies />
+  <Assets>
+`, esc(m.Name), esc(m.Version), esc(m.Publisher), esc(m.DisplayName), esc(m.Description),
+		esc(strings.Join(m.Keywords, ",")), esc(strings.Join(m.Categories, ",")),
+		EngineProperty, esc(engine), esc(m.Repository.URL))
+	for _, a := range assets {
+		fmt.Fprintf(&vm, "    <Asset Type=\"%s\" Path=\"%s\" Addressable=\"true\" />\n", esc(a[0]), esc(a[1]))
+	}
+	vm.WriteString("  </Assets>\n</PackageManifest>\n")
+	return []byte(vm.String())
+}
+
+func zipFiles(t *testing.T, contents map[string][]byte, updated time.Time) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		names = append(names, name)
+	}
+	// Sorted, so that the same inputs give the same bytes.
+	sort.Strings(names)
+	for _, name := range names {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: updated})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(contents[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// icon draws a plain 128 pixel icon in the banner colour.
+func icon(t *testing.T, hex string) []byte {
+	t.Helper()
+	c, err := colors.ParseHex(hex)
+	if err != nil {
+		t.Fatalf("galleryBanner: %v", err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 128, 128))
+	fill := color.RGBA{c.R, c.G, c.B, 0xff}
+	for y := 0; y < 128; y++ {
+		for x := 0; x < 128; x++ {
+			img.SetRGBA(x, y, fill)
+		}
+	}
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+// fileGallery serves dir as a plain file server would, answering the
+// query POST with the static response as the server config in the README
+// does.
+func fileGallery(dir string) http.Handler {
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/"+QueryPath:
+			r.Method = http.MethodGet
+		case r.Method != http.MethodGet && r.Method != http.MethodHead:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// served packs the current version and an older pre-release for an older
+// VS Code, generates their gallery and serves it from a local file server.
+type served struct {
+	m              *manifest.Manifest
+	current, older fixture
+	byVersion      map[string]*Extension
+	srv            *httptest.Server
+}
+
+func serveGallery(t *testing.T) *served {
+	t.Helper()
+	m, err := manifest.Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &served{
+		m:         m,
+		current:   fixture{m.Version, m.Engines["vscode"]},
+		older:     fixture{m.Version + "-rc.1", "^1.80.0"},
+		byVersion: map[string]*Extension{},
+	}
+	updated := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var exts []*Extension
+	for i, f := range []fixture{s.older, s.current} {
+		e, err := Read(packVSIX(t, f, updated.AddDate(0, i, 0), nil))
+		if err != nil {
+			t.Fatalf("%s: %v", f.version, err)
+		}
+		exts = append(exts, e)
+		s.byVersion[f.version] = e
+	}
+
+	dir := t.TempDir()
+	s.srv = httptest.NewServer(fileGallery(dir))
+	t.Cleanup(s.srv.Close)
+	files, _, err := Generate(exts, s.srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := export.Write(dir, files); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// query posts the extension query VS Code sends for the extension.
+func (s *served) query(t *testing.T) GalleryExtension {
+	t.Helper()
+	body := fmt.Sprintf(`{"filters":[{"criteria":[{"filterType":7,"value":%q}],"pageNumber":1,"pageSize":1}],"flags":950}`, s.m.ExtensionID())
+	req, err := http.NewRequest(http.MethodPost, s.srv.URL+"/"+QueryPath, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json;api-version=3.0-preview.1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("query: status %d", resp.StatusCode)
+	}
+	var got Response
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Results) != 1 || len(got.Results[0].Extensions) != 1 {
+		t.Fatalf("query: want one result with one extension, got %+v", got.Results)
+	}
+	return got.Results[0].Extensions[0]
+}
+
+// fetch requests a path of the gallery and returns the status and body.
+func (s *served) fetch(t *testing.T, method, p string) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, s.srv.URL+p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestQuery(t *testing.T) {
+	s := serveGallery(t)
+	ext := s.query(t)
+	if !strings.EqualFold(ext.Publisher.PublisherName+"."+ext.ExtensionName, s.m.ExtensionID()) {
+		t.Errorf("extension %s.%s, want %s", ext.Publisher.PublisherName, ext.ExtensionName, s.m.ExtensionID())
+	}
+	if strings.Join(ext.Categories, ",") != strings.Join(s.m.Categories, ",") || strings.Join(ext.Tags, ",") != strings.Join(s.m.Keywords, ",") {
+		t.Errorf("categories %v and tags %v, want %v and %v", ext.Categories, ext.Tags, s.m.Categories, s.m.Keywords)
+	}
+	var vs []string
+	for _, v := range ext.Versions {
+		vs = append(vs, v.Version)
+	}
+	if want := s.current.version + "," + s.older.version; strings.Join(vs, ",") != want {
+		t.Errorf("versions %v, want %s", vs, want)
+	}
+}
+
+func TestEngineChoosesVersion(t *testing.T) {
+	s := serveGallery(t)
+	ext := s.query(t)
+	for _, tc := range []struct{ vscode, want string }{
+		{strings.TrimPrefix(s.current.engine, "^"), s.current.version},
+		{"1.90.0", s.older.version},
+		{"1.79.0", ""},
+	} {
+		got := ""
+		for _, v := range ext.Versions {
+			if EngineSatisfied(property(v, EngineProperty), tc.vscode) {
+				got = v.Version
+				break
+			}
+		}
+		if got != tc.want {
+			t.Errorf("VS Code %s gets %q, want %q", tc.vscode, got, tc.want)
+		}
+	}
+}
+
+func TestPackageDownload(t *testing.T) {
+	s := serveGallery(t)
+	ext := s.query(t)
+	for _, v := range ext.Versions {
+		status, body := s.fetch(t, http.MethodGet, "/"+PackagePath(ext.Publisher.PublisherName, ext.ExtensionName, v.Version))
+		if status != http.StatusOK {
+			t.Errorf("%s: status %d", v.Version, status)
+			continue
+		}
+		if !bytes.Equal(body, s.byVersion[v.Version].VSIX) {
+			t.Errorf("%s: downloaded package differs", v.Version)
+		}
+		if e, err := Read(body); err != nil || e.Manifest.Version != v.Version {
+			t.Errorf("%s: downloaded package does not read back: %v", v.Version, err)
+		}
+	}
+}
+
+func TestAssets(t *testing.T) {
+	s := serveGallery(t)
+	ext := s.query(t)
+	readme, err := os.ReadFile(filepath.Join(root, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range ext.Versions {
+		e := s.byVersion[v.Version]
+		if len(v.Files) != len(e.Assets) {
+			t.Errorf("%s: %d assets, want %d", v.Version, len(v.Files), len(e.Assets))
+		}
+		for _, a := range v.Files {
+			if !strings.HasPrefix(a.Source, v.AssetURI+"/") || path.Base(a.Source) != a.AssetType {
+				t.Errorf("%s: %s is not at assetUri/%s", v.Version, a.Source, a.AssetType)
+				continue
+			}
+			status, body := s.fetch(t, http.MethodGet, strings.TrimPrefix(a.Source, s.srv.URL))
+			if status != http.StatusOK {
+				t.Errorf("%s %s: status %d", v.Version, a.AssetType, status)
+				continue
+			}
+			if !bytes.Equal(body, e.Assets[a.AssetType]) {
+				t.Errorf("%s %s: served %d bytes, want %d", v.Version, a.AssetType, len(body), len(e.Assets[a.AssetType]))
+			}
+		}
+		for _, typ := range []string{AssetManifest, AssetDetails, AssetIcon} {
+			if e.Assets[typ] == nil {
+				t.Errorf("%s: no %s", v.Version, typ)
+			}
+		}
+		if _, err := png.DecodeConfig(bytes.NewReader(e.Assets[AssetIcon])); err != nil {
+			t.Errorf("%s icon: %v", v.Version, err)
+		}
+		if !bytes.Equal(e.Assets[AssetDetails], readme) {
+			t.Errorf("%s: details asset is not README.md", v.Version)
+		}
+	}
+}
+
+func TestServedErrors(t *testing.T) {
+	s := serveGallery(t)
+	m := s.m
+	for _, tc := range []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodGet, "/" + PackagePath(m.Publisher, m.Name, "0.0.0"), http.StatusNotFound},
+		{http.MethodGet, "/" + AssetDir(m.Publisher, m.Name, s.current.version) + "/nope", http.StatusNotFound},
+		{http.MethodPost, "/" + PackagePath(m.Publisher, m.Name, s.current.version), http.StatusMethodNotAllowed},
+	} {
+		if status, body := s.fetch(t, tc.method, tc.path); status != tc.status {
+			t.Errorf("%s %s: status %d, want %d: %s", tc.method, tc.path, status, tc.status, body)
+		}
+	}
+}
+
+func TestReadRejectsUnsafePaths(t *testing.T) {
+	m, err := manifest.Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := fixture{m.Version, m.Engines["vscode"]}
+	updated := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		field, value string
+	}{
+		{"publisher", "../evil"},
+		{"publisher", "a/b"},
+		{"name", ".."},
+		{"name", "theme/../../x"},
+		{"version", "1.0.0/../../x"},
+		{"version", "1.0"},
+	} {
+		data := packVSIX(t, f, updated, func(doc map[string]any) { doc[tc.field] = tc.value })
+		if _, err := Read(data); err == nil {
+			t.Errorf("Read accepted %s %q", tc.field, tc.value)
+		}
+	}
+
+	for _, typ := range []string{"Microsoft.VisualStudio.Code.Readme", "../escape", "Microsoft..Icons", "a/b", ""} {
+		pkgJSON, err := os.ReadFile(filepath.Join(root, "package.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents := map[string][]byte{
+			"extension/package.json": pkgJSON,
+			"extension/icon.png":     icon(t, m.Banner.Color),
+			"extension.vsixmanifest": vsixManifestFor(m, f.engine, [][2]string{
+				{AssetManifest, "extension/package.json"},
+				{typ, "extension/package.json"},
+			}),
+		}
+		_, err = Read(zipFiles(t, contents, updated))
+		if valid := typ == "Microsoft.VisualStudio.Code.Readme"; (err == nil) != valid {
+			t.Errorf("Read of asset type %q: %v", typ, err)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+	} {
+		if got := CompareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+// property returns the value of a version property, or "".
+func property(v Version, key string) string {
+	for _, p := range v.Properties {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
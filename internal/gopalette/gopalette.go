@@ -0,0 +1,105 @@
+// Package gopalette generates the caffeinated Go package: the palette roles
+// as hex string constants for lipgloss and friends, which the caffeinated-vet
+// analyzer points colour literals at.
+package gopalette
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+)
+
+// Dir is where the package lives, relative to the repository root, and
+// ImportPath is its import path.
+const (
+	Dir        = "caffeinated"
+	ImportPath = "github.com/caffeinated-minds/caffeinated-rust/caffeinated"
+)
+
+// Ident returns the exported Go name of a role: "findMatch" is FindMatch.
+func Ident(r palette.Role) string {
+	return strings.ToUpper(string(r[:1])) + string(r[1:])
+}
+
+var kindComments = map[palette.Kind]string{
+	palette.UI:         "Workbench roles.",
+	palette.Diagnostic: "Diagnostic roles.",
+	palette.Git:        "Version control roles.",
+	palette.Syntax:     "Syntax roles.",
+}
+
+// Generate returns the source of the package for p. The selection, which
+// the theme keeps translucent, is laid over the background.
+func Generate(p *palette.Palette) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(`// Code generated by "caffeinated gopalette"; DO NOT EDIT.
+
+`)
+	fmt.Fprintf(&b, `// Package caffeinated is the %s palette for Go programs. The
+// roles are #RRGGBB strings, which lipgloss.Color and most terminal
+// libraries take; RGB splits them for fatih/color.
+package caffeinated
+`, p.Name)
+	hex := func(r palette.Role) string {
+		if r == palette.Selection {
+			return p.Color(r).Over(p.Color(palette.Background)).Hex()
+		}
+		return p.Hex(r)
+	}
+	kind := palette.Kind(-1)
+	for _, d := range palette.Definitions {
+		if d.Kind != kind {
+			if kind >= 0 {
+				b.WriteString(")\n")
+			}
+			kind = d.Kind
+			fmt.Fprintf(&b, "\n// %s\nconst (\n", kindComments[kind])
+		}
+		fmt.Fprintf(&b, "%s = %q\n", Ident(d.Role), hex(d.Role))
+	}
+	b.WriteString(`)
+
+// Role is a named colour of the palette.
+type Role struct {
+	Name string
+	Hex  string
+}
+
+// Roles lists every role in canonical order.
+var Roles = []Role{
+`)
+	for _, d := range palette.Definitions {
+		fmt.Fprintf(&b, "{%q, %s},\n", Ident(d.Role), Ident(d.Role))
+	}
+	b.WriteString(`}
+
+// RGB returns the channels of a #RRGGBB colour, as color.RGB and
+// color.BgRGB take them: color.RGB(caffeinated.RGB(caffeinated.Accent)).
+// It returns zeros for anything else.
+func RGB(hex string) (r, g, b int) {
+	if len(hex) != 7 || hex[0] != '#' {
+		return 0, 0, 0
+	}
+	var v [3]int
+	for i := range v {
+		for _, c := range hex[1+2*i : 3+2*i] {
+			switch {
+			case '0' <= c && c <= '9':
+				v[i] = v[i]*16 + int(c-'0')
+			case 'a' <= c && c <= 'f':
+				v[i] = v[i]*16 + int(c-'a'+10)
+			case 'A' <= c && c <= 'F':
+				v[i] = v[i]*16 + int(c-'A'+10)
+			default:
+				return 0, 0, 0
+			}
+		}
+	}
+	return v[0], v[1], v[2]
+}
+`)
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,187 @@
+// Package jsonschema validates JSON documents against the subset of JSON
+// Schema used by the schemas bundled with the tooling: type, enum, const,
+// required, properties, additionalProperties, items, minItems, pattern,
+// minimum, maximum, local $ref into $defs, and contentMediaType/contentSchema
+// for JSON documents embedded in strings.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Schema is a parsed schema document.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Defs                 map[string]*Schema `json:"$defs"`
+	Type                 string             `json:"type"`
+	Enum                 []any              `json:"enum"`
+	Const                json.RawMessage    `json:"const"`
+	Required             []string           `json:"required"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	Pattern              string             `json:"pattern"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	ContentMediaType     string             `json:"contentMediaType"`
+	ContentSchema        *Schema            `json:"contentSchema"`
+}
+
+// Parse decodes a schema.
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("jsonschema: %w", err)
+	}
+	return &s, nil
+}
+
+// Error lists every violation found in a document.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "schema validation failed:\n\t" + strings.Join(e.Problems, "\n\t")
+}
+
+// Validate checks a JSON document against s.
+func (s *Schema) Validate(doc []byte) error {
+	var v any
+	if err := json.Unmarshal(doc, &v); err != nil {
+		return fmt.Errorf("jsonschema: %w", err)
+	}
+	var problems []string
+	s.validate(s, "$", v, &problems)
+	if len(problems) > 0 {
+		return &Error{Problems: problems}
+	}
+	return nil
+}
+
+func (s *Schema) validate(root *Schema, path string, v any, problems *[]string) {
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+	if s.Ref != "" {
+		def, ok := root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if !ok {
+			fail("unresolved $ref %q", s.Ref)
+			return
+		}
+		s = def
+	}
+	if s.Type != "" && !hasType(v, s.Type) {
+		fail("want %s, got %s", s.Type, typeOf(v))
+		return
+	}
+	// Const stays raw so that "const": null can be told from no const.
+	if s.Const != nil {
+		var want any
+		if err := json.Unmarshal(s.Const, &want); err != nil {
+			fail("bad const: %v", err)
+		} else if !reflect.DeepEqual(want, v) {
+			fail("want %s, got %v", s.Const, v)
+		}
+	}
+	if len(s.Enum) > 0 {
+		ok := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, v) {
+				ok = true
+			}
+		}
+		if !ok {
+			fail("%v is not one of %v", v, s.Enum)
+		}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for _, r := range s.Required {
+			if _, ok := v[r]; !ok {
+				fail("missing required property %q", r)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := s.Properties[k]; ok {
+				ps.validate(root, path+"."+k, v[k], problems)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				fail("unexpected property %q", k)
+			}
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("want at least %d items, got %d", *s.MinItems, len(v))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(root, fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	case string:
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				fail("bad pattern %q: %v", s.Pattern, err)
+			} else if !re.MatchString(v) {
+				fail("%q does not match %s", v, s.Pattern)
+			}
+		}
+		if s.ContentMediaType == "application/json" {
+			var inner any
+			if err := json.Unmarshal([]byte(v), &inner); err != nil {
+				fail("embedded JSON: %v", err)
+			} else if s.ContentSchema != nil {
+				s.ContentSchema.validate(root, path+"<json>", inner, problems)
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("%v is below the minimum %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("%v is above the maximum %v", v, *s.Maximum)
+		}
+	}
+}
+
+func hasType(v any, t string) bool {
+	switch t {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == float64(int64(f))
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	}
+	return typeOf(v) == t
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,88 @@
+package jsonschema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestKeywords checks each supported keyword with documents it accepts and
+// documents it rejects.
+func TestKeywords(t *testing.T) {
+	for _, tc := range []struct {
+		name, schema string
+		pass, fail   []string
+	}{
+		{"type", `{"type": "string"}`, []string{`"a"`}, []string{`1`, `null`, `{}`}},
+		{"type null", `{"type": "null"}`, []string{`null`}, []string{`0`, `""`}},
+		{"type integer", `{"type": "integer"}`, []string{`3`, `-2`, `1.0`}, []string{`1.5`, `"3"`}},
+		{"type number", `{"type": "number"}`, []string{`1.5`, `3`}, []string{`"1.5"`, `true`}},
+		{"enum", `{"enum": ["a", 1, true]}`, []string{`"a"`, `1`, `true`}, []string{`"b"`, `"1"`, `"true"`}},
+		{"const", `{"const": "a"}`, []string{`"a"`}, []string{`"b"`, `null`}},
+		{"const null", `{"const": null}`, []string{`null`}, []string{`"null"`, `0`, `false`}},
+		{"const object", `{"const": {"a": [1]}}`, []string{`{"a": [1]}`}, []string{`{"a": [2]}`, `{"a": [1], "b": 1}`}},
+		{"required", `{"type": "object", "required": ["a"]}`, []string{`{"a": null}`}, []string{`{}`, `{"b": 1}`}},
+		{"properties", `{"properties": {"a": {"type": "string"}}}`, []string{`{"a": "x"}`, `{"b": 1}`}, []string{`{"a": 1}`}},
+		{"additionalProperties", `{"properties": {"a": {}}, "additionalProperties": false}`, []string{`{"a": 1}`, `{}`}, []string{`{"b": 1}`}},
+		{"items", `{"items": {"type": "integer"}}`, []string{`[]`, `[1, 2]`}, []string{`[1, "2"]`}},
+		{"minItems", `{"minItems": 2}`, []string{`[1, 2]`}, []string{`[]`, `[1]`}},
+		{"pattern", `{"pattern": "^[a-z]+$"}`, []string{`"abc"`, `1`}, []string{`"ABC"`, `""`}},
+		{"minimum", `{"minimum": 1}`, []string{`1`, `2.5`}, []string{`0.5`}},
+		{"maximum", `{"maximum": 1}`, []string{`1`, `-3`}, []string{`1.5`}},
+		{"$ref", `{"$defs": {"id": {"type": "string", "pattern": "^x"}}, "properties": {"a": {"$ref": "#/$defs/id"}}}`, []string{`{"a": "xy"}`}, []string{`{"a": "y"}`, `{"a": 1}`}},
+		{"unresolved $ref", `{"$ref": "#/$defs/nope"}`, nil, []string{`1`}},
+		{"contentSchema", `{"type": "string", "contentMediaType": "application/json", "contentSchema": {"required": ["a"]}}`, []string{`"{\"a\": 1}"`}, []string{`"{}"`, `"{"`}},
+		{"contentSchema $ref", `{"$defs": {"n": {"type": "integer"}}, "contentMediaType": "application/json", "contentSchema": {"items": {"$ref": "#/$defs/n"}}}`, []string{`"[1, 2]"`}, []string{`"[1.5]"`}},
+	} {
+		s, err := Parse([]byte(tc.schema))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		for _, doc := range tc.pass {
+			if err := s.Validate([]byte(doc)); err != nil {
+				t.Errorf("%s: %s rejected: %v", tc.name, doc, err)
+			}
+		}
+		for _, doc := range tc.fail {
+			if err := s.Validate([]byte(doc)); err == nil {
+				t.Errorf("%s: %s accepted", tc.name, doc)
+			}
+		}
+	}
+}
+
+// TestProblems checks that every violation is reported with its path.
+func TestProblems(t *testing.T) {
+	s, err := Parse([]byte(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {"tags": {"items": {"type": "string"}}},
+		"additionalProperties": false
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Validate([]byte(`{"tags": ["a", 2], "extra": true}`))
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("Validate = %v, want an *Error", err)
+	}
+	want := []string{
+		`$: missing required property "name"`,
+		`$: unexpected property "extra"`,
+		`$.tags[1]: want string, got number`,
+	}
+	if strings.Join(e.Problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("problems:\n%s\nwant:\n%s", strings.Join(e.Problems, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestInvalidDocument(t *testing.T) {
+	s, err := Parse([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Validate([]byte(`{`)); err == nil {
+		t.Error("malformed JSON accepted")
+	}
+}
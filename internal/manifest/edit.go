@@ -0,0 +1,112 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// field is a member of a JSON object, kept in document order.
+type field struct {
+	key   string
+	value json.RawMessage
+}
+
+func decodeObject(data []byte) ([]field, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+	var fs []field
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		fs = append(fs, field{tok.(string), v})
+	}
+	return fs, nil
+}
+
+func encodeObject(fs []field) []byte {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range fs {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(f.key)
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(f.value)
+	}
+	b.WriteByte('}')
+	return b.Bytes()
+}
+
+// set replaces the value of key, or appends key when it is missing.
+func set(fs []field, key string, value json.RawMessage) []field {
+	for i := range fs {
+		if fs[i].key == key {
+			fs[i].value = value
+			return fs
+		}
+	}
+	return append(fs, field{key, value})
+}
+
+// SetContribution returns package.json data with contributes[key] set to
+// value, leaving every other field and the order of fields as they were.
+// The result is indented with two spaces, as npm writes package.json.
+func SetContribution(data []byte, key string, value any) ([]byte, error) {
+	top, err := decodeObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("package.json: %w", err)
+	}
+	contributes := []byte("{}")
+	for _, f := range top {
+		if f.key == "contributes" {
+			contributes = f.value
+		}
+	}
+	cs, err := decodeObject(contributes)
+	if err != nil {
+		return nil, fmt.Errorf("package.json: contributes: %w", err)
+	}
+	var v bytes.Buffer
+	enc := json.NewEncoder(&v)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	cs = set(cs, key, bytes.TrimSpace(v.Bytes()))
+	top = set(top, "contributes", encodeObject(cs))
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, encodeObject(top), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// WriteContribution sets contributes[key] in the package.json of root and
+// reports whether the file changed.
+func WriteContribution(root, key string, value any) (bool, error) {
+	path := filepath.Join(root, "package.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	out, err := SetContribution(data, key, value)
+	if err != nil || bytes.Equal(out, data) {
+		return false, err
+	}
+	return true, os.WriteFile(path, out, 0o644)
+}
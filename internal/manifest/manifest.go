@@ -0,0 +1,91 @@
+// Package manifest reads the extension's package.json.
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Manifest is the subset of package.json the tooling reads.
+type Manifest struct {
+	Name        string            `json:"name"`
+	DisplayName string            `json:"displayName"`
+	Description string            `json:"description"`
+	Version     string            `json:"version"`
+	Publisher   string            `json:"publisher"`
+	Repository  Repository        `json:"repository"`
+	Engines     map[string]string `json:"engines"`
+	Keywords    []string          `json:"keywords"`
+	Categories  []string          `json:"categories"`
+	Icon        string            `json:"icon"`
+	Banner      GalleryBanner     `json:"galleryBanner"`
+	Tooling     Tooling           `json:"caffeinated"`
+	Contributes Contributes       `json:"contributes"`
+}
+
+// Repository is the repository field.
+type Repository struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// GalleryBanner is the galleryBanner field: the colour of the marketplace
+// page header and whether its text is dark or light.
+type GalleryBanner struct {
+	Color string `json:"color"`
+	Theme string `json:"theme"`
+}
+
+// Tooling is the caffeinated field: settings for the repository's own
+// tools, kept here so that CI can change them without a code change.
+type Tooling struct {
+	// VSIXBudget is the largest the packaged extension may be, in bytes or
+	// with a K or M suffix. A colour theme is a few kilobytes of JSON;
+	// nearly all of the budget is for the README screenshots.
+	VSIXBudget string `json:"vsixBudget"`
+}
+
+// Contributes is the contributes field.
+type Contributes struct {
+	Themes              []ThemeContribution  `json:"themes"`
+	SemanticTokenScopes []SemanticTokenScope `json:"semanticTokenScopes,omitempty"`
+}
+
+// ThemeContribution is one entry of contributes.themes.
+type ThemeContribution struct {
+	Label   string `json:"label"`
+	UITheme string `json:"uiTheme"`
+	Path    string `json:"path"`
+}
+
+// SemanticTokenScope is one entry of contributes.semanticTokenScopes: the
+// TextMate scopes that semantic token selectors of a language fall back to
+// when the theme has no semanticTokenColors for them.
+type SemanticTokenScope struct {
+	Language string              `json:"language,omitempty"`
+	Scopes   map[string][]string `json:"scopes"`
+}
+
+// Load reads package.json from the repository root.
+func Load(root string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes package.json data, such as the copy inside a VSIX.
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ExtensionID returns the publisher.name identifier of the extension.
+func (m *Manifest) ExtensionID() string {
+	return m.Publisher + "." + m.Name
+}
@@ -0,0 +1,165 @@
+// Package mockup renders SVG mockups of workbench scenes in the colours a
+// theme actually paints, so that widgets can be reviewed side by side
+// without opening VS Code and so that ids the theme forgot, which fall back
+// to VS Code's defaults, stand out.
+package mockup
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+)
+
+// Character cell of the monospace font the scenes are laid out in.
+const (
+	fontSize  = 12
+	charWidth = 7.2
+	lineH     = 18
+)
+
+// Scene is one mockup.
+type Scene struct {
+	Name   string
+	Title  string
+	Width  int
+	Height int
+	Shapes []Shape
+}
+
+// Shape is a rectangle, optionally filled, outlined and labelled. Colours
+// are workbench colour ids, or "role:<name>" for a palette role.
+type Shape struct {
+	X, Y, W, H int
+	Fill       string
+	Stroke     string
+	Text       []Span
+}
+
+// Span is a run of text in one colour.
+type Span struct {
+	Text  string
+	Color string
+}
+
+// Report lists the colour ids a scene painted that the theme does not set.
+type Report struct {
+	// Defaulted ids were painted with VS Code's default colour.
+	Defaulted []string
+	// Unset ids have no colour at all, so the shape was left out.
+	Unset []string
+	// Unknown ids are missing from the registry snapshot, so the shape was
+	// left out although VS Code may paint it.
+	Unknown []string
+}
+
+// OK reports whether the theme set every id the scene uses.
+func (r *Report) OK() bool {
+	return len(r.Defaulted) == 0 && len(r.Unset) == 0 && len(r.Unknown) == 0
+}
+
+// Render draws s in the colours of eff, with syntax colours from p.
+func Render(s Scene, eff *colorreg.Effective, p *palette.Palette) ([]byte, *Report) {
+	r := &renderer{eff: eff, p: p, seen: map[string]bool{}, report: &Report{}}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", s.Width, s.Height, s.Width, s.Height)
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(s.Title))
+	fmt.Fprintf(&b, "<g font-family=\"Menlo, Consolas, 'DejaVu Sans Mono', monospace\" font-size=\"%d\">\n", fontSize)
+	for _, sh := range s.Shapes {
+		r.shape(&b, sh)
+	}
+	b.WriteString("</g>\n</svg>\n")
+	sort.Strings(r.report.Defaulted)
+	sort.Strings(r.report.Unset)
+	sort.Strings(r.report.Unknown)
+	return b.Bytes(), r.report
+}
+
+type renderer struct {
+	eff    *colorreg.Effective
+	p      *palette.Palette
+	seen   map[string]bool
+	report *Report
+}
+
+// paint resolves a colour reference and records ids the theme leaves to
+// VS Code.
+func (r *renderer) paint(ref string) (colors.Color, bool) {
+	if role, ok := strings.CutPrefix(ref, "role:"); ok {
+		return r.p.Color(palette.Role(role)), true
+	}
+	v, _ := r.eff.Lookup(ref)
+	if !r.seen[ref] {
+		r.seen[ref] = true
+		switch v.Source {
+		case colorreg.Defaulted:
+			r.report.Defaulted = append(r.report.Defaulted, ref)
+		case colorreg.Unset:
+			r.report.Unset = append(r.report.Unset, ref)
+		case colorreg.Unknown:
+			r.report.Unknown = append(r.report.Unknown, ref)
+		}
+	}
+	return v.Color, v.Source == colorreg.Themed || v.Source == colorreg.Defaulted
+}
+
+func (r *renderer) attr(name, ref string) string {
+	c, ok := r.paint(ref)
+	if !ok {
+		return fmt.Sprintf(` %s="none"`, name)
+	}
+	s := fmt.Sprintf(` %s="%s"`, name, c.Opaque().Hex())
+	if c.A != 0xff {
+		s += fmt.Sprintf(` %s-opacity="%.3g"`, name, float64(c.A)/255)
+	}
+	return s
+}
+
+func (r *renderer) shape(b *bytes.Buffer, sh Shape) {
+	if sh.Fill != "" || sh.Stroke != "" {
+		fill, stroke := ` fill="none"`, ""
+		if sh.Fill != "" {
+			fill = r.attr("fill", sh.Fill)
+		}
+		if sh.Stroke != "" {
+			stroke = r.attr("stroke", sh.Stroke)
+		}
+		x, y, w, h := float64(sh.X), float64(sh.Y), float64(sh.W), float64(sh.H)
+		if stroke != "" {
+			// Strokes are centred on the outline; inset by half a pixel so
+			// that borders stay crisp and inside the shape, as CSS borders are.
+			x, y, w, h = x+0.5, y+0.5, w-1, h-1
+		}
+		fmt.Fprintf(b, "<rect x=\"%g\" y=\"%g\" width=\"%g\" height=\"%g\"%s%s/>\n", x, y, w, h, fill, stroke)
+	}
+	if len(sh.Text) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "<text x=\"%d\" y=\"%d\" xml:space=\"preserve\">", sh.X, sh.Y+sh.H/2+fontSize/2-2)
+	for _, sp := range sh.Text {
+		fmt.Fprintf(b, "<tspan%s%s>%s</tspan>", r.attr("fill", sp.Color), r.fontStyle(sp.Color), html.EscapeString(sp.Text))
+	}
+	b.WriteString("</text>\n")
+}
+
+// fontStyle renders the font style of a palette role.
+func (r *renderer) fontStyle(ref string) string {
+	role, ok := strings.CutPrefix(ref, "role:")
+	if !ok {
+		return ""
+	}
+	var s string
+	style := r.p.FontStyle(palette.Role(role))
+	if strings.Contains(style, "italic") {
+		s += ` font-style="italic"`
+	}
+	if strings.Contains(style, "bold") {
+		s += ` font-weight="bold"`
+	}
+	return s
+}
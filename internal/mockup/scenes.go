@@ -0,0 +1,243 @@
+package mockup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scenes lists the built-in mockups.
+var Scenes = []Scene{
+	findScene(),
+	widgetsScene(),
+	quickFixScene(),
+	treeScene(),
+}
+
+// Lookup returns the scene with the given name.
+func Lookup(name string) (Scene, bool) {
+	for _, s := range Scenes {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Scene{}, false
+}
+
+// builder accumulates the shapes of a scene.
+type builder struct {
+	Scene
+}
+
+func (b *builder) rect(x, y, w, h int, fill, stroke string) {
+	b.Shapes = append(b.Shapes, Shape{X: x, Y: y, W: w, H: h, Fill: fill, Stroke: stroke})
+}
+
+func (b *builder) text(x, y int, spans ...Span) {
+	n := 0
+	for _, s := range spans {
+		n += len(s.Text)
+	}
+	b.Shapes = append(b.Shapes, Shape{X: x, Y: y, W: cols(n), H: lineH, Text: spans})
+}
+
+func span(color, text string) Span { return Span{Text: text, Color: color} }
+
+func tok(role, text string) Span { return Span{Text: text, Color: "role:" + role} }
+
+// cols is the width of n characters.
+func cols(n int) int { return int(float64(n)*charWidth + 0.5) }
+
+// source is a short Go function shown in the editor scenes, one line of
+// tokens per row.
+var source = [][]Span{
+	{tok("keyword", "func"), tok("variable", " "), tok("function", "render"), tok("punctuation", "("), tok("variable", "w io"), tok("punctuation", "."), tok("type", "Writer"), tok("punctuation", ", "), tok("variable", "lines "), tok("punctuation", "[]"), tok("type", "string"), tok("punctuation", ") "), tok("type", "error"), tok("punctuation", " {")},
+	{tok("comment", "    // Write every line, then flush.")},
+	{tok("keyword", "    for"), tok("variable", " _"), tok("punctuation", ", "), tok("variable", "line "), tok("punctuation", ":= "), tok("keyword", "range"), tok("variable", " lines "), tok("punctuation", "{")},
+	{tok("variable", "        fmt"), tok("punctuation", "."), tok("function", "Fprintln"), tok("punctuation", "("), tok("variable", "w"), tok("punctuation", ", "), tok("variable", "lnie"), tok("punctuation", ", "), tok("string", `"\n"`), tok("punctuation", ")")},
+	{tok("punctuation", "    }")},
+	{tok("keyword", "    return"), tok("constant", " nil")},
+	{tok("punctuation", "}")},
+}
+
+// lineText returns the plain text of source row i.
+func lineText(i int) string {
+	var b strings.Builder
+	for _, s := range source[i] {
+		b.WriteString(s.Text)
+	}
+	return b.String()
+}
+
+// editor draws an editor with the source rows, returning the x of the first
+// text column and the y of each row.
+func (b *builder) editor(x, y, w int, rows []int, active int) (int, []int) {
+	b.rect(x, y, w, lineH*len(rows)+16, "editor.background", "")
+	textX := x + 48
+	var ys []int
+	for n, i := range rows {
+		ry := y + 8 + n*lineH
+		ys = append(ys, ry)
+		number := "editorLineNumber.foreground"
+		if i == active {
+			b.rect(x, ry, w, lineH, "editor.lineHighlightBackground", "")
+			number = "editorLineNumber.activeForeground"
+		}
+		b.text(x+12, ry, span(number, fmt.Sprintf("%3d", i+1)))
+		b.text(textX, ry, source[i]...)
+	}
+	return textX, ys
+}
+
+// highlight paints a background behind the nth occurrence of word in
+// source row i, drawn at row y.
+func (b *builder) highlight(textX, y, i int, word string, nth int, fill string) {
+	text, at := lineText(i), -1
+	for n := 0; n <= nth; n++ {
+		k := strings.Index(text[at+1:], word)
+		if k < 0 {
+			return
+		}
+		at += k + 1
+	}
+	b.rect(textX+cols(at), y, cols(len(word)), lineH, fill, "")
+}
+
+// findWidget draws the find widget with its top-left corner at x, y.
+func (b *builder) findWidget(x, y int) {
+	b.rect(x, y, 300, 34, "editorWidget.background", "editorWidget.border")
+	b.rect(x, y, 3, 34, "editorWidget.resizeBorder", "")
+	b.rect(x+12, y+6, 170, 22, "input.background", "input.border")
+	b.text(x+18, y+8, span("input.foreground", "line"))
+	b.rect(x+150, y+9, 26, 16, "inputOption.activeBackground", "inputOption.activeBorder")
+	b.text(x+155, y+8, span("inputOption.activeForeground", "Aa"))
+	b.text(x+192, y+8, span("editorWidget.foreground", "2 of 3"))
+	b.text(x+250, y+8, span("icon.foreground", "↑ ↓ ×"))
+}
+
+func findScene() Scene {
+	b := &builder{Scene{Name: "find", Title: "Find widget and match highlights", Width: 640, Height: 160}}
+	textX, ys := b.editor(0, 0, 640, []int{0, 1, 2, 3, 4, 5, 6}, 2)
+	b.highlight(textX, ys[1], 1, "line", 0, "editor.findMatchHighlightBackground")
+	b.highlight(textX, ys[2], 2, "line", 0, "editor.findMatchBackground")
+	b.highlight(textX, ys[2], 2, "line", 1, "editor.findMatchHighlightBackground")
+	b.findWidget(326, 0)
+	b.rect(626, 0, 14, 160, "editorOverviewRuler.background", "editorOverviewRuler.border")
+	b.rect(628, 24, 10, 3, "editorOverviewRuler.findMatchForeground", "")
+	b.rect(628, 44, 10, 3, "editorOverviewRuler.findMatchForeground", "")
+	return b.Scene
+}
+
+func widgetsScene() Scene {
+	b := &builder{Scene{Name: "widgets", Title: "Hover, suggest and find widgets side by side", Width: 960, Height: 150}}
+	b.rect(0, 0, 960, 150, "editor.background", "")
+
+	b.rect(10, 10, 300, 96, "editorHoverWidget.background", "editorHoverWidget.border")
+	b.text(20, 16, tok("keyword", "func"), tok("function", " Fprintln"), tok("punctuation", "("), tok("variable", "w io"), tok("punctuation", "."), tok("type", "Writer"), tok("punctuation", ", ...)"))
+	b.text(20, 40, span("editorHoverWidget.foreground", "Fprintln formats using the default"))
+	b.text(20, 58, span("editorHoverWidget.foreground", "formats and writes to "), span("editorHoverWidget.highlightForeground", "w"), span("editorHoverWidget.foreground", "."))
+	b.rect(11, 82, 298, 23, "editorHoverWidget.statusBarBackground", "")
+	b.text(20, 84, span("textLink.foreground", "Go to definition"))
+
+	b.rect(330, 10, 300, 96, "editorSuggestWidget.background", "editorSuggestWidget.border")
+	items := []struct{ match, rest string }{{"Fpr", "intf"}, {"Fpr", "intln"}, {"F", "ormat"}, {"Err", "orf"}}
+	for i, it := range items {
+		y := 14 + i*22
+		fg, hl := "editorSuggestWidget.foreground", "editorSuggestWidget.highlightForeground"
+		if i == 1 {
+			b.rect(331, y, 298, 22, "editorSuggestWidget.selectedBackground", "")
+			fg, hl = "editorSuggestWidget.selectedForeground", "editorSuggestWidget.focusHighlightForeground"
+			b.text(340, y+2, span("editorSuggestWidget.selectedIconForeground", "ƒ"))
+		} else {
+			b.text(340, y+2, span("symbolIcon.functionForeground", "ƒ"))
+		}
+		b.text(356, y+2, span(hl, it.match), span(fg, it.rest))
+	}
+
+	b.findWidget(650, 10)
+	return b.Scene
+}
+
+func quickFixScene() Scene {
+	b := &builder{Scene{Name: "quickfix", Title: "Light bulb and marker navigation (F8)", Width: 640, Height: 330}}
+	b.rect(0, 0, 640, 330, "editor.background", "")
+	textX, ys := b.editor(0, 0, 640, []int{2, 3}, 3)
+	b.text(2, ys[1], span("editorLightBulbAutoFix.foreground", "●"))
+	at := strings.Index(lineText(3), "lnie")
+	b.rect(textX+cols(at), ys[1]+lineH-2, cols(4), 2, "editorError.foreground", "")
+
+	y := ys[1] + lineH
+	y = b.markerZone(y, "Error", "undefined: lnie", "compiler")
+	_, ys = b.editor(0, y, 640, []int{4, 5}, -1)
+	b.text(2, ys[1], span("editorLightBulb.foreground", "●"))
+	y = b.markerZone(ys[1]+lineH, "Warning", "unreachable code", "vet")
+	_, ys = b.editor(0, y, 640, []int{6}, -1)
+	b.text(2, ys[0], span("editorLightBulbAi.foreground", "✦"))
+	b.markerZone(ys[0]+lineH, "Info", "render can be simplified", "gopls")
+	return b.Scene
+}
+
+// markerZone draws the marker navigation zone for a problem of the given
+// severity below row y and returns the y below it.
+func (b *builder) markerZone(y int, severity, message, source string) int {
+	frame := "editorMarkerNavigation" + severity + ".background"
+	b.rect(0, y, 640, 2, frame, "")
+	b.rect(0, y+2, 640, 22, "editorMarkerNavigation.background", "")
+	b.rect(0, y+2, 640, 22, "editorMarkerNavigation"+severity+".headerBackground", "")
+	b.text(12, y+4, span("peekViewTitleLabel.foreground", "main.go"), span("peekViewTitleDescription.foreground", "  cmd/render  1 of 3 problems"))
+	b.rect(0, y+24, 640, 28, "editorMarkerNavigation.background", "")
+	b.text(12, y+29, span("editor"+severity+".foreground", strings.ToLower(severity)+"  "), span("editor.foreground", message), span("descriptionForeground", "  "+source))
+	b.rect(0, y+52, 640, 2, frame, "")
+	return y + 54
+}
+
+func treeScene() Scene {
+	b := &builder{Scene{Name: "tree", Title: "Explorer tree with indent guides and filter matches", Width: 300, Height: 240}}
+	b.rect(0, 0, 300, 240, "sideBar.background", "sideBar.border")
+	b.rect(0, 0, 300, 22, "sideBarSectionHeader.background", "sideBarSectionHeader.border")
+	b.text(10, 2, span("sideBarSectionHeader.foreground", "CAFFEINATED-RUST"))
+
+	rows := []struct {
+		depth       int
+		name, match string
+	}{
+		{0, "cmd", ""},
+		{1, "caffeinated", ""},
+		{2, "colors.go", "col"},
+		{2, "main.go", ""},
+		{0, "internal", ""},
+		{1, "colorreg", "col"},
+		{2, "colorreg.go", "col"},
+		{2, "defaults.json", ""},
+		{1, "colors", "col"},
+	}
+	const selected, indent = 3, 14
+	for i, r := range rows {
+		y := 26 + i*22
+		fg := "sideBar.foreground"
+		if i == selected {
+			b.rect(0, y, 300, 22, "list.activeSelectionBackground", "list.focusOutline")
+			fg = "list.activeSelectionForeground"
+		}
+		x := 12 + r.depth*indent
+		if r.match != "" {
+			b.rect(x+cols(2), y+2, cols(len(r.match)), lineH, "list.filterMatchBackground", "")
+			b.text(x, y+2, span("icon.foreground", "▸ "), span("list.highlightForeground", r.match), span(fg, r.name[len(r.match):]))
+		} else {
+			b.text(x, y+2, span("icon.foreground", "▸ "), span(fg, r.name))
+		}
+	}
+	// Guides run down the children of each folder; the one enclosing the
+	// selection is the active guide.
+	guide := func(depth, from, to int, active bool) {
+		stroke := "tree.inactiveIndentGuidesStroke"
+		if active {
+			stroke = "tree.indentGuidesStroke"
+		}
+		b.rect(16+depth*indent, 26+from*22, 1, (to-from+1)*22, stroke, "")
+	}
+	guide(0, 1, 3, false)
+	guide(1, 2, 3, true)
+	guide(0, 5, 8, false)
+	guide(1, 6, 7, false)
+	return b.Scene
+}
@@ -0,0 +1,200 @@
+// Package palette names the colour roles of a theme variant so that
+// generators and exporters can refer to "keyword" or "error" rather than to
+// workbench ids and TextMate scopes.
+package palette
+
+import (
+	"fmt"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// Role is a named colour of the palette.
+type Role string
+
+// Workbench roles.
+const (
+	Background Role = "background"
+	Surface    Role = "surface"
+	Border     Role = "border"
+	Foreground Role = "foreground"
+	Muted      Role = "muted"
+	Accent     Role = "accent"
+	Selection  Role = "selection"
+	Error      Role = "error"
+	Warning    Role = "warning"
+	Info       Role = "info"
+	Added      Role = "added"
+	Modified   Role = "modified"
+	Deleted    Role = "deleted"
+	FindMatch  Role = "findMatch"
+)
+
+// Syntax roles.
+const (
+	Comment     Role = "comment"
+	String      Role = "string"
+	Keyword     Role = "keyword"
+	Function    Role = "function"
+	Type        Role = "type"
+	Constant    Role = "constant"
+	Variable    Role = "variable"
+	Property    Role = "property"
+	Tag         Role = "tag"
+	Punctuation Role = "punctuation"
+)
+
+// Kind groups roles by where they are used.
+type Kind int
+
+const (
+	UI Kind = iota
+	Diagnostic
+	Git
+	Syntax
+)
+
+var kindNames = [...]string{"ui", "diagnostic", "git", "syntax"}
+
+func (k Kind) String() string { return kindNames[k] }
+
+// Definition says where a role's colour is read from: a workbench colour id,
+// or the scope a representative token carries. Translucent workbench
+// colours are flattened onto the editor background unless Hue is set, in
+// which case only their alpha is dropped.
+type Definition struct {
+	Role    Role
+	Kind    Kind
+	ColorID string
+	Scope   string
+	Hue     bool
+}
+
+// Definitions lists every role in canonical order.
+var Definitions = []Definition{
+	{Role: Background, Kind: UI, ColorID: "editor.background"},
+	{Role: Surface, Kind: UI, ColorID: "editorHoverWidget.background"},
+	{Role: Border, Kind: UI, ColorID: "widget.border"},
+	{Role: Foreground, Kind: UI, ColorID: "editor.foreground"},
+	{Role: Muted, Kind: UI, ColorID: "editorLineNumber.foreground"},
+	{Role: Accent, Kind: UI, ColorID: "activityBar.activeBorder"},
+	{Role: Selection, Kind: UI, ColorID: "list.activeSelectionBackground"},
+	{Role: Error, Kind: Diagnostic, ColorID: "editorError.foreground"},
+	{Role: Warning, Kind: Diagnostic, ColorID: "editorWarning.foreground"},
+	{Role: Info, Kind: Diagnostic, ColorID: "editorInfo.foreground"},
+	{Role: FindMatch, Kind: Diagnostic, ColorID: "editorOverviewRuler.findMatchForeground", Hue: true},
+	{Role: Added, Kind: Git, ColorID: "editorGutter.addedBackground"},
+	{Role: Modified, Kind: Git, ColorID: "editorGutter.modifiedBackground"},
+	{Role: Deleted, Kind: Git, ColorID: "editorGutter.deletedBackground"},
+	{Role: Comment, Kind: Syntax, Scope: "comment.line.double-slash"},
+	{Role: String, Kind: Syntax, Scope: "string.quoted.double"},
+	{Role: Keyword, Kind: Syntax, Scope: "keyword.function"},
+	{Role: Function, Kind: Syntax, Scope: "entity.name.function.go"},
+	{Role: Type, Kind: Syntax, Scope: "support.type"},
+	{Role: Constant, Kind: Syntax, Scope: "constant.numeric"},
+	{Role: Variable, Kind: Syntax, Scope: "variable.other.readwrite"},
+	{Role: Property, Kind: Syntax, Scope: "variable.other.property"},
+	{Role: Tag, Kind: Syntax, Scope: "entity.name.tag"},
+	{Role: Punctuation, Kind: Syntax, Scope: "punctuation.separator"},
+}
+
+// ANSINames are the terminal.ansi* suffixes in colour index order.
+var ANSINames = [16]string{
+	"Black", "Red", "Green", "Yellow", "Blue", "Magenta", "Cyan", "White",
+	"BrightBlack", "BrightRed", "BrightGreen", "BrightYellow",
+	"BrightBlue", "BrightMagenta", "BrightCyan", "BrightWhite",
+}
+
+// Palette is the resolved set of roles of one theme.
+type Palette struct {
+	Name   string
+	colors map[Role]colors.Color
+	styles map[Role]string
+	ansi   [16]colors.Color
+}
+
+// New resolves every role of t.
+func New(t *theme.Theme) (*Palette, error) {
+	p := &Palette{Name: t.Name, colors: map[Role]colors.Color{}, styles: map[Role]string{}}
+	bg, ok := t.Color("editor.background")
+	if !ok {
+		return nil, fmt.Errorf("palette: %s has no editor.background", t.Name)
+	}
+	for _, d := range Definitions {
+		var hex string
+		if d.ColorID != "" {
+			hex, ok = t.Colors.Get(d.ColorID)
+			if !ok {
+				return nil, fmt.Errorf("palette: %s has no %s for role %s", t.Name, d.ColorID, d.Role)
+			}
+		} else {
+			s := t.Match(d.Scope)
+			hex = s.Foreground.Value
+			p.styles[d.Role] = s.FontStyle.Value
+		}
+		c, err := colors.ParseHex(hex)
+		if err != nil {
+			return nil, fmt.Errorf("palette: role %s: %w", d.Role, err)
+		}
+		switch {
+		case d.Hue:
+			c = c.Opaque()
+		case d.Role != Selection && c.A != 0xff:
+			c = c.Over(bg)
+		}
+		p.colors[d.Role] = c
+	}
+	for i, name := range ANSINames {
+		c, ok := t.Color("terminal.ansi" + name)
+		if !ok {
+			return nil, fmt.Errorf("palette: %s has no terminal.ansi%s", t.Name, name)
+		}
+		p.ansi[i] = c
+	}
+	return p, nil
+}
+
+// Color returns the colour of r. Selection keeps its alpha; other roles are
+// opaque as described on Definition.
+func (p *Palette) Color(r Role) colors.Color {
+	c, ok := p.colors[r]
+	if !ok {
+		panic("palette: unknown role " + string(r))
+	}
+	return c
+}
+
+// Hex returns the colour of r formatted as #RRGGBB.
+func (p *Palette) Hex(r Role) string {
+	return p.Color(r).Opaque().Hex()
+}
+
+// FontStyle returns the fontStyle of a syntax role.
+func (p *Palette) FontStyle(r Role) string {
+	return p.styles[r]
+}
+
+// ANSI returns the 16 terminal colours.
+func (p *Palette) ANSI() [16]colors.Color {
+	return p.ansi
+}
+
+// Roles returns every role in canonical order.
+func Roles() []Role {
+	rs := make([]Role, len(Definitions))
+	for i, d := range Definitions {
+		rs[i] = d.Role
+	}
+	return rs
+}
+
+// Lookup returns the definition of r.
+func Lookup(r Role) (Definition, bool) {
+	for _, d := range Definitions {
+		if d.Role == r {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
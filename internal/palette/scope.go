@@ -0,0 +1,87 @@
+package palette
+
+import (
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// scopeRoles classifies TextMate scopes into syntax roles. Keys are theme
+// selectors, so a parent scope narrows an entry to one language; the most
+// specific matching selector wins, as in the theme.
+var scopeRoles = map[string]Role{
+	"comment":                        Comment,
+	"punctuation.definition.comment": Comment,
+	"string":                         String,
+	"punctuation.definition.string":  String,
+	"constant.other.symbol":          String,
+	"keyword":                        Keyword,
+	"storage":                        Keyword,
+	"keyword.other.unit":             Constant,
+	"entity.name.function":           Function,
+	"support.function":               Function,
+	"meta.function-call":             Function,
+	"entity.name.type":               Type,
+	"entity.name.class":              Type,
+	"entity.other.inherited-class":   Type,
+	"support.type":                   Type,
+	"support.class":                  Type,
+	"support.constant":               Constant,
+	"constant":                       Constant,
+	"variable":                       Variable,
+	"variable.other.property":        Property,
+	"variable.other.member":          Property,
+	"variable.other.object.property": Property,
+	"variable.object.property":       Property,
+	"support.type.property-name":     Property,
+	"support.variable.property":      Property,
+	"meta.object-literal.key":        Property,
+	"entity.name.tag":                Tag,
+	"entity.other.attribute-name":    Property,
+	"punctuation":                    Punctuation,
+	"punctuation.decorator":          Function,
+	"entity.other.document":          Punctuation,
+
+	// The web grammars scope type annotations, assignments and selector
+	// combinators as operators, so the theme paints them as punctuation.
+	"source.ts keyword.operator":  Punctuation,
+	"source.tsx keyword.operator": Punctuation,
+	"source.css keyword.operator": Punctuation,
+}
+
+// ScopeRole returns the syntax role a scope belongs to. The scope may be a
+// stack of scopes separated by spaces, outermost first, such as the
+// selector "source.ts keyword.operator".
+func ScopeRole(scope string) (Role, bool) {
+	stack := strings.Fields(scope)
+	best, role := [2]int{-1, -1}, Role("")
+	for sel, r := range scopeRoles {
+		score, ok := theme.MatchSelector(sel, stack)
+		if ok && (score[0] > best[0] || score[0] == best[0] && score[1] > best[1]) {
+			best, role = score, r
+		}
+	}
+	return role, best[0] >= 0
+}
+
+// Definitional reports whether scope names a declaration rather than a use,
+// such as a function or class name at its definition site.
+func Definitional(scope string) bool {
+	for _, prefix := range []string{"entity.name.function", "entity.name.type", "entity.name.class"} {
+		if theme.ScopePrefix(prefix, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// SyntaxRoles returns the syntax roles in canonical order.
+func SyntaxRoles() []Role {
+	var rs []Role
+	for _, d := range Definitions {
+		if d.Kind == Syntax {
+			rs = append(rs, d.Role)
+		}
+	}
+	return rs
+}
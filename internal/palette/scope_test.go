@@ -0,0 +1,28 @@
+package palette
+
+import "testing"
+
+func TestScopeRole(t *testing.T) {
+	for _, tt := range []struct {
+		scope string
+		want  Role
+	}{
+		{"comment.block.html", Comment},
+		{"keyword.control.go", Keyword},
+		{"keyword.operator.comparison.go", Keyword},
+		{"source.ts keyword.operator.assignment.ts", Punctuation},
+		{"source.tsx meta.tag.tsx keyword.operator.assignment.tsx", Punctuation},
+		{"source.tsx keyword.operator", Punctuation},
+		{"source.ts keyword.control.flow.ts", Keyword},
+		{"keyword.other.unit.px.css", Constant},
+		{"punctuation.decorator.ts", Function},
+		{"variable.other.property.go", Property},
+	} {
+		if got, ok := ScopeRole(tt.scope); !ok || got != tt.want {
+			t.Errorf("ScopeRole(%q) = %s, %v; want %s", tt.scope, got, ok, tt.want)
+		}
+	}
+	if role, ok := ScopeRole("markup.heading"); ok {
+		t.Errorf("ScopeRole(markup.heading) = %s, want no role", role)
+	}
+}
@@ -0,0 +1,162 @@
+// Package profile builds the user settings the theme relies on but cannot
+// set itself, and packages them as a VS Code profile export.
+package profile
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/jsonschema"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+//go:embed code-profile.schema.json
+var schemaJSON []byte
+
+// Settings is the settings.json snippet. Field order is the order the
+// snippet is written in.
+type Settings struct {
+	ColorTheme            string            `json:"workbench.colorTheme"`
+	PreferredDark         string            `json:"workbench.preferredDarkColorTheme,omitempty"`
+	PreferredLight        string            `json:"workbench.preferredLightColorTheme,omitempty"`
+	AutoDetectColorScheme bool              `json:"window.autoDetectColorScheme"`
+	SemanticHighlighting  *bool             `json:"editor.semanticHighlighting.enabled,omitempty"`
+	BracketPairColors     bool              `json:"editor.bracketPairColorization.enabled"`
+	MinimumContrastRatio  float64           `json:"terminal.integrated.minimumContrastRatio"`
+	GoCoverageDecorator   CoverageDecorator `json:"go.coverageDecorator"`
+}
+
+// CoverageDecorator is the vscode-go go.coverageDecorator setting.
+type CoverageDecorator struct {
+	Type                    string `json:"type"`
+	CoveredHighlightColor   string `json:"coveredHighlightColor"`
+	UncoveredHighlightColor string `json:"uncoveredHighlightColor"`
+	CoveredBorderColor      string `json:"coveredBorderColor"`
+	UncoveredBorderColor    string `json:"uncoveredBorderColor"`
+}
+
+// coverageAlpha keeps coverage highlights in the same translucency band as
+// the theme's other line backgrounds.
+const coverageAlpha = 0x22
+
+// NewSettings derives the settings for the dark variant and, when there is
+// one, the light variant that window.autoDetectColorScheme switches to.
+func NewSettings(dark theme.Variant, light *theme.Variant) (*Settings, error) {
+	p, err := palette.New(dark.Theme)
+	if err != nil {
+		return nil, err
+	}
+	s := &Settings{
+		ColorTheme: dark.Label,
+		// Left out unless the theme sets it, so that VS Code's default
+		// keeps the semanticTokenScopes fallbacks working.
+		SemanticHighlighting: dark.Theme.SemanticHighlighting,
+		MinimumContrastRatio: minimumContrast(p),
+	}
+	if _, ok := dark.Theme.Colors.Get("editorBracketHighlight.foreground1"); ok {
+		s.BracketPairColors = true
+	}
+	if light != nil {
+		s.AutoDetectColorScheme = true
+		s.PreferredDark = dark.Label
+		s.PreferredLight = light.Label
+	}
+	added, deleted := p.Color(palette.Added), p.Color(palette.Deleted)
+	s.GoCoverageDecorator = CoverageDecorator{
+		Type:                    "highlight",
+		CoveredHighlightColor:   added.WithAlpha(coverageAlpha).CSS(),
+		UncoveredHighlightColor: deleted.WithAlpha(coverageAlpha).CSS(),
+		CoveredBorderColor:      added.CSS(),
+		UncoveredBorderColor:    deleted.CSS(),
+	}
+	return s, nil
+}
+
+// minimumContrast returns the highest terminal.integrated.minimumContrastRatio
+// that leaves every ANSI colour of the palette untouched on the terminal
+// background. VS Code's default of 4.5 would otherwise shift the darker
+// accents such as rust.
+func minimumContrast(p *palette.Palette) float64 {
+	ansi := p.ANSI()
+	bg := p.Color(palette.Background)
+	lowest := 21.0
+	for i, c := range ansi {
+		if i == 0 {
+			continue
+		}
+		lowest = math.Min(lowest, colors.Contrast(c.Over(bg), bg))
+	}
+	return math.Floor(lowest*10) / 10
+}
+
+// JSON renders the settings as an indented settings.json snippet.
+func (s *Settings) JSON() ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Profile is a .code-profile export. VS Code stores each resource as a JSON
+// document embedded in a string.
+type Profile struct {
+	Name       string `json:"name"`
+	Settings   string `json:"settings"`
+	Extensions string `json:"extensions"`
+}
+
+type extension struct {
+	Identifier struct {
+		ID string `json:"id"`
+	} `json:"identifier"`
+	DisplayName string `json:"displayName"`
+}
+
+// New packages settings into a profile that installs the extension described
+// by m.
+func New(name string, m *manifest.Manifest, s *Settings) (*Profile, error) {
+	settings, err := s.JSON()
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := json.Marshal(map[string]string{"settings": string(settings)})
+	if err != nil {
+		return nil, err
+	}
+	var ext extension
+	ext.Identifier.ID = strings.ToLower(m.ExtensionID())
+	ext.DisplayName = m.DisplayName
+	exts, err := json.Marshal([]extension{ext})
+	if err != nil {
+		return nil, err
+	}
+	return &Profile{Name: name, Settings: string(wrapped), Extensions: string(exts)}, nil
+}
+
+// JSON renders the profile export and validates it against the bundled
+// schema.
+func (p *Profile) JSON() ([]byte, error) {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := Validate(b); err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// Validate checks a .code-profile document against the bundled schema.
+func Validate(doc []byte) error {
+	schema, err := jsonschema.Parse(schemaJSON)
+	if err != nil {
+		return err
+	}
+	return schema.Validate(doc)
+}
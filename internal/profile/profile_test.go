@@ -0,0 +1,83 @@
+package profile
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func generate(t *testing.T, dark theme.Variant, light *theme.Variant) *Profile {
+	t.Helper()
+	m, err := manifest.Load("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSettings(dark, light)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(m.DisplayName, m, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// TestSchema validates the profile generated for every variant against the
+// bundled schema, with and without a light variant to switch to.
+func TestSchema(t *testing.T) {
+	vs, err := theme.LoadVariants("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range vs {
+		for _, light := range []*theme.Variant{nil, &vs[0]} {
+			doc, err := generate(t, vs[i], light).JSON()
+			if err != nil {
+				t.Fatalf("%s: %v", vs[i].ID, err)
+			}
+			if err := Validate(doc); err != nil {
+				t.Errorf("%s: %v", vs[i].ID, err)
+			}
+		}
+	}
+}
+
+// TestSchemaRejects checks that the schema catches a broken profile: the
+// inner settings and the extension list are JSON embedded in strings.
+func TestSchemaRejects(t *testing.T) {
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name, want string
+		edit       func(p *Profile)
+	}{
+		{"no name", "$.name", func(p *Profile) { p.Name = " " }},
+		{"no colour theme", "workbench.colorTheme", func(p *Profile) {
+			p.Settings = `{"settings": "{\"editor.bracketPairColorization.enabled\": true}"}`
+		}},
+		{"contrast out of range", "maximum", func(p *Profile) {
+			p.Settings = `{"settings": "{\"workbench.colorTheme\": \"x\", \"terminal.integrated.minimumContrastRatio\": 30}"}`
+		}},
+		{"upper-case extension id", "does not match", func(p *Profile) {
+			p.Extensions = strings.Replace(p.Extensions, `"id":"`, `"id":"X`, 1)
+		}},
+		{"no extensions", "at least 1", func(p *Profile) { p.Extensions = "[]" }},
+	} {
+		p := generate(t, v, nil)
+		tc.edit(p)
+		doc, err := json.Marshal(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Validate(doc)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Validate = %v, want an error mentioning %s", tc.name, err, tc.want)
+		}
+	}
+}
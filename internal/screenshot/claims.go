@@ -0,0 +1,50 @@
+package screenshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+)
+
+// Claims maps each screenshot, by repository relative path, to the variant
+// it was taken with and the roles it is meant to show.
+type Claims map[string]Claim
+
+// Claim describes one screenshot.
+type Claim struct {
+	Variant string         `json:"variant,omitempty"`
+	Roles   []palette.Role `json:"roles"`
+}
+
+// LoadClaims reads a claims file and checks that every role exists.
+func LoadClaims(path string) (Claims, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Claims
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for img, claim := range c {
+		for _, r := range claim.Roles {
+			if _, ok := palette.Lookup(r); !ok {
+				return nil, fmt.Errorf("%s: %s claims unknown role %q", path, img, r)
+			}
+		}
+	}
+	return c, nil
+}
+
+// Images returns the screenshot paths in sorted order.
+func (c Claims) Images() []string {
+	imgs := make([]string, 0, len(c))
+	for img := range c {
+		imgs = append(imgs, img)
+	}
+	sort.Strings(imgs)
+	return imgs
+}
@@ -0,0 +1,253 @@
+// Package screenshot checks that committed screenshots still show the
+// theme's current colours.
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// Options tunes the analysis.
+type Options struct {
+	// Tolerance is the OKLab distance within which an image colour counts
+	// as a theme colour. Screenshots pass through the display's colour
+	// profile, which shifts colours by a few units.
+	Tolerance float64
+	// Dominant is the share of non-background pixels a colour cluster needs
+	// before it is considered part of the picture rather than noise.
+	Dominant float64
+}
+
+// DefaultOptions suits screenshots taken on a colour managed display.
+var DefaultOptions = Options{Tolerance: 0.05, Dominant: 0.005}
+
+// clusterRadius merges near-identical image colours before dominance is
+// judged, so that dithering and compression noise do not split a colour.
+const clusterRadius = 0.02
+
+// Swatch is a distinct colour the theme can paint, with the ids and scopes
+// that produce it and the palette roles it is the colour of. Translucent
+// colours are flattened onto the background.
+type Swatch struct {
+	Color   colors.Color
+	Sources []string
+	Roles   []palette.Role
+}
+
+// Cluster is a group of similar image colours.
+type Cluster struct {
+	Color  colors.Color
+	Pixels int
+	Share  float64
+	// Match is the swatch the cluster was attributed to, if any.
+	Match *Swatch
+	// Antialias is set when the cluster lies between the background and
+	// Match rather than on Match itself.
+	Antialias bool
+}
+
+// Report is the result of analysing one screenshot.
+type Report struct {
+	Clusters []Cluster
+	// Stale lists dominant clusters that match no theme colour.
+	Stale []Cluster
+	// Missing lists claimed roles whose colour the image does not contain.
+	Missing []palette.Role
+}
+
+// OK reports whether the screenshot is consistent with the theme.
+func (r *Report) OK() bool {
+	return len(r.Stale) == 0 && len(r.Missing) == 0
+}
+
+// Swatches lists every opaque colour t can paint.
+func Swatches(t *theme.Theme) []Swatch {
+	bg, _ := t.Color("editor.background")
+	index := map[string]int{}
+	var out []Swatch
+	add := func(value, source string) {
+		c, err := colors.ParseHex(value)
+		if err != nil {
+			return
+		}
+		c = c.Over(bg)
+		key := c.Hex()
+		if i, ok := index[key]; ok {
+			out[i].Sources = append(out[i].Sources, source)
+			return
+		}
+		index[key] = len(out)
+		out = append(out, Swatch{Color: c, Sources: []string{source}})
+	}
+	for _, id := range t.Colors.Keys() {
+		v, _ := t.Colors.Get(id)
+		add(v, id)
+	}
+	for _, r := range t.TokenColors {
+		for _, s := range r.Scope {
+			if r.Settings.Foreground != "" {
+				add(r.Settings.Foreground, s)
+			}
+			if r.Settings.Background != "" {
+				add(r.Settings.Background, s+" (background)")
+			}
+		}
+	}
+	return out
+}
+
+// Analyze compares img against the colours of t. claims lists the roles the
+// screenshot is meant to show.
+func Analyze(img image.Image, t *theme.Theme, claims []palette.Role, o Options) (*Report, error) {
+	p, err := palette.New(t)
+	if err != nil {
+		return nil, err
+	}
+	bg := p.Color(palette.Background)
+	swatches := Swatches(t)
+	for i := range swatches {
+		for _, role := range palette.Roles() {
+			if p.Color(role).Over(bg) == swatches[i].Color {
+				swatches[i].Roles = append(swatches[i].Roles, role)
+			}
+		}
+	}
+
+	counts := map[colors.Color]int{}
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			counts[colors.FromImage(img.At(x, y)).Opaque()]++
+		}
+	}
+
+	clusters, foreground := cluster(counts, bg, o.Tolerance)
+	background := b.Dx()*b.Dy() - foreground
+	r := &Report{}
+	for _, c := range clusters {
+		c.Share = float64(c.Pixels) / float64(max(foreground, 1))
+		if c.Share < o.Dominant {
+			continue
+		}
+		c.Match, c.Antialias = attribute(c.Color, bg, swatches, o.Tolerance)
+		if c.Match == nil {
+			r.Stale = append(r.Stale, c)
+		}
+		r.Clusters = append(r.Clusters, c)
+	}
+
+	for _, role := range claims {
+		want := p.Color(role)
+		found := background > 0 && colors.Distance(want.Over(bg), bg) <= o.Tolerance/2
+		for _, c := range r.Clusters {
+			if c.Match != nil && !c.Antialias && c.Match.Color == want.Over(bg) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.Missing = append(r.Missing, role)
+		}
+	}
+	return r, nil
+}
+
+// cluster groups image colours greedily around the most frequent ones.
+// Pixels close to the background are left out and the number of remaining
+// foreground pixels is returned alongside the clusters.
+func cluster(counts map[colors.Color]int, bg colors.Color, tolerance float64) ([]Cluster, int) {
+	type entry struct {
+		c colors.Color
+		n int
+	}
+	entries := make([]entry, 0, len(counts))
+	for c, n := range counts {
+		entries = append(entries, entry{c, n})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].n != entries[j].n {
+			return entries[i].n > entries[j].n
+		}
+		return entries[i].c.Hex() < entries[j].c.Hex()
+	})
+
+	var clusters []Cluster
+	foreground := 0
+	for _, e := range entries {
+		if colors.Distance(e.c, bg) <= tolerance/2 {
+			continue
+		}
+		foreground += e.n
+		joined := false
+		for i := range clusters {
+			if colors.Distance(clusters[i].Color, e.c) <= clusterRadius {
+				clusters[i].Pixels += e.n
+				joined = true
+				break
+			}
+		}
+		if !joined {
+			clusters = append(clusters, Cluster{Color: e.c, Pixels: e.n})
+		}
+	}
+	return clusters, foreground
+}
+
+// attribute finds the swatch that explains c: the nearest swatch within
+// tolerance, or failing that a swatch c is an antialiased blend of with the
+// background.
+func attribute(c, bg colors.Color, swatches []Swatch, tolerance float64) (*Swatch, bool) {
+	best, bestDist := -1, math.Inf(1)
+	for i, s := range swatches {
+		if d := colors.Distance(c, s.Color); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	if bestDist <= tolerance {
+		return &swatches[best], false
+	}
+	best, bestDist = -1, math.Inf(1)
+	for i, s := range swatches {
+		if d := blendDistance(c, bg, s.Color); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	if bestDist <= tolerance {
+		return &swatches[best], true
+	}
+	return nil, false
+}
+
+// blendDistance is the OKLab distance from c to the nearest sRGB blend of bg
+// and fg, which is what antialiased glyph edges are made of.
+func blendDistance(c, bg, fg colors.Color) float64 {
+	const steps = 32
+	best := math.Inf(1)
+	for i := 1; i < steps; i++ {
+		blend := fg.WithAlpha(uint8(255 * i / steps)).Over(bg)
+		best = math.Min(best, colors.Distance(c, blend))
+	}
+	return best
+}
+
+// String formats a cluster for reports.
+func (c Cluster) String() string {
+	s := fmt.Sprintf("%s %5.1f%%", c.Color.Hex(), c.Share*100)
+	switch {
+	case c.Match == nil:
+		s += "  not in theme"
+	case c.Antialias:
+		s += fmt.Sprintf("  antialiased %s", c.Match.Color.Hex())
+	case len(c.Match.Roles) > 0:
+		s += fmt.Sprintf("  = %s %v", c.Match.Color.Hex(), c.Match.Roles)
+	default:
+		s += fmt.Sprintf("  = %s (%s)", c.Match.Color.Hex(), c.Match.Sources[0])
+	}
+	return s
+}
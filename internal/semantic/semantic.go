@@ -0,0 +1,84 @@
+// Package semantic generates the semanticTokenScopes the extension
+// contributes. When a language server sends a semantic token the theme has
+// no semanticTokenColors for, VS Code styles it like the TextMate scopes
+// mapped to its selector. Its built-in defaults (entity.name.type for
+// "type", support.function for "function.defaultLibrary") are scopes this
+// theme leaves unstyled, and the custom types of gopls, Pylance and
+// rust-analyzer have no default at all; either way the token comes out in
+// the plain foreground where the TextMate grammar would have coloured it.
+package semantic
+
+import (
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+)
+
+// Language is the mapping for one language: the root scope of its grammar
+// and its selectors.
+type Language struct {
+	ID       string
+	Root     string
+	Mappings []Mapping
+}
+
+// Mapping maps a semantic token selector (a type with optional modifiers,
+// "type.defaultLibrary") to a TextMate scope and names the role the scope
+// must be painted in, which the tests check against the default variant.
+type Mapping struct {
+	Selector string
+	Scope    string
+	Role     palette.Role
+}
+
+// Languages lists the mapped languages.
+var Languages = []Language{
+	{ID: "go", Root: "source.go", Mappings: []Mapping{
+		{"type", "support.type.go", palette.Type},
+		{"type.defaultLibrary", "support.type.builtin.go", palette.Type},
+		{"typeParameter", "support.type.parameter.go", palette.Type},
+		{"function", "entity.name.function.go", palette.Function},
+		{"function.defaultLibrary", "entity.name.function.go", palette.Function},
+		{"method", "entity.name.function.go", palette.Function},
+		{"string.format", "constant.other.placeholder.go", palette.Constant},
+	}},
+	{ID: "python", Root: "source.python", Mappings: []Mapping{
+		// The theme paints Python class names in the function colour.
+		{"class", "entity.name.type.class.python", palette.Function},
+		{"class.builtin", "support.type.python", palette.Type},
+		{"class.typeHint", "support.type.python", palette.Type},
+		{"function", "entity.name.function.python", palette.Function},
+		{"function.builtin", "entity.name.function.python", palette.Function},
+		{"method", "entity.name.function.python", palette.Function},
+		{"magicFunction", "entity.name.function.python", palette.Function},
+		{"decorator", "punctuation.decorator.python", palette.Function},
+		{"selfParameter", "variable.language.special.self.python", palette.Property},
+		{"clsParameter", "variable.language.special.cls.python", palette.Property},
+		{"builtinConstant", "constant.language.python", palette.Constant},
+	}},
+	{ID: "rust", Root: "source.rust", Mappings: []Mapping{
+		{"builtinType", "support.type.primitive.rust", palette.Type},
+		{"typeAlias", "support.type.alias.rust", palette.Type},
+		{"selfTypeKeyword", "support.type.self.rust", palette.Type},
+		{"selfKeyword", "variable.language.self.rust", palette.Property},
+		{"lifetime", "storage.modifier.lifetime.rust", palette.Keyword},
+		{"boolean", "constant.language.bool.rust", palette.Constant},
+		{"formatSpecifier", "constant.other.placeholder.rust", palette.Constant},
+		{"escapeSequence", "constant.character.escape.rust", palette.Constant},
+		// Flow keywords share the accent, as keyword.control.flow does in
+		// TextMate languages.
+		{"keyword.controlFlow", "keyword.control.flow.rust", palette.Property},
+	}},
+}
+
+// Contribution returns contributes.semanticTokenScopes.
+func Contribution() []manifest.SemanticTokenScope {
+	var out []manifest.SemanticTokenScope
+	for _, l := range Languages {
+		scopes := map[string][]string{}
+		for _, m := range l.Mappings {
+			scopes[m.Selector] = []string{m.Scope}
+		}
+		out = append(out, manifest.SemanticTokenScope{Language: l.ID, Scopes: scopes})
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package semantic
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func TestTables(t *testing.T) {
+	if err := validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The scopes apply whichever variant is active, but they are chosen for
+// the default one: Focus groups some of them differently.
+func TestMappedScopesPaintTheirRole(t *testing.T) {
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pr := range check(v.Theme, p) {
+		t.Error(pr)
+	}
+}
+
+func TestPackageJSONUpToDate(t *testing.T) {
+	m, err := manifest.Load("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Compare through JSON so that a missing and an empty list agree.
+	var got, want any
+	for _, c := range []struct {
+		v   any
+		out *any
+	}{{m.Contributes.SemanticTokenScopes, &got}, {Contribution(), &want}} {
+		data, err := json.Marshal(c.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, c.out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("package.json semanticTokenScopes is out of date; run caffeinated semantic")
+	}
+}
+
+// problem is a mapping whose scope a theme does not paint in its role.
+type problem struct {
+	Language string
+	Mapping  Mapping
+	Style    theme.Style
+	Want     string
+}
+
+func (p problem) String() string {
+	s := fmt.Sprintf("%s %s: %s is %s", p.Language, p.Mapping.Selector, p.Mapping.Scope, p.Style.Foreground.Value)
+	if p.Style.Foreground.Rule >= 0 {
+		s += fmt.Sprintf(" from rule %d (%s)", p.Style.Foreground.Rule, p.Style.Foreground.Selector)
+	} else {
+		s += " (no rule)"
+	}
+	return s + fmt.Sprintf(", want %s %s", p.Mapping.Role, p.Want)
+}
+
+// check resolves every mapped scope under its language's root scope and
+// returns those that a rule of t does not paint in the mapping's role.
+func check(t *theme.Theme, p *palette.Palette) []problem {
+	var problems []problem
+	for _, l := range Languages {
+		for _, m := range l.Mappings {
+			st := t.Match(l.Root, m.Scope)
+			want := p.Hex(m.Role)
+			got, err := colors.ParseHex(st.Foreground.Value)
+			if st.Foreground.Rule < 0 || err != nil || got.Opaque().Hex() != want {
+				problems = append(problems, problem{Language: l.ID, Mapping: m, Style: st, Want: want})
+			}
+		}
+	}
+	return problems
+}
+
+// validate checks the tables themselves: known roles, no selector mapped
+// twice for a language, and selectors of the form type.modifier...
+func validate() error {
+	for _, l := range Languages {
+		seen := map[string]bool{}
+		for _, m := range l.Mappings {
+			if _, ok := palette.Lookup(m.Role); !ok {
+				return fmt.Errorf("%s %s: unknown role %q", l.ID, m.Selector, m.Role)
+			}
+			if seen[m.Selector] {
+				return fmt.Errorf("%s: %s is mapped twice", l.ID, m.Selector)
+			}
+			seen[m.Selector] = true
+			for _, part := range strings.Split(m.Selector, ".") {
+				if part == "" || strings.ContainsAny(part, " *:[]") {
+					return fmt.Errorf("%s: %q is not a token selector", l.ID, m.Selector)
+				}
+			}
+		}
+	}
+	return nil
+}
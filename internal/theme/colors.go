@@ -0,0 +1,108 @@
+package theme
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Colors is the workbench colour map of a theme. It keeps the order in which
+// ids first appear so that generated files diff cleanly against their source.
+// When an id is repeated the last value wins, as it does in VS Code.
+type Colors struct {
+	keys   []string
+	values map[string]string
+}
+
+// NewColors returns an empty colour map.
+func NewColors() *Colors {
+	return &Colors{values: map[string]string{}}
+}
+
+// Get returns the raw value of id.
+func (c *Colors) Get(id string) (string, bool) {
+	v, ok := c.values[id]
+	return v, ok
+}
+
+// Set assigns id, appending it to the key order if it is new.
+func (c *Colors) Set(id, value string) {
+	if _, ok := c.values[id]; !ok {
+		c.keys = append(c.keys, id)
+	}
+	c.values[id] = value
+}
+
+// Delete removes id.
+func (c *Colors) Delete(id string) {
+	if _, ok := c.values[id]; !ok {
+		return
+	}
+	delete(c.values, id)
+	for i, k := range c.keys {
+		if k == id {
+			c.keys = append(c.keys[:i], c.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the ids in file order.
+func (c *Colors) Keys() []string {
+	return append([]string(nil), c.keys...)
+}
+
+// Len returns the number of distinct ids.
+func (c *Colors) Len() int {
+	return len(c.keys)
+}
+
+// Clone returns a copy of c.
+func (c *Colors) Clone() *Colors {
+	n := &Colors{keys: append([]string(nil), c.keys...), values: make(map[string]string, len(c.values))}
+	for k, v := range c.values {
+		n.values[k] = v
+	}
+	return n
+}
+
+// MarshalJSON writes the ids in order.
+func (c *Colors) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range c.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, _ := json.Marshal(k)
+		vb, _ := json.Marshal(c.values[k])
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads an object of id to colour strings, keeping order.
+func (c *Colors) UnmarshalJSON(data []byte) error {
+	*c = *NewColors()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("theme: colors must be an object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := tok.(string)
+		var value string
+		if err := dec.Decode(&value); err != nil {
+			return fmt.Errorf("theme: colors[%q]: %w", key, err)
+		}
+		c.Set(key, value)
+	}
+	_, err := dec.Token()
+	return err
+}
@@ -0,0 +1,93 @@
+package theme
+
+import "strings"
+
+// Resolved is one style property of a token and the rule it came from.
+// Rule is -1 when no rule sets the property and the value is the editor
+// default.
+type Resolved struct {
+	Value    string
+	Rule     int
+	Selector string
+}
+
+// Style is the effective style of a token.
+type Style struct {
+	Foreground Resolved
+	Background Resolved
+	FontStyle  Resolved
+}
+
+// Match resolves the style of a token whose scope stack is given from the
+// outermost scope (e.g. "source.go") to the innermost one, following the
+// TextMate rules VS Code applies: for each property the innermost scope with
+// a matching rule wins, a longer selector prefix beats a shorter one, a
+// selector with parent scopes beats one without, and later rules win ties.
+func (t *Theme) Match(stack ...string) Style {
+	var s Style
+	s.Foreground = t.resolve(stack, func(ts TokenSettings) string { return ts.Foreground })
+	s.Background = t.resolve(stack, func(ts TokenSettings) string { return ts.Background })
+	s.FontStyle = t.resolve(stack, func(ts TokenSettings) string { return ts.FontStyle })
+	if s.Foreground.Rule < 0 {
+		s.Foreground.Value, _ = t.Colors.Get("editor.foreground")
+	}
+	return s
+}
+
+func (t *Theme) resolve(stack []string, prop func(TokenSettings) string) Resolved {
+	for depth := len(stack) - 1; depth >= 0; depth-- {
+		best := Resolved{Rule: -1}
+		bestScore := [2]int{-1, -1}
+		for i, rule := range t.TokenColors {
+			v := prop(rule.Settings)
+			if v == "" {
+				continue
+			}
+			for _, sel := range rule.Scope {
+				score, ok := MatchSelector(sel, stack[:depth+1])
+				if !ok {
+					continue
+				}
+				if score[0] > bestScore[0] || score[0] == bestScore[0] && score[1] >= bestScore[1] {
+					best = Resolved{Value: v, Rule: i, Selector: sel}
+					bestScore = score
+				}
+			}
+		}
+		if best.Rule >= 0 {
+			return best
+		}
+	}
+	return Resolved{Rule: -1}
+}
+
+// MatchSelector matches a descendant selector such as "source.go string"
+// against a scope stack whose last element is the scope being styled. The
+// score ranks the match by leaf prefix length, then by parent count.
+func MatchSelector(sel string, stack []string) ([2]int, bool) {
+	parts := strings.Fields(sel)
+	if len(parts) == 0 || len(stack) == 0 {
+		return [2]int{}, false
+	}
+	leaf := parts[len(parts)-1]
+	if !ScopePrefix(leaf, stack[len(stack)-1]) {
+		return [2]int{}, false
+	}
+	j := len(stack) - 2
+	for p := len(parts) - 2; p >= 0; p-- {
+		for j >= 0 && !ScopePrefix(parts[p], stack[j]) {
+			j--
+		}
+		if j < 0 {
+			return [2]int{}, false
+		}
+		j--
+	}
+	return [2]int{strings.Count(leaf, ".") + 1, len(parts) - 1}, true
+}
+
+// ScopePrefix reports whether selector matches scope on a dot boundary, so
+// that "string" matches "string.quoted.double.go" but not "stringy".
+func ScopePrefix(selector, scope string) bool {
+	return scope == selector || strings.HasPrefix(scope, selector+".")
+}
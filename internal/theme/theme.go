@@ -0,0 +1,158 @@
+// Package theme reads and writes VS Code colour theme files.
+package theme
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+)
+
+// Theme is a decoded colour theme file.
+type Theme struct {
+	Name                 string                     `json:"name"`
+	Type                 string                     `json:"type"`
+	SemanticHighlighting *bool                      `json:"semanticHighlighting,omitempty"`
+	Colors               *Colors                    `json:"colors"`
+	TokenColors          []TokenRule                `json:"tokenColors"`
+	SemanticTokenColors  map[string]json.RawMessage `json:"semanticTokenColors,omitempty"`
+}
+
+// TokenRule is one entry of tokenColors.
+type TokenRule struct {
+	Name     string        `json:"name,omitempty"`
+	Scope    Scopes        `json:"scope"`
+	Settings TokenSettings `json:"settings"`
+}
+
+// TokenSettings is the style a token rule applies.
+type TokenSettings struct {
+	Foreground string `json:"foreground,omitempty"`
+	Background string `json:"background,omitempty"`
+	FontStyle  string `json:"fontStyle,omitempty"`
+}
+
+// Scopes is a rule's scope selector list. Theme files may give it either as
+// an array or as a single comma separated string.
+type Scopes []string
+
+// UnmarshalJSON accepts both selector list forms.
+func (s *Scopes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*s = nil
+		for _, part := range strings.Split(one, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				*s = append(*s, part)
+			}
+		}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*s = many
+	return nil
+}
+
+// Load reads the theme file at path.
+func Load(path string) (*Theme, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Parse decodes a JSONC theme document.
+func Parse(src []byte) (*Theme, error) {
+	var t Theme
+	if err := json.Unmarshal(StripJSONC(src), &t); err != nil {
+		return nil, err
+	}
+	if t.Colors == nil {
+		t.Colors = NewColors()
+	}
+	return &t, nil
+}
+
+// Color returns the parsed value of a workbench colour id.
+func (t *Theme) Color(id string) (colors.Color, bool) {
+	v, ok := t.Colors.Get(id)
+	if !ok {
+		return colors.Color{}, false
+	}
+	c, err := colors.ParseHex(v)
+	if err != nil {
+		return colors.Color{}, false
+	}
+	return c, true
+}
+
+// Clone returns a deep copy of t.
+func (t *Theme) Clone() *Theme {
+	c := *t
+	c.Colors = t.Colors.Clone()
+	c.TokenColors = make([]TokenRule, len(t.TokenColors))
+	for i, r := range t.TokenColors {
+		r.Scope = append(Scopes(nil), r.Scope...)
+		c.TokenColors[i] = r
+	}
+	if t.SemanticTokenColors != nil {
+		c.SemanticTokenColors = make(map[string]json.RawMessage, len(t.SemanticTokenColors))
+		for k, v := range t.SemanticTokenColors {
+			c.SemanticTokenColors[k] = v
+		}
+	}
+	return &c
+}
+
+// Encode writes t as indented JSON. Each line of header is emitted as a //
+// comment above the document, which VS Code accepts in theme files.
+func (t *Theme) Encode(w io.Writer, header string) error {
+	var buf bytes.Buffer
+	if header != "" {
+		for _, line := range strings.Split(strings.TrimRight(header, "\n"), "\n") {
+			fmt.Fprintf(&buf, "// %s\n", line)
+		}
+	}
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(t); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Hash returns the hex SHA-256 of t's encoding without a header. It
+// changes whenever anything the theme paints changes, and not when only
+// comments or formatting of the source file do.
+func (t *Theme) Hash() (string, error) {
+	h := sha256.New()
+	if err := t.Encode(h, ""); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Write encodes t to the file at path.
+func (t *Theme) Write(path, header string) error {
+	var buf bytes.Buffer
+	if err := t.Encode(&buf, header); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,86 @@
+package theme
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+)
+
+// DefaultVariant is the ID of the theme contributed first in package.json.
+const DefaultVariant = "default"
+
+// Variant is a theme contributed by package.json.
+type Variant struct {
+	ID      string
+	Label   string
+	UITheme string
+	Path    string
+	Theme   *Theme
+}
+
+// LoadVariants loads every theme contributed by the package.json in root.
+func LoadVariants(root string) ([]Variant, error) {
+	m, err := manifest.Load(root)
+	if err != nil {
+		return nil, err
+	}
+	var vs []Variant
+	for i, c := range m.Contributes.Themes {
+		path := filepath.Join(root, filepath.FromSlash(c.Path))
+		t, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		id := VariantID(c.Label)
+		if i == 0 {
+			id = DefaultVariant
+		}
+		vs = append(vs, Variant{ID: id, Label: c.Label, UITheme: c.UITheme, Path: path, Theme: t})
+	}
+	if len(vs) == 0 {
+		return nil, fmt.Errorf("%s: package.json contributes no themes", root)
+	}
+	return vs, nil
+}
+
+// LoadVariant loads the variant with the given ID; an empty ID selects the
+// default variant.
+func LoadVariant(root, id string) (Variant, error) {
+	vs, err := LoadVariants(root)
+	if err != nil {
+		return Variant{}, err
+	}
+	return FindVariant(vs, id)
+}
+
+// FindVariant looks a variant up by ID or label.
+func FindVariant(vs []Variant, id string) (Variant, error) {
+	if id == "" {
+		id = DefaultVariant
+	}
+	for _, v := range vs {
+		if v.ID == id || strings.EqualFold(v.Label, id) {
+			return v, nil
+		}
+	}
+	ids := make([]string, len(vs))
+	for i, v := range vs {
+		ids[i] = v.ID
+	}
+	return Variant{}, fmt.Errorf("unknown variant %q (have %s)", id, strings.Join(ids, ", "))
+}
+
+// VariantID derives a short ID from a contribution label by dropping the
+// "Caffeinated-Rust" family name, so "Caffeinated-Rust Focus" becomes "focus".
+func VariantID(label string) string {
+	slug := strings.ToLower(strings.Join(strings.FieldsFunc(label, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}), "-"))
+	slug = strings.TrimPrefix(strings.TrimPrefix(slug, "caffeinated-rust"), "-")
+	if slug == "" {
+		return DefaultVariant
+	}
+	return slug
+}
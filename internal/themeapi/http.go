@@ -0,0 +1,102 @@
+package themeapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	jsonType = "application/json"
+	cssType  = "text/css"
+	textType = "text/plain"
+	zipType  = "application/zip"
+)
+
+// etag derives a strong validator from the theme hash and the parts that
+// pick a representation of it. It assumes the exporters themselves do not
+// change while the server runs.
+func etag(hash string, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	short := hash
+	if len(short) > 16 {
+		short = short[:16]
+	}
+	return `"` + short + "-" + hex.EncodeToString(sum[:4]) + `"`
+}
+
+// serve writes data, answering conditional and HEAD requests. Responses
+// must be revalidated, since the theme files may change at any time.
+func serve(w http.ResponseWriter, r *http.Request, typ string, data []byte, tag string) {
+	h := w.Header()
+	if strings.HasPrefix(typ, "text/") && !strings.Contains(typ, "charset") {
+		typ += "; charset=utf-8"
+	}
+	h.Set("Content-Type", typ)
+	h.Set("ETag", tag)
+	h.Set("Cache-Control", "no-cache")
+	h.Add("Vary", "Accept")
+	h.Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+// negotiate picks the offered type the request's Accept header prefers,
+// the first offer when there is no header. It answers 406 when none is
+// acceptable.
+func negotiate(w http.ResponseWriter, r *http.Request, offers ...string) (string, bool) {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return offers[0], true
+	}
+	best, bestQ := "", 0.0
+	for _, o := range offers {
+		// Ties go to the earlier offer.
+		if q := accepts(accept, o); q > bestQ {
+			best, bestQ = o, q
+		}
+	}
+	if best == "" {
+		fail(w, http.StatusNotAcceptable, fmt.Sprintf("cannot produce %s; available: %s", accept, strings.Join(offers, ", ")))
+		return "", false
+	}
+	return best, true
+}
+
+// accepts returns the quality an Accept header gives a media type, taken
+// from the most specific range that matches it.
+func accepts(accept, typ string) float64 {
+	typ, _, _ = mime.ParseMediaType(typ)
+	major, _, _ := strings.Cut(typ, "/")
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		rng, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		s := -1
+		switch {
+		case rng == typ:
+			s = 2
+		case rng == major+"/*":
+			s = 1
+		case rng == "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+		specificity, q = s, 1
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return q
+}
@@ -0,0 +1,415 @@
+// Package themeapi serves the theme over HTTP for tools that would rather
+// fetch the palette or an exported config at runtime than vendor a copy.
+// Every response is derived from the theme files on disk when it is
+// requested, so edits show up without a restart, and carries an ETag built
+// from the theme hash so that clients can revalidate cheaply.
+//
+// Endpoints, all GET or HEAD:
+//
+//	/                         index of endpoints and export formats
+//	/variants                 the contributed variants
+//	/palette?variant=ID       palette roles and terminal colours
+//	/theme?variant=ID         the theme with every effective workbench colour
+//	/export/FORMAT?variant=ID an export format; ?file=PATH picks one file
+package themeapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// Options configures a Handler.
+type Options struct {
+	// Root is the repository root holding package.json and the themes.
+	Root string
+	// Public accepts requests from other hosts. By default only loopback
+	// clients, addressing the server by a loopback name, are served, which
+	// also keeps web pages from reaching it through DNS rebinding.
+	Public bool
+}
+
+// Handler returns the API handler.
+func Handler(opts Options) http.Handler {
+	s := &server{opts: opts, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/{$}", s.index)
+	s.mux.HandleFunc("/variants", s.variants)
+	s.mux.HandleFunc("/palette", s.palette)
+	s.mux.HandleFunc("/theme", s.theme)
+	s.mux.HandleFunc("/export/{format}", s.export)
+	return s
+}
+
+type server struct {
+	opts Options
+	mux  *http.ServeMux
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.opts.Public && !(loopback(r.RemoteAddr) && loopback(r.Host)) {
+		fail(w, http.StatusForbidden, "only local clients are served; start the server with -public to allow others")
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		fail(w, http.StatusMethodNotAllowed, r.Method+" is not supported")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// loopback reports whether a host, with or without a port, is localhost or
+// a loopback address.
+func loopback(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = strings.Trim(hostport, "[]")
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// IsLoopback reports whether a listen address only accepts local
+// connections.
+func IsLoopback(addr string) bool {
+	return loopback(addr)
+}
+
+// variant loads the variant named by the request's variant parameter.
+func (s *server) variant(w http.ResponseWriter, r *http.Request) (theme.Variant, string, bool) {
+	vs, err := theme.LoadVariants(s.opts.Root)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err.Error())
+		return theme.Variant{}, "", false
+	}
+	v, err := theme.FindVariant(vs, r.URL.Query().Get("variant"))
+	if err != nil {
+		fail(w, http.StatusNotFound, err.Error())
+		return theme.Variant{}, "", false
+	}
+	hash, err := v.Theme.Hash()
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err.Error())
+		return theme.Variant{}, "", false
+	}
+	return v, hash, true
+}
+
+type link struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+func (s *server) index(w http.ResponseWriter, r *http.Request) {
+	doc := struct {
+		Endpoints []link   `json:"endpoints"`
+		Formats   []string `json:"formats"`
+	}{
+		Endpoints: []link{
+			{"/variants", "the contributed variants"},
+			{"/palette?variant=ID", "palette roles and terminal colours as JSON, CSS custom properties or text"},
+			{"/theme?variant=ID", "the theme with every effective workbench colour"},
+			{"/export/FORMAT?variant=ID", "an export format; ?file=PATH picks one file of a multi-file format"},
+		},
+		Formats: export.Names(),
+	}
+	data, err := encodeJSON(doc)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// The index only changes with the set of formats, which is fixed for
+	// the life of the process.
+	serve(w, r, jsonType, data, etag(strings.Join(doc.Formats, ","), "index"))
+}
+
+// Variant is an entry of /variants.
+type Variant struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	UITheme string `json:"uiTheme"`
+	Hash    string `json:"hash"`
+}
+
+func (s *server) variants(w http.ResponseWriter, r *http.Request) {
+	vs, err := theme.LoadVariants(s.opts.Root)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var out []Variant
+	var hashes []string
+	for _, v := range vs {
+		h, err := v.Theme.Hash()
+		if err != nil {
+			fail(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		out = append(out, Variant{ID: v.ID, Label: v.Label, UITheme: v.UITheme, Hash: h})
+		hashes = append(hashes, v.ID+"="+h)
+	}
+	typ, ok := negotiate(w, r, jsonType, textType)
+	if !ok {
+		return
+	}
+	var data []byte
+	if typ == textType {
+		var b strings.Builder
+		for _, v := range out {
+			fmt.Fprintf(&b, "%s\t%s\t%s\n", v.ID, v.UITheme, v.Label)
+		}
+		data = []byte(b.String())
+	} else if data, err = encodeJSON(out); err != nil {
+		fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	serve(w, r, typ, data, etag(strings.Join(hashes, ","), "variants", typ))
+}
+
+// Role is an entry of /palette.
+type Role struct {
+	Role      palette.Role `json:"role"`
+	Kind      string       `json:"kind"`
+	Color     string       `json:"color"`
+	FontStyle string       `json:"fontStyle,omitempty"`
+}
+
+// Palette is the JSON form of /palette.
+type Palette struct {
+	Variant string   `json:"variant"`
+	Name    string   `json:"name"`
+	Hash    string   `json:"hash"`
+	Roles   []Role   `json:"roles"`
+	ANSI    []string `json:"ansi"`
+}
+
+func (s *server) palette(w http.ResponseWriter, r *http.Request) {
+	v, hash, ok := s.variant(w, r)
+	if !ok {
+		return
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	doc := Palette{Variant: v.ID, Name: p.Name, Hash: hash}
+	for _, d := range palette.Definitions {
+		doc.Roles = append(doc.Roles, Role{Role: d.Role, Kind: d.Kind.String(), Color: p.Hex(d.Role), FontStyle: p.FontStyle(d.Role)})
+	}
+	for _, c := range p.ANSI() {
+		doc.ANSI = append(doc.ANSI, c.Hex())
+	}
+	typ, ok := negotiate(w, r, jsonType, cssType, textType)
+	if !ok {
+		return
+	}
+	var data []byte
+	switch typ {
+	case cssType:
+		var b strings.Builder
+		fmt.Fprintf(&b, "/* %s palette, theme hash %s */\n:root {\n", v.Label, hash)
+		for _, role := range doc.Roles {
+			fmt.Fprintf(&b, "  --caffeinated-%s: %s;\n", role.Role, role.Color)
+		}
+		for i, c := range doc.ANSI {
+			fmt.Fprintf(&b, "  --caffeinated-ansi-%d: %s;\n", i, c)
+		}
+		b.WriteString("}\n")
+		data = []byte(b.String())
+	case textType:
+		var b strings.Builder
+		for _, role := range doc.Roles {
+			fmt.Fprintf(&b, "%-12s %s\n", role.Role, role.Color)
+		}
+		for i, c := range doc.ANSI {
+			fmt.Fprintf(&b, "%-12s %s\n", fmt.Sprintf("ansi%d", i), c)
+		}
+		data = []byte(b.String())
+	default:
+		if data, err = encodeJSON(doc); err != nil {
+			fail(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	serve(w, r, typ, data, etag(hash, "palette", typ))
+}
+
+func (s *server) theme(w http.ResponseWriter, r *http.Request) {
+	v, hash, ok := s.variant(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := negotiate(w, r, jsonType); !ok {
+		return
+	}
+	eff, err := colorreg.Resolve(v.Theme)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	t := v.Theme.Clone()
+	t.Colors = theme.NewColors()
+	for _, id := range eff.IDs() {
+		if c, ok := eff.Color(id); ok {
+			t.Colors.Set(id, c.Hex())
+		}
+	}
+	var b bytes.Buffer
+	if err := t.Encode(&b, ""); err != nil {
+		fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	serve(w, r, jsonType, b.Bytes(), etag(hash, "theme"))
+}
+
+// ExportFile is an entry of the JSON listing of /export/FORMAT.
+type ExportFile struct {
+	Path string `json:"path"`
+	Size int    `json:"size"`
+	Mode string `json:"mode,omitempty"`
+	URL  string `json:"url"`
+}
+
+func (s *server) export(w http.ResponseWriter, r *http.Request) {
+	format := r.PathValue("format")
+	if _, err := export.Lookup(format); err != nil {
+		fail(w, http.StatusNotFound, err.Error())
+		return
+	}
+	v, hash, ok := s.variant(w, r)
+	if !ok {
+		return
+	}
+	files, err := export.Run(format, v)
+	if err != nil {
+		fail(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if name := r.URL.Query().Get("file"); name != "" {
+		for _, f := range files {
+			if f.Path == name {
+				s.exportFile(w, r, f, hash, format)
+				return
+			}
+		}
+		fail(w, http.StatusNotFound, fmt.Sprintf("export %s has no file %q", format, name))
+		return
+	}
+	if len(files) == 1 {
+		if typ := r.Header.Get("Accept"); typ == "" || accepts(typ, fileType(files[0])) > 0 {
+			s.exportFile(w, r, files[0], hash, format)
+			return
+		}
+	}
+	typ, ok := negotiate(w, r, zipType, jsonType)
+	if !ok {
+		return
+	}
+	var data []byte
+	if typ == jsonType {
+		var list []ExportFile
+		for _, f := range files {
+			e := ExportFile{Path: f.Path, Size: len(f.Data), URL: fileURL(r, f.Path)}
+			if f.Mode != 0 {
+				e.Mode = fmt.Sprintf("%#o", f.Mode.Perm())
+			}
+			list = append(list, e)
+		}
+		if data, err = encodeJSON(list); err != nil {
+			fail(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	} else {
+		if data, err = zipFiles(files); err != nil {
+			fail(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.BaseName(v)+"-"+format+".zip"))
+	}
+	serve(w, r, typ, data, etag(hash, "export", format, typ))
+}
+
+func (s *server) exportFile(w http.ResponseWriter, r *http.Request, f export.File, hash, format string) {
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", path.Base(f.Path)))
+	serve(w, r, fileType(f), f.Data, etag(hash, "export", format, f.Path))
+}
+
+// fileURL is the address of one file of the export r asked for.
+func fileURL(r *http.Request, name string) string {
+	q := r.URL.Query()
+	q.Set("file", name)
+	return r.URL.Path + "?" + q.Encode()
+}
+
+// fileType guesses a content type from the extension, falling back on
+// plain text or bytes.
+func fileType(f export.File) string {
+	if t := mime.TypeByExtension(path.Ext(f.Path)); t != "" {
+		return t
+	}
+	if utf8.Valid(f.Data) {
+		return textType
+	}
+	return "application/octet-stream"
+}
+
+// zipFiles packs a multi-file export, keeping executable modes.
+func zipFiles(files []export.File) ([]byte, error) {
+	var b bytes.Buffer
+	zw := zip.NewWriter(&b)
+	for _, f := range files {
+		h := &zip.FileHeader{Name: f.Path, Method: zip.Deflate}
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		h.SetMode(mode)
+		fw, err := zw.CreateHeader(h)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(f.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func encodeJSON(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// fail writes a JSON error body.
+func fail(w http.ResponseWriter, status int, msg string) {
+	data, _ := json.Marshal(map[string]string{"error": msg})
+	w.Header().Set("Content-Type", jsonType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,307 @@
+package themeapi
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+const root = "../.."
+
+// client issues requests against a loopback test server running Handler
+// on the repository.
+type client struct {
+	t    *testing.T
+	base string
+}
+
+func newClient(t *testing.T) client {
+	t.Helper()
+	srv := httptest.NewServer(Handler(Options{Root: root}))
+	t.Cleanup(srv.Close)
+	return client{t, srv.URL}
+}
+
+func (c client) get(path string, header ...string) (*http.Response, []byte) {
+	c.t.Helper()
+	return c.do(http.MethodGet, path, header...)
+}
+
+// do sends a request with header given as name and value pairs; "Host"
+// sets the request's host.
+func (c client) do(method, path string, header ...string) (*http.Response, []byte) {
+	c.t.Helper()
+	req, err := http.NewRequest(method, c.base+path, nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		if header[i] == "Host" {
+			req.Host = header[i+1]
+		} else {
+			req.Header.Set(header[i], header[i+1])
+		}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return resp, body
+}
+
+// expect fails the test unless the response has the status and, if typ is
+// not empty, the media type.
+func expect(t *testing.T, resp *http.Response, body []byte, status int, typ string) bool {
+	t.Helper()
+	if resp.StatusCode != status {
+		t.Errorf("%s: status %d, want %d: %s", resp.Request.URL, resp.StatusCode, status, strings.TrimSpace(string(body)))
+		return false
+	}
+	if got, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";"); typ != "" && got != typ {
+		t.Errorf("%s: content type %q, want %q", resp.Request.URL, got, typ)
+		return false
+	}
+	return true
+}
+
+func variants(t *testing.T) []theme.Variant {
+	t.Helper()
+	vs, err := theme.LoadVariants(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vs
+}
+
+func TestIndex(t *testing.T) {
+	c := newClient(t)
+	resp, body := c.get("/")
+	if !expect(t, resp, body, http.StatusOK, "application/json") {
+		return
+	}
+	var doc struct{ Formats []string }
+	if err := json.Unmarshal(body, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(doc.Formats, ",") != strings.Join(export.Names(), ",") {
+		t.Errorf("formats %v, want %v", doc.Formats, export.Names())
+	}
+}
+
+func TestVariants(t *testing.T) {
+	c := newClient(t)
+	vs := variants(t)
+	resp, body := c.get("/variants")
+	if !expect(t, resp, body, http.StatusOK, "application/json") {
+		return
+	}
+	var got []Variant
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(vs) {
+		t.Fatalf("%d variants, want %d", len(got), len(vs))
+	}
+	for i, v := range vs {
+		hash, err := v.Theme.Hash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got[i].ID != v.ID || got[i].Hash != hash {
+			t.Errorf("variant %d is %s %s, want %s %s", i, got[i].ID, got[i].Hash, v.ID, hash)
+		}
+	}
+}
+
+func TestPalette(t *testing.T) {
+	c := newClient(t)
+	for _, v := range variants(t) {
+		resp, body := c.get("/palette?variant=" + v.ID)
+		if !expect(t, resp, body, http.StatusOK, "application/json") {
+			continue
+		}
+		var got Palette
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatal(err)
+		}
+		p, err := palette.New(v.Theme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Roles) != len(palette.Definitions) || len(got.ANSI) != 16 {
+			t.Errorf("%s: %d roles and %d terminal colours", v.ID, len(got.Roles), len(got.ANSI))
+		}
+		for _, r := range got.Roles {
+			if r.Color != p.Hex(r.Role) {
+				t.Errorf("%s: %s is %s, want %s", v.ID, r.Role, r.Color, p.Hex(r.Role))
+			}
+		}
+	}
+}
+
+func TestETag(t *testing.T) {
+	c := newClient(t)
+	resp, body := c.get("/palette")
+	tag := resp.Header.Get("ETag")
+	if tag == "" {
+		t.Fatalf("no ETag: %s", body)
+	}
+	resp, body = c.get("/palette", "If-None-Match", tag)
+	if resp.StatusCode != http.StatusNotModified || len(body) != 0 {
+		t.Errorf("revalidation gave status %d with %d bytes, want 304 and none", resp.StatusCode, len(body))
+	}
+	tags := map[string]string{}
+	for _, path := range []string{"/palette", "/theme", "/export/kitty"} {
+		for _, v := range variants(t) {
+			resp, _ := c.get(path + "?variant=" + v.ID)
+			tag := resp.Header.Get("ETag")
+			if other, dup := tags[tag]; dup {
+				t.Errorf("%s?variant=%s and %s share the ETag %s", path, v.ID, other, tag)
+			}
+			tags[tag] = path + "?variant=" + v.ID
+		}
+	}
+}
+
+func TestNegotiation(t *testing.T) {
+	c := newClient(t)
+	for _, tc := range []struct{ accept, want string }{
+		{"text/css", "text/css"},
+		{"text/plain;q=0.5, text/css;q=0.2", "text/plain"},
+		{"text/*", "text/css"},
+		{"*/*;q=0.1, application/json", "application/json"},
+	} {
+		resp, body := c.get("/palette", "Accept", tc.accept)
+		expect(t, resp, body, http.StatusOK, tc.want)
+	}
+	resp, body := c.get("/palette", "Accept", "image/png")
+	expect(t, resp, body, http.StatusNotAcceptable, "application/json")
+}
+
+func TestTheme(t *testing.T) {
+	c := newClient(t)
+	for _, v := range variants(t) {
+		resp, body := c.get("/theme?variant=" + v.ID)
+		if !expect(t, resp, body, http.StatusOK, "application/json") {
+			continue
+		}
+		got, err := theme.Parse(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eff, err := colorreg.Resolve(v.Theme)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, id := range eff.IDs() {
+			c, ok := eff.Color(id)
+			if !ok {
+				continue
+			}
+			n++
+			if hex, _ := got.Colors.Get(id); hex != c.Hex() {
+				t.Errorf("%s: %s is %q, want %s", v.ID, id, hex, c.Hex())
+			}
+		}
+		if got.Colors.Len() != n || len(got.TokenColors) != len(v.Theme.TokenColors) {
+			t.Errorf("%s: %d colours and %d token rules, want %d and %d", v.ID, got.Colors.Len(), len(got.TokenColors), n, len(v.Theme.TokenColors))
+		}
+	}
+}
+
+// TestExport compares every export endpoint with export.Run: a single file
+// is served as is, several as a zip, and each file on its own with ?file=.
+func TestExport(t *testing.T) {
+	c := newClient(t)
+	for _, format := range export.Names() {
+		for _, v := range variants(t) {
+			files, err := export.Run(format, v)
+			if err != nil {
+				t.Fatalf("%s %s: %v", format, v.ID, err)
+			}
+			path := "/export/" + format + "?variant=" + v.ID
+			resp, body := c.get(path)
+			if !expect(t, resp, body, http.StatusOK, "") {
+				continue
+			}
+			if len(files) == 1 {
+				if !bytes.Equal(body, files[0].Data) {
+					t.Errorf("%s: served %d bytes, want the %d of %s", path, len(body), len(files[0].Data), files[0].Path)
+				}
+			} else {
+				checkZip(t, path, body, files)
+			}
+			for _, f := range files {
+				resp, body := c.get(path + "&file=" + url.QueryEscape(f.Path))
+				if expect(t, resp, body, http.StatusOK, "") && !bytes.Equal(body, f.Data) {
+					t.Errorf("%s&file=%s served %d bytes, want %d", path, f.Path, len(body), len(f.Data))
+				}
+			}
+		}
+	}
+}
+
+func checkZip(t *testing.T, path string, body []byte, files []export.File) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Errorf("%s: %v", path, err)
+		return
+	}
+	if len(zr.File) != len(files) {
+		t.Errorf("%s: zip has %d files, want %d", path, len(zr.File), len(files))
+		return
+	}
+	for i, zf := range zr.File {
+		r, err := zf.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if zf.Name != files[i].Path || !bytes.Equal(data, files[i].Data) {
+			t.Errorf("%s: zip entry %d is %s, want %s", path, i, zf.Name, files[i].Path)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	c := newClient(t)
+	for _, tc := range []struct {
+		method, path string
+		header       []string
+		status       int
+	}{
+		{http.MethodGet, "/palette?variant=nope", nil, http.StatusNotFound},
+		{http.MethodGet, "/export/nope", nil, http.StatusNotFound},
+		{http.MethodGet, "/export/kitty?file=nope", nil, http.StatusNotFound},
+		{http.MethodGet, "/nope", nil, http.StatusNotFound},
+		{http.MethodPost, "/palette", nil, http.StatusMethodNotAllowed},
+		{http.MethodGet, "/palette", []string{"Host", "theme.example.com"}, http.StatusForbidden},
+	} {
+		resp, body := c.do(tc.method, tc.path, tc.header...)
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s %s: status %d, want %d: %s", tc.method, tc.path, resp.StatusCode, tc.status, body)
+		}
+	}
+}
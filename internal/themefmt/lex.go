@@ -0,0 +1,97 @@
+package themefmt
+
+import "fmt"
+
+type kind int
+
+const (
+	tString kind = iota
+	tPunct
+	tComment
+	tNewline
+	tLiteral
+)
+
+// token is a lexical element of a JSONC document; text is its source.
+type token struct {
+	kind       kind
+	text       string
+	start, end int
+	line       int
+}
+
+// lex splits src into tokens. Spaces and tabs are dropped; newlines are
+// kept because comments belong to the line they are on.
+func lex(src string) ([]token, error) {
+	var ts []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		start := i
+		var k kind
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '\n':
+			k = tNewline
+			i++
+		case c == '"':
+			k = tString
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+				if i < len(src) && src[i] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			i++
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			k = tComment
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			for i > start && (src[i-1] == ' ' || src[i-1] == '\t' || src[i-1] == '\r') {
+				i--
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			k = tComment
+			j := i + 2
+			for j+1 < len(src) && !(src[j] == '*' && src[j+1] == '/') {
+				j++
+			}
+			if j+1 >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			i = j + 2
+		case c == '{' || c == '}' || c == '[' || c == ']' || c == ':' || c == ',':
+			k = tPunct
+			i++
+		default:
+			k = tLiteral
+			for i < len(src) && !isDelim(src[i]) {
+				i++
+			}
+		}
+		t := token{kind: k, text: src[start:i], start: start, end: i, line: line}
+		ts = append(ts, t)
+		for j := start; j < i; j++ {
+			if src[j] == '\n' {
+				line++
+			}
+		}
+	}
+	return ts, nil
+}
+
+func isDelim(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '"', '{', '}', '[', ']', ':', ',', '/':
+		return true
+	}
+	return false
+}
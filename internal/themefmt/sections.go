@@ -0,0 +1,122 @@
+package themefmt
+
+import "strings"
+
+// Section is a commented group of workbench colour ids. An id belongs to
+// the section listing it in IDs, or else to the section listing its area,
+// the part of the id before the first dot ("editorGutter" in
+// "editorGutter.background"). Areas the table does not list fall back to
+// the longest listed area they start with, so "editorStickyScroll" joins
+// the editor colours.
+type Section struct {
+	Title string
+	Areas []string
+	IDs   []string
+}
+
+// Other collects the ids no section claims.
+const Other = "Other"
+
+// Sections is the canonical order of the colors object.
+var Sections = []Section{
+	{Title: "Editor Colors", Areas: []string{"editor"}},
+	{Title: "Cursor", Areas: []string{"editorCursor"}},
+	{Title: "Whitespace and Indentation", Areas: []string{"editorWhitespace", "editorIndentGuide", "editorRuler"}},
+	{Title: "Line Numbers", Areas: []string{"editorLineNumber"}},
+	{Title: "Gutter", Areas: []string{"editorGutter"}},
+	{Title: "Brackets", Areas: []string{"editorBracketMatch", "editorBracketHighlight", "editorBracketPairGuide"}},
+	{Title: "Code Lens", Areas: []string{"editorCodeLens"}},
+	{Title: "Folding", IDs: []string{"editor.foldBackground", "editorGutter.foldingControlForeground"}},
+	{Title: "Overview Ruler", Areas: []string{"editorOverviewRuler"}},
+	{Title: "Errors and Warnings", Areas: []string{"editorError", "editorWarning", "editorInfo", "editorHint"}},
+	{Title: "Hover", Areas: []string{"editorHoverWidget"}},
+	{Title: "Suggest Widget", Areas: []string{"editorSuggestWidget", "editorSuggestWidgetStatus"}},
+	{Title: "Find Widget", Areas: []string{"editorWidget"}},
+	{Title: "Quick Fix", Areas: []string{"editorLightBulb", "editorLightBulbAutoFix", "editorLightBulbAi"}},
+	{Title: "Marker Navigation", Areas: []string{"editorMarkerNavigation", "editorMarkerNavigationError", "editorMarkerNavigationWarning", "editorMarkerNavigationInfo"}},
+	{Title: "Parameter Hints", Areas: []string{"editorParameterHint"}},
+	{Title: "Activity Bar", Areas: []string{"activityBar", "activityBarBadge"}},
+	{Title: "Side Bar", Areas: []string{"sideBar", "sideBarTitle", "sideBarSectionHeader"}},
+	{Title: "Side Bar List", Areas: []string{"list"}},
+	{Title: "Tree", Areas: []string{"tree"}},
+	{Title: "Explorer", Areas: []string{"explorer"}},
+	{Title: "Status Bar", Areas: []string{"statusBar", "statusBarItem"}},
+	{Title: "Tabs", Areas: []string{"tab", "tabBar"}},
+	{Title: "Editor Groups", Areas: []string{"editorGroup", "editorGroupHeader"}},
+	{Title: "Panel (Terminal, Output, etc.)", Areas: []string{"panel", "panelTitle", "panelInput", "panelSection", "panelSectionHeader"}},
+	{Title: "Terminal", Areas: []string{"terminal", "terminalCursor"}},
+	{Title: "Title Bar", Areas: []string{"titleBar"}},
+	{Title: "Menu Bar", Areas: []string{"menubar", "menu"}},
+	{Title: "Command Palette", Areas: []string{"quickInput", "quickInputTitle", "quickInputList", "pickerGroup"}},
+	{Title: "Buttons", Areas: []string{"button"}},
+	{Title: "Inputs", Areas: []string{"input", "inputOption", "inputValidation", "checkbox"}},
+	{Title: "Dropdown", Areas: []string{"dropdown"}},
+	{Title: "Badges", Areas: []string{"badge"}},
+	{Title: "Progress Bar", Areas: []string{"progressBar"}},
+	{Title: "Scrollbar", Areas: []string{"scrollbar", "scrollbarSlider"}},
+	{Title: "Selection", Areas: []string{"selection"}},
+	{Title: "Widget", Areas: []string{"widget"}, IDs: []string{"focusBorder", "icon.foreground", "descriptionForeground", "textLink.foreground", "textLink.activeForeground"}},
+	{Title: "Toolbar", Areas: []string{"toolbar"}},
+	{Title: "Keybinding Labels", Areas: []string{"keybindingLabel"}},
+	{Title: "Peek View", Areas: []string{"peekView", "peekViewEditor", "peekViewEditorGutter", "peekViewEditorStickyScroll", "peekViewResult", "peekViewTitle", "peekViewTitleDescription", "peekViewTitleLabel"}},
+	{Title: "Merge Conflicts", Areas: []string{"merge"}},
+	{Title: "Git", Areas: []string{"gitDecoration"}},
+	{Title: "Notifications", Areas: []string{"notificationCenter", "notificationCenterHeader", "notificationToast", "notifications", "notificationLink", "notificationsErrorIcon", "notificationsWarningIcon", "notificationsInfoIcon"}},
+	{Title: "Extensions", Areas: []string{"extensionButton", "extensionBadge"}},
+	{Title: "Settings", Areas: []string{"settings"}},
+	{Title: "Breadcrumbs", Areas: []string{"breadcrumb", "breadcrumbPicker"}},
+	{Title: "Symbol Icons", Areas: []string{"symbolIcon"}},
+}
+
+// place is where an id goes: its section and its rank within it.
+type place struct {
+	section int
+	rank    int
+}
+
+// Classify returns the index in Sections of the section id belongs to,
+// and len(Sections) for Other.
+func Classify(id string) int {
+	return classify(id).section
+}
+
+func classify(id string) place {
+	for i, s := range Sections {
+		for j, x := range s.IDs {
+			if x == id {
+				return place{i, j}
+			}
+		}
+	}
+	area, _, _ := strings.Cut(id, ".")
+	best, bestLen := place{len(Sections), 0}, 0
+	for i, s := range Sections {
+		for j, a := range s.Areas {
+			if a == area {
+				return place{i, len(s.IDs) + j}
+			}
+			if strings.HasPrefix(area, a) && len(a) > bestLen {
+				best, bestLen = place{i, len(s.IDs) + j}, len(a)
+			}
+		}
+	}
+	return best
+}
+
+// title returns the title of section i, including Other.
+func title(i int) string {
+	if i == len(Sections) {
+		return Other
+	}
+	return Sections[i].Title
+}
+
+// sectionTitle reports whether a comment's text is a section title.
+func sectionTitle(text string) (int, bool) {
+	for i := 0; i <= len(Sections); i++ {
+		if title(i) == text {
+			return i, true
+		}
+	}
+	return 0, false
+}
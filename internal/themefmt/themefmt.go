@@ -0,0 +1,285 @@
+// Package themefmt formats theme files canonically. The colors object is
+// regrouped into the sections of Sections, each under its // title comment
+// and ordered as in VS Code's colour registry; hex colours are upper-cased
+// throughout. Everything outside the colors object keeps its layout, and
+// hand-written comments inside it travel with the id below them.
+package themefmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+)
+
+// Result is a formatted document and what formatting changed.
+type Result struct {
+	Data []byte
+	// Changes describes each change, starting "N: " with its line.
+	Changes []string
+	// Unsectioned lists the ids that went to the Other section.
+	Unsectioned []string
+	// Unknown lists the ids VS Code's colour registry does not have.
+	Unknown []string
+}
+
+// entry is one id of the colors object.
+type entry struct {
+	id, value string
+	// lead holds the comment lines above the id, trail the comment after
+	// it on the same line.
+	lead  []string
+	trail string
+	line  int
+	// from is the section the id was found under, -1 before any title.
+	from int
+	at   place
+	reg  int
+}
+
+var hexColor = regexp.MustCompile(`^"#[0-9A-Fa-f]{3,8}"$`)
+
+// Format formats the theme document src.
+func Format(src []byte) (*Result, error) {
+	text := string(src)
+	ts, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+	open, close, err := colorsObject(ts)
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{}
+
+	// Upper-case hex colours outside the colors object in place.
+	var out strings.Builder
+	last := 0
+	recase := func(lo, hi int) {
+		for _, t := range ts[lo:hi] {
+			if t.kind == tString && hexColor.MatchString(t.text) && t.text != strings.ToUpper(t.text) {
+				out.WriteString(text[last:t.start])
+				out.WriteString(strings.ToUpper(t.text))
+				last = t.end
+				r.Changes = append(r.Changes, fmt.Sprintf("%d: %s upper-cased", t.line, t.text))
+			}
+		}
+	}
+	recase(0, open)
+
+	entries, tail, err := parseColors(ts[open+1:close], r)
+	if err != nil {
+		return nil, err
+	}
+	out.WriteString(text[last:ts[open].end])
+	out.WriteString(render(entries, tail, indentOf(text, ts, open, close)))
+	last = ts[close].start
+	recase(close, len(ts))
+	out.WriteString(text[last:])
+	r.Data = []byte(out.String())
+	return r, nil
+}
+
+// colorsObject returns the indexes of the braces of the top-level colors
+// object.
+func colorsObject(ts []token) (int, int, error) {
+	depth := 0
+	for i, t := range ts {
+		if t.kind != tPunct {
+			continue
+		}
+		switch t.text {
+		case "{", "[":
+			if depth == 1 && t.text == "{" && i >= 2 && ts[i-1].text == ":" && ts[i-2].text == `"colors"` {
+				d := 0
+				for j := i; j < len(ts); j++ {
+					switch ts[j].text {
+					case "{", "[":
+						d++
+					case "}", "]":
+						if d--; d == 0 {
+							return i, j, nil
+						}
+					}
+				}
+				return 0, 0, fmt.Errorf("%d: colors object is not closed", t.line)
+			}
+			depth++
+		case "}", "]":
+			depth--
+		}
+	}
+	return 0, 0, fmt.Errorf("no colors object")
+}
+
+// parseColors reads the entries between the braces of the colors object,
+// dropping section titles and merging repeated ids. Comments after the
+// last id are returned as tail.
+func parseColors(ts []token, r *Result) ([]*entry, []string, error) {
+	reg := map[string]int{}
+	for i, id := range colorreg.Default().IDs() {
+		reg[id] = i
+	}
+	var entries []*entry
+	seen := map[string]*entry{}
+	var pending []string
+	section := -1
+	var prev *entry
+	for i := 0; i < len(ts); i++ {
+		t := ts[i]
+		switch {
+		case t.kind == tNewline:
+			prev = nil
+		case t.kind == tComment:
+			if prev != nil {
+				prev.trail = t.text
+				continue
+			}
+			if s, ok := sectionTitle(strings.TrimSpace(strings.TrimPrefix(t.text, "//"))); ok && strings.HasPrefix(t.text, "//") {
+				section = s
+				continue
+			}
+			pending = append(pending, t.text)
+		case t.kind == tPunct && t.text == ",":
+		case t.kind == tString:
+			if i+2 >= len(ts) || ts[i+1].text != ":" || ts[i+2].kind != tString && ts[i+2].text != "null" {
+				return nil, nil, fmt.Errorf("%d: want \"id\": \"value\"", t.line)
+			}
+			var id string
+			if err := json.Unmarshal([]byte(t.text), &id); err != nil {
+				return nil, nil, fmt.Errorf("%d: %w", t.line, err)
+			}
+			value := ts[i+2].text
+			if hexColor.MatchString(value) && value != strings.ToUpper(value) {
+				r.Changes = append(r.Changes, fmt.Sprintf("%d: %s %s upper-cased", t.line, id, value))
+				value = strings.ToUpper(value)
+			}
+			i += 2
+			if e, ok := seen[id]; ok {
+				// VS Code uses the last value; keep it in the first place.
+				what := "same value"
+				if e.value != value {
+					what = fmt.Sprintf("%s replaces %s", value, e.value)
+				}
+				r.Changes = append(r.Changes, fmt.Sprintf("%d: %s repeats line %d (%s); merged", t.line, id, e.line, what))
+				e.value = value
+				e.lead = append(e.lead, pending...)
+				pending = nil
+				prev = e
+				continue
+			}
+			e := &entry{id: id, value: value, lead: pending, line: t.line, from: section, at: classify(id)}
+			if n, ok := reg[id]; ok {
+				e.reg = n
+			} else {
+				e.reg = len(reg)
+				r.Unknown = append(r.Unknown, id)
+			}
+			if e.at.section == len(Sections) {
+				r.Unsectioned = append(r.Unsectioned, id)
+			}
+			if e.from >= 0 && e.from != e.at.section {
+				r.Changes = append(r.Changes, fmt.Sprintf("%d: %s moved from %q to %q", t.line, id, title(e.from), title(e.at.section)))
+			}
+			pending = nil
+			seen[id] = e
+			entries = append(entries, e)
+			prev = e
+		default:
+			return nil, nil, fmt.Errorf("%d: unexpected %s in colors", t.line, t.text)
+		}
+	}
+
+	sorted := append([]*entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.at != b.at {
+			if a.at.section != b.at.section {
+				return a.at.section < b.at.section
+			}
+			return a.at.rank < b.at.rank
+		}
+		if a.reg != b.reg {
+			return a.reg < b.reg
+		}
+		return a.id < b.id
+	})
+	// Report each section whose ids changed order among themselves.
+	bySection := func(es []*entry) map[int][]*entry {
+		m := map[int][]*entry{}
+		for _, e := range es {
+			m[e.at.section] = append(m[e.at.section], e)
+		}
+		return m
+	}
+	before, after := bySection(entries), bySection(sorted)
+	for s := 0; s <= len(Sections); s++ {
+		for i, e := range before[s] {
+			if after[s][i] != e {
+				r.Changes = append(r.Changes, fmt.Sprintf("%d: %q reordered", e.line, title(s)))
+				break
+			}
+		}
+	}
+	return sorted, pending, nil
+}
+
+// indentOf returns the indentation of the first line inside the colors
+// object and of its closing brace.
+func indentOf(text string, ts []token, open, close int) [2]string {
+	lineIndent := func(pos int) string {
+		start := strings.LastIndexByte(text[:pos], '\n') + 1
+		return text[start:pos]
+	}
+	outer := lineIndent(ts[close].start)
+	if strings.TrimSpace(outer) != "" {
+		outer = ""
+	}
+	inner := outer + "  "
+	for _, t := range ts[open+1 : close] {
+		if t.kind == tString {
+			if in := lineIndent(t.start); strings.TrimSpace(in) == "" {
+				inner = in
+			}
+			break
+		}
+	}
+	return [2]string{inner, outer}
+}
+
+// render writes the body of the colors object, from after its opening
+// brace up to its closing brace.
+func render(entries []*entry, tail []string, indent [2]string) string {
+	var b strings.Builder
+	b.WriteString("\n")
+	section := -1
+	for i, e := range entries {
+		if e.at.section != section {
+			if section >= 0 {
+				b.WriteString("\n")
+			}
+			section = e.at.section
+			fmt.Fprintf(&b, "%s// %s\n", indent[0], title(section))
+		}
+		for _, c := range e.lead {
+			fmt.Fprintf(&b, "%s%s\n", indent[0], c)
+		}
+		key, _ := json.Marshal(e.id)
+		fmt.Fprintf(&b, "%s%s: %s", indent[0], key, e.value)
+		if i < len(entries)-1 {
+			b.WriteString(",")
+		}
+		if e.trail != "" {
+			b.WriteString(" " + e.trail)
+		}
+		b.WriteString("\n")
+	}
+	for _, c := range tail {
+		fmt.Fprintf(&b, "%s%s\n", indent[0], c)
+	}
+	b.WriteString(indent[1])
+	return b.String()
+}
@@ -0,0 +1,170 @@
+package themefmt
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// drifted has the misplaced and repeated keys of the request, hand-written
+// comments and lower-case hex colours inside and outside the colors object.
+const drifted = `{
+  "name": "Test",
+  "colors": {
+    // Editor Colors
+    "editor.background": "#1a1a1a",
+    // Gutter
+    "editorGutter.background": "#1A1A1A",
+    "editorGutter.foldingControlForeground": "#111111",
+    // Merge Conflicts
+    // the ruler mark for the current change
+    "editorOverviewRuler.currentContentForeground": "#aabbcc",
+    "merge.currentHeaderBackground": "#112233",
+    // Parameter Hints
+    "editorHoverWidget.highlightForeground": "#445566", // matches the find highlight
+    // Folding
+    // keep folding subtle
+    "editorGutter.foldingControlForeground": "#6c6c6c"
+    // end of colours
+  },
+  "tokenColors": [
+    { "scope": "comment", "settings": { "foreground": "#6c6c6c" } }
+  ]
+}
+`
+
+// layout returns, for each id of a formatted colors object, the section
+// titles it appears under, and the lines that are neither ids nor titles.
+func layout(t *testing.T, out string) (map[string][]string, []string) {
+	t.Helper()
+	body := out[strings.Index(out, `"colors": {`):strings.Index(out, `"tokenColors"`)]
+	ids := map[string][]string{}
+	var other []string
+	section := ""
+	for _, line := range strings.Split(body, "\n")[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if s, ok := sectionTitle(strings.TrimPrefix(line, "// ")); ok && strings.HasPrefix(line, "// ") {
+			section = title(s)
+			continue
+		}
+		if id, _, ok := strings.Cut(line, `": `); ok && strings.HasPrefix(id, `"`) {
+			ids[strings.TrimPrefix(id, `"`)] = append(ids[strings.TrimPrefix(id, `"`)], section)
+			continue
+		}
+		other = append(other, line)
+	}
+	return ids, other
+}
+
+func TestClassify(t *testing.T) {
+	for id, want := range map[string]string{
+		"editor.background":                            "Editor Colors",
+		"editorStickyScroll.background":                "Editor Colors",
+		"editorOverviewRuler.currentContentForeground": "Overview Ruler",
+		"editorHoverWidget.highlightForeground":        "Hover",
+		"editorGutter.foldingControlForeground":        "Folding",
+		"editorGutter.background":                      "Gutter",
+		"merge.currentHeaderBackground":                "Merge Conflicts",
+		"focusBorder":                                  "Widget",
+		"textLink.foreground":                          "Widget",
+		"notAnArea.foreground":                         Other,
+	} {
+		if got := title(Classify(id)); got != want {
+			t.Errorf("%s is in %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	r, err := Format([]byte(drifted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(r.Data)
+	ids, other := layout(t, out)
+
+	for id, want := range map[string]string{
+		"editorOverviewRuler.currentContentForeground": "Overview Ruler",
+		"editorHoverWidget.highlightForeground":        "Hover",
+		"editorGutter.foldingControlForeground":        "Folding",
+		"merge.currentHeaderBackground":                "Merge Conflicts",
+	} {
+		if got := strings.Join(ids[id], ", "); got != want {
+			t.Errorf("%s under %q, want once under %q", id, got, want)
+		}
+	}
+	want := []string{
+		`5: editor.background "#1a1a1a" upper-cased`,
+		`8: editorGutter.foldingControlForeground moved from "Gutter" to "Folding"`,
+		`11: editorOverviewRuler.currentContentForeground "#aabbcc" upper-cased`,
+		`11: editorOverviewRuler.currentContentForeground moved from "Merge Conflicts" to "Overview Ruler"`,
+		`14: editorHoverWidget.highlightForeground moved from "Parameter Hints" to "Hover"`,
+		`17: editorGutter.foldingControlForeground "#6c6c6c" upper-cased`,
+		`17: editorGutter.foldingControlForeground repeats line 8 ("#6C6C6C" replaces "#111111"); merged`,
+		`21: "#6c6c6c" upper-cased`,
+	}
+	if got := strings.Join(r.Changes, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("changes:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+
+	// The repeated id keeps VS Code's value, the last one.
+	if !strings.Contains(out, `"editorGutter.foldingControlForeground": "#6C6C6C"`) {
+		t.Errorf("merged id lost its last value:\n%s", out)
+	}
+	// Comments stay with the id below them, or the one on their line.
+	for _, pair := range [][2]string{
+		{"// the ruler mark for the current change", `"editorOverviewRuler.currentContentForeground"`},
+		{"// keep folding subtle", `"editorGutter.foldingControlForeground"`},
+	} {
+		if !strings.Contains(out, pair[0]+"\n    "+pair[1]) {
+			t.Errorf("%q is not above %s:\n%s", pair[0], pair[1], out)
+		}
+	}
+	if !strings.Contains(out, `"editorHoverWidget.highlightForeground": "#445566", // matches the find highlight`) {
+		t.Errorf("trailing comment lost:\n%s", out)
+	}
+	if got := strings.Join(other, "|"); got != "// keep folding subtle|// the ruler mark for the current change|// end of colours|}," {
+		t.Errorf("other lines in colors: %q", got)
+	}
+}
+
+func TestHexCase(t *testing.T) {
+	r, err := Format([]byte(drifted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(r.Data)
+	for _, lower := range []string{"#1a1a1a", "#aabbcc", "#6c6c6c"} {
+		if strings.Contains(out, lower) {
+			t.Errorf("%s was not upper-cased", lower)
+		}
+	}
+	if !strings.Contains(out, `{ "scope": "comment", "settings": { "foreground": "#6C6C6C" } }`) {
+		t.Errorf("token rule not upper-cased in place:\n%s", out)
+	}
+}
+
+// TestIdempotent formats the drifted document and the committed theme
+// again and expects no change.
+func TestIdempotent(t *testing.T) {
+	committed, err := os.ReadFile("../../themes/Caffeinated-Rust-color-theme.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := Format([]byte(drifted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range map[string][]byte{"formatted": first.Data, "committed theme": committed} {
+		r, err := Format(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(r.Data) != string(src) || len(r.Changes) != 0 {
+			t.Errorf("%s changed when formatted again: %v", name, r.Changes)
+		}
+	}
+}
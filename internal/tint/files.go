@@ -0,0 +1,78 @@
+package tint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+)
+
+// Files renders the tints of c for a variant whose file name stem is base:
+//
+//	ssh_config                Host blocks applying and resetting the tints
+//	<env>.osc, reset.osc      the raw escape sequences, for scripts
+//	kitty/ssh.conf            color_scheme settings for "kitten ssh"
+//	kitty/<base>-tint/*.conf  the kitty colour schemes they name
+//
+// source names the config in the banners.
+func Files(c *Config, b Base, p *palette.Palette, base, source string) ([]export.File, error) {
+	tints := make([]Tint, len(c.Environments))
+	for i, e := range c.Environments {
+		t, err := b.Tint(p, e)
+		if err != nil {
+			return nil, err
+		}
+		tints[i] = t
+	}
+	reset := b.Reset()
+	banner := func(comment string) string {
+		return fmt.Sprintf("%s Terminal tints for %s from %s.\n%s Generated by \"caffeinated tint\"; do not edit.\n", comment, p.Name, source, comment)
+	}
+
+	var files []export.File
+	var ssh strings.Builder
+	ssh.WriteString(banner("#"))
+	ssh.WriteString(`#
+# Include it near the top of ~/.ssh/config: ssh takes the first value it
+# finds for an option, so it must come before any block that sets
+# LocalCommand or RemoteCommand for the same hosts.
+#
+# LocalCommand tints the terminal once the connection is up. RemoteCommand
+# runs the login shell and resets the colours when it exits; ssh refuses it
+# together with a command, so use "ssh -o RemoteCommand=none" (and the
+# same for scp and sftp) to run one on these hosts. If the connection
+# drops, "cat reset.osc" puts the colours back.
+`)
+	for i, e := range c.Environments {
+		fmt.Fprintf(&ssh, "\n# %s: %s at %g\n", e.Name, e.Role, e.Strength)
+		fmt.Fprintf(&ssh, "Host %s\n", strings.Join(e.Hosts, " "))
+		ssh.WriteString("    PermitLocalCommand yes\n")
+		fmt.Fprintf(&ssh, "    LocalCommand %s >/dev/tty 2>/dev/null\n", tints[i].Printf())
+		ssh.WriteString("    RequestTTY yes\n")
+		fmt.Fprintf(&ssh, "    RemoteCommand \"$SHELL\" -l; %s\n", reset.Printf())
+	}
+	files = append(files, export.File{Path: "ssh_config", Data: []byte(ssh.String())})
+
+	for _, t := range append(tints, reset) {
+		files = append(files, export.File{Path: t.Name + ".osc", Data: []byte(t.OSC())})
+	}
+
+	// kitten ssh applies the last block that matches, so the environments
+	// go in reverse order to keep the first match winning. It restores the
+	// colours itself when the session ends.
+	dir := base + "-tint"
+	var kitty strings.Builder
+	kitty.WriteString(banner("#"))
+	fmt.Fprintf(&kitty, "# Merge it into ~/.config/kitty/ssh.conf and copy the\n# %s directory next to it.\n", dir)
+	for i := len(c.Environments) - 1; i >= 0; i-- {
+		e := c.Environments[i]
+		fmt.Fprintf(&kitty, "\nhostname %s\ncolor_scheme %s/%s.conf\n", strings.Join(e.Hosts, " "), dir, e.Name)
+		files = append(files, export.File{
+			Path: fmt.Sprintf("kitty/%s/%s.conf", dir, e.Name),
+			Data: []byte(banner("#") + "\n" + tints[i].Kitty()),
+		})
+	}
+	files = append(files, export.File{Path: "kitty/ssh.conf", Data: []byte(kitty.String())})
+	return files, nil
+}
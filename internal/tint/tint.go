@@ -0,0 +1,224 @@
+// Package tint tints the terminal by the environment of the host an SSH
+// session connects to. A config maps host patterns to environments, each
+// tinted towards a palette role: the background takes a little of the
+// role's hue at the same lightness, the cursor takes the role colour and
+// the selection is laid over the tinted background. The ANSI colours and
+// the foreground are left alone, so every colour keeps (within a few
+// percent) the contrast it has against the untinted background.
+package tint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/cast"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+)
+
+// DefaultStrength is how far the background moves towards the role's
+// chroma when an environment does not say.
+const DefaultStrength = 0.25
+
+// Config maps hosts to environments. The first environment with a matching
+// pattern wins, as the first matching Host block does in ssh_config.
+type Config struct {
+	Environments []Environment `json:"environments"`
+}
+
+// Environment is one tint. Hosts are ssh_config style patterns, where *
+// matches any run of characters and ? any one character.
+type Environment struct {
+	Name     string       `json:"name"`
+	Role     palette.Role `json:"role"`
+	Strength float64      `json:"strength"`
+	Hosts    []string     `json:"hosts"`
+}
+
+// Load reads and validates a config.
+func Load(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return &c, nil
+}
+
+func (c *Config) validate() error {
+	if len(c.Environments) == 0 {
+		return fmt.Errorf("no environments")
+	}
+	seen := map[string]bool{}
+	for i := range c.Environments {
+		e := &c.Environments[i]
+		if e.Name == "" || strings.Trim(e.Name, "abcdefghijklmnopqrstuvwxyz0123456789-_") != "" {
+			return fmt.Errorf("environment %d: name %q must be lower-case letters, digits, - and _", i+1, e.Name)
+		}
+		if e.Name == "reset" || seen[e.Name] {
+			return fmt.Errorf("environment %s: name is taken", e.Name)
+		}
+		seen[e.Name] = true
+		if _, ok := palette.Lookup(e.Role); !ok {
+			return fmt.Errorf("environment %s: unknown role %q", e.Name, e.Role)
+		}
+		if e.Strength == 0 {
+			e.Strength = DefaultStrength
+		}
+		if e.Strength < 0 || e.Strength > 1 {
+			return fmt.Errorf("environment %s: strength %g is not between 0 and 1", e.Name, e.Strength)
+		}
+		if len(e.Hosts) == 0 {
+			return fmt.Errorf("environment %s: no hosts", e.Name)
+		}
+		for _, h := range e.Hosts {
+			// Both ssh_config and kitty's ssh.conf take * and ?, but they
+			// disagree on the rest, and a space would split the pattern.
+			if h == "" || strings.ContainsAny(h, " \t![]{},\\/\"'") {
+				return fmt.Errorf("environment %s: unsupported host pattern %q", e.Name, h)
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the environment for host and the pattern that matched it.
+// Like ssh, it ignores case.
+func (c *Config) Match(host string) (Environment, string, bool) {
+	host = strings.ToLower(host)
+	for _, e := range c.Environments {
+		for _, h := range e.Hosts {
+			// Validated patterns have no [ or \, which leaves path.Match
+			// only * and ?, and host names have no /.
+			if ok, _ := path.Match(strings.ToLower(h), host); ok {
+				return e, h, true
+			}
+		}
+	}
+	return Environment{}, "", false
+}
+
+// Base is the untinted terminal.
+type Base struct {
+	cast.Colors
+	// Selection is the selection laid over the background; selection is
+	// the selection colour itself, which may be translucent.
+	Selection colors.Color
+	selection colors.Color
+}
+
+// BaseColors reads the terminal colours of a variant.
+func BaseColors(eff *colorreg.Effective, p *palette.Palette) (Base, error) {
+	tc, err := cast.TerminalColors(eff, p)
+	if err != nil {
+		return Base{}, err
+	}
+	b := Base{Colors: tc, selection: p.Color(palette.Selection)}
+	if c, ok := eff.Color("terminal.selectionBackground"); ok {
+		b.selection = c
+	}
+	b.Selection = b.selection.Over(tc.Background)
+	return b, nil
+}
+
+// Tint is the colours a session is switched to.
+type Tint struct {
+	Name       string
+	Background colors.Color
+	Cursor     colors.Color
+	CursorText colors.Color
+	Selection  colors.Color
+}
+
+// Reset returns the untinted colours as a Tint named "reset".
+func (b Base) Reset() Tint {
+	return Tint{Name: "reset", Background: b.Background, Cursor: b.Cursor, CursorText: b.CursorText, Selection: b.Selection}
+}
+
+// Tint computes the colours of e and checks that they keep the contrast of
+// the untinted terminal.
+func (b Base) Tint(p *palette.Palette, e Environment) (Tint, error) {
+	role := p.Color(e.Role).OKLab()
+	bg := b.Background.OKLab()
+	bg.A += (role.A - bg.A) * e.Strength
+	bg.B += (role.B - bg.B) * e.Strength
+	t := Tint{
+		Name:       e.Name,
+		Background: colors.FromOKLab(bg, 0xff),
+		Cursor:     p.Color(e.Role).Opaque(),
+	}
+	t.CursorText = t.Background
+	t.Selection = b.selection.Over(t.Background)
+	return t, b.check(t)
+}
+
+// keep is the share of its untinted contrast a colour must keep.
+const keep = 0.95
+
+func (b Base) check(t Tint) error {
+	var problems []string
+	ratio := func(name string, c, was, is colors.Color) {
+		before, after := colors.Contrast(c, was), colors.Contrast(c, is)
+		// Colours that are meant to blend into the background, like ANSI
+		// black, have little contrast to keep.
+		if before >= 1.5 && after < before*keep {
+			problems = append(problems, fmt.Sprintf("%s contrast falls from %.2f to %.2f", name, before, after))
+		}
+	}
+	ratio("foreground", b.Foreground, b.Background, t.Background)
+	for i, c := range b.ANSI {
+		ratio("terminal.ansi"+palette.ANSINames[i], c, b.Background, t.Background)
+	}
+	ratio("foreground on the selection", b.Foreground, b.Selection, t.Selection)
+	if c := colors.Contrast(t.Cursor, t.Background); c < 3 {
+		problems = append(problems, fmt.Sprintf("the cursor has contrast %.2f, want at least 3", c))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s: %s; lower its strength or pick another role", t.Name, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// xcolor formats c in the rgb:RR/GG/BB form of XParseColor, which every
+// terminal that takes OSC colours reads and which has no # to be taken for
+// a comment in ssh_config.
+func xcolor(c colors.Color) string {
+	return fmt.Sprintf("rgb:%02x/%02x/%02x", c.R, c.G, c.B)
+}
+
+// OSC returns the escape sequences that switch a terminal to t: OSC 11 for
+// the background, 12 for the cursor and 17 for the selection background.
+func (t Tint) OSC() string {
+	return fmt.Sprintf("\x1b]11;%s\x07\x1b]12;%s\x07\x1b]17;%s\x07",
+		xcolor(t.Background), xcolor(t.Cursor), xcolor(t.Selection))
+}
+
+// Printf returns t.OSC() as a printf format for a shell command line.
+func (t Tint) Printf() string {
+	r := strings.NewReplacer("\x1b", `\033`, "\x07", `\007`)
+	return "printf '" + r.Replace(t.OSC()) + "'"
+}
+
+// Kitty returns t as a kitty colour scheme. It sets only the tinted
+// colours, so the palette of the running theme stays.
+func (t Tint) Kitty() string {
+	var b strings.Builder
+	set := func(key string, c colors.Color) {
+		fmt.Fprintf(&b, "%-24s %s\n", key, c.Opaque().Hex())
+	}
+	set("background", t.Background)
+	set("cursor", t.Cursor)
+	set("cursor_text_color", t.CursorText)
+	set("selection_background", t.Selection)
+	return b.String()
+}
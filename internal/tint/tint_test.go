@@ -0,0 +1,157 @@
+package tint
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colorreg"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/export"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/golden")
+
+func loadFixture(t *testing.T) *Config {
+	t.Helper()
+	c, err := Load(filepath.Join("testdata", "hosts.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// TestMatch reads lines of "host environment" ("-" for none) from
+// testdata/matches.txt and checks that the fixture config matches each
+// host that way.
+func TestMatch(t *testing.T) {
+	c := loadFixture(t)
+	f, err := os.Open(filepath.Join("testdata", "matches.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	n := 0
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			t.Fatalf("matches.txt:%d: want a host and an environment", line)
+		}
+		n++
+		got := "-"
+		if e, _, ok := c.Match(fields[0]); ok {
+			got = e.Name
+		}
+		if got != fields[1] {
+			t.Errorf("matches.txt:%d: %s is in %s, want %s", line, fields[0], got, fields[1])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if n == 0 {
+		t.Fatal("matches.txt lists no hosts")
+	}
+}
+
+// TestGolden compares the files for the fixture config with
+// testdata/golden, which must hold exactly those files. Run with -update
+// to rewrite them.
+func TestGolden(t *testing.T) {
+	c := loadFixture(t)
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eff, err := colorreg.Resolve(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := palette.New(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, err := BaseColors(eff, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := Files(c, base, p, export.BaseName(v), "hosts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join("testdata", "golden")
+	if *update {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := export.Write(dir, files); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want := map[string]bool{}
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		want[filepath.ToSlash(rel)] = true
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if !want[f.Path] {
+			t.Errorf("%s: no golden file", f.Path)
+			continue
+		}
+		delete(want, f.Path)
+		golden, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(golden, f.Data) {
+			t.Errorf("%s differs from the golden file:\n%s", f.Path, diffLines(string(golden), string(f.Data)))
+		}
+	}
+	var stale []string
+	for path := range want {
+		stale = append(stale, path)
+	}
+	sort.Strings(stale)
+	for _, path := range stale {
+		t.Errorf("%s: golden file is no longer generated", path)
+	}
+}
+
+// diffLines describes the lines where got differs from want.
+func diffLines(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var b strings.Builder
+	for i := 0; i < max(len(w), len(g)); i++ {
+		var wl, gl string
+		if i < len(w) {
+			wl = w[i]
+		}
+		if i < len(g) {
+			gl = g[i]
+		}
+		if wl != gl {
+			fmt.Fprintf(&b, "\tline %d: want %q, got %q\n", i+1, wl, gl)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,180 @@
+// Package variants derives additional theme variants from the base theme.
+package variants
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+// FocusName is the theme name of the focus variant.
+const FocusName = "Caffeinated Rust Focus"
+
+// focusRamp places each neutralised syntax role on the grey ramp between the
+// muted comment grey (0) and the editor foreground (1), with the fontStyle
+// that keeps it distinguishable without hue.
+var focusRamp = map[palette.Role]struct {
+	level     float64
+	fontStyle string
+}{
+	palette.Keyword:     {0.60, "bold"},
+	palette.Function:    {1.00, ""},
+	palette.Type:        {0.85, ""},
+	palette.Constant:    {0.70, ""},
+	palette.Variable:    {1.00, ""},
+	palette.Property:    {0.85, ""},
+	palette.Tag:         {0.75, ""},
+	palette.Punctuation: {0.35, ""},
+}
+
+// definitionFontStyle is added to definition sites (function and type
+// names where they are declared).
+const definitionFontStyle = "bold"
+
+// focusKeep lists the workbench ids whose colours survive in the focus
+// variant: diagnostics, source control and find results.
+var focusKeep = []string{
+	"editorError.", "editorWarning.", "editorInfo.", "editorHint.",
+	"editorOverviewRuler.errorForeground",
+	"editorOverviewRuler.warningForeground",
+	"editorOverviewRuler.infoForeground",
+	"list.errorForeground", "list.warningForeground", "list.invalidItemForeground",
+	"inputValidation.",
+	"notificationsErrorIcon.", "notificationsWarningIcon.", "notificationsInfoIcon.",
+	"statusBarItem.errorBackground", "statusBarItem.errorForeground",
+	"statusBarItem.warningBackground", "statusBarItem.warningForeground",
+	"problemsErrorIcon.", "problemsWarningIcon.", "problemsInfoIcon.",
+	"editorMarkerNavigationError.", "editorMarkerNavigationWarning.", "editorMarkerNavigationInfo.",
+	"gitDecoration.",
+	"editorGutter.addedBackground", "editorGutter.modifiedBackground", "editorGutter.deletedBackground",
+	"editorOverviewRuler.addedForeground", "editorOverviewRuler.modifiedForeground",
+	"editorOverviewRuler.deletedForeground",
+	"merge.",
+	"editorOverviewRuler.currentContentForeground",
+	"editorOverviewRuler.incomingContentForeground",
+	"editorOverviewRuler.commonContentForeground",
+	"diffEditor.",
+	"editor.findMatch", "editor.currentFindMatch", "editor.findMatchHighlight", "editor.findRange",
+	"editorOverviewRuler.findMatchForeground",
+	"list.filterMatch",
+	"peekViewEditor.matchHighlight", "peekViewResult.matchHighlight",
+	"terminal.ansi", "terminal.findMatch",
+}
+
+func focusKept(id string) bool {
+	for _, k := range focusKeep {
+		if strings.HasPrefix(id, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Focus derives the monochrome focus variant from base. Syntax roles other
+// than comments and strings collapse onto a grey ramp and are told apart by
+// lightness and fontStyle; workbench accents are neutralised to greys of the
+// same lightness, while diagnostic, git and find colours are kept.
+func Focus(base *theme.Theme) (*theme.Theme, error) {
+	p, err := palette.New(base)
+	if err != nil {
+		return nil, err
+	}
+	t := base.Clone()
+	t.Name = FocusName
+
+	for _, id := range t.Colors.Keys() {
+		if focusKept(id) {
+			continue
+		}
+		v, _ := t.Colors.Get(id)
+		c, err := colors.ParseHex(v)
+		if err != nil || !c.Chromatic() {
+			continue
+		}
+		t.Colors.Set(id, c.Desaturate().Hex())
+	}
+
+	lo := p.Color(palette.Muted).OKLab().L
+	hi := p.Color(palette.Foreground).OKLab().L
+	grey := func(level float64) string {
+		return colors.Gray(lo+level*(hi-lo), 0xff).Hex()
+	}
+
+	var rules []theme.TokenRule
+	seen := map[string]bool{}
+	for _, rule := range base.TokenColors {
+		for _, scope := range rule.Scope {
+			if seen[scope] {
+				continue
+			}
+			seen[scope] = true
+			rules = append(rules, focusRule(base, p, scope, grey))
+		}
+	}
+	t.TokenColors = mergeRules(rules)
+	return t, nil
+}
+
+// focusRule restyles a single scope. The base theme's effective style for
+// the scope decides what is kept, so that a scope listed under several rules
+// is treated the way the editor actually paints it. Comment scopes all take
+// the comment role's style so that they stand out consistently.
+func focusRule(base *theme.Theme, p *palette.Palette, scope string, grey func(float64) string) theme.TokenRule {
+	// A selector with parent scopes, such as "source.ts entity.name.type",
+	// is matched as the scope stack it selects.
+	stack := strings.Fields(scope)
+	leaf := stack[len(stack)-1]
+	style := base.Match(stack...)
+	role, ok := palette.ScopeRole(scope)
+	settings := theme.TokenSettings{
+		Foreground: style.Foreground.Value,
+		Background: style.Background.Value,
+		FontStyle:  style.FontStyle.Value,
+	}
+	if ok && role == palette.Comment {
+		settings.Foreground = p.Hex(palette.Comment)
+		settings.FontStyle = p.FontStyle(palette.Comment)
+	}
+	if step, neutral := focusRamp[role]; ok && neutral && settings.Background == "" {
+		settings.Foreground = grey(step.level)
+		settings.FontStyle = step.fontStyle
+		if palette.Definitional(leaf) {
+			settings.FontStyle = joinFontStyle(settings.FontStyle, definitionFontStyle)
+		}
+	}
+	return theme.TokenRule{Scope: theme.Scopes{scope}, Settings: settings}
+}
+
+// mergeRules folds scopes with identical settings into one rule, keeping
+// the order in which each style first appears.
+func mergeRules(rules []theme.TokenRule) []theme.TokenRule {
+	var out []theme.TokenRule
+	index := map[theme.TokenSettings]int{}
+	for _, r := range rules {
+		if i, ok := index[r.Settings]; ok {
+			out[i].Scope = append(out[i].Scope, r.Scope...)
+			continue
+		}
+		index[r.Settings] = len(out)
+		out = append(out, r)
+	}
+	return out
+}
+
+func joinFontStyle(styles ...string) string {
+	set := map[string]bool{}
+	for _, s := range styles {
+		for _, f := range strings.Fields(s) {
+			set[f] = true
+		}
+	}
+	fs := make([]string, 0, len(set))
+	for f := range set {
+		fs = append(fs, f)
+	}
+	sort.Strings(fs)
+	return strings.Join(fs, " ")
+}
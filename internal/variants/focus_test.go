@@ -0,0 +1,112 @@
+package variants
+
+import (
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/colors"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/palette"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/theme"
+)
+
+func focus(t *testing.T) (base, focus *theme.Theme) {
+	t.Helper()
+	v, err := theme.LoadVariant("../..", theme.DefaultVariant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := Focus(v.Theme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v.Theme, f
+}
+
+// TestFocusKeeps checks that diagnostic, git and find colours come through
+// unchanged and every other workbench accent is neutralised.
+func TestFocusKeeps(t *testing.T) {
+	base, f := focus(t)
+	for _, id := range []string{
+		"editorError.foreground",
+		"editorWarning.foreground",
+		"gitDecoration.modifiedResourceForeground",
+		"editorGutter.addedBackground",
+		"editor.findMatchBackground",
+		"editorOverviewRuler.findMatchForeground",
+	} {
+		want, ok := base.Colors.Get(id)
+		if !ok {
+			t.Fatalf("the default variant has no %s", id)
+		}
+		if c := colors.MustParseHex(want); !c.Chromatic() {
+			t.Fatalf("%s is %s, a grey, so keeping it proves nothing", id, want)
+		}
+		if got, _ := f.Colors.Get(id); got != want {
+			t.Errorf("%s is %s, want %s", id, got, want)
+		}
+	}
+	for _, id := range base.Colors.Keys() {
+		want, _ := base.Colors.Get(id)
+		got, _ := f.Colors.Get(id)
+		c, err := colors.ParseHex(got)
+		switch {
+		case focusKept(id) && got != want:
+			t.Errorf("kept %s is %s, want %s", id, got, want)
+		case !focusKept(id) && err == nil && c.Chromatic():
+			t.Errorf("%s is %s, want a grey", id, got)
+		}
+	}
+}
+
+// TestFocusRamp checks that every scope of a neutralised role is painted
+// with the grey and fontStyle of its step on the ramp, while strings keep
+// their colour and comments take the comment style.
+func TestFocusRamp(t *testing.T) {
+	base, f := focus(t)
+	p, err := palette.New(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lo := p.Color(palette.Muted).OKLab().L
+	hi := p.Color(palette.Foreground).OKLab().L
+
+	covered := map[palette.Role]bool{}
+	for _, rule := range f.TokenColors {
+		for _, scope := range rule.Scope {
+			role, ok := palette.ScopeRole(scope)
+			step, neutral := focusRamp[role]
+			if !ok || !neutral || rule.Settings.Background != "" {
+				continue
+			}
+			covered[role] = true
+			c, err := colors.ParseHex(rule.Settings.Foreground)
+			if err != nil {
+				t.Fatalf("%s: %v", scope, err)
+			}
+			if c.Chromatic() {
+				t.Errorf("%s (%s) is %s, want a grey", scope, role, c.Hex())
+			}
+			if want := colors.Gray(lo+step.level*(hi-lo), 0xff); c != want {
+				t.Errorf("%s (%s) is %s, want %s at %.2f on the ramp", scope, role, c.Hex(), want.Hex(), step.level)
+			}
+		}
+	}
+	for role := range focusRamp {
+		if !covered[role] {
+			t.Errorf("no scope of the %s role was put on the ramp", role)
+		}
+	}
+
+	for _, tc := range []struct {
+		scope, foreground, fontStyle string
+	}{
+		{"keyword.control", colors.Gray(lo+0.60*(hi-lo), 0xff).Hex(), "bold"},
+		{"entity.name.function.go", colors.Gray(hi, 0xff).Hex(), "bold"},
+		{"string.quoted.double", base.Match("string.quoted.double").Foreground.Value, base.Match("string.quoted.double").FontStyle.Value},
+		{"comment.line", p.Hex(palette.Comment), p.FontStyle(palette.Comment)},
+	} {
+		got := f.Match(tc.scope)
+		if got.Foreground.Value != tc.foreground || got.FontStyle.Value != tc.fontStyle {
+			t.Errorf("%s is %s %q, want %s %q", tc.scope, got.Foreground.Value, got.FontStyle.Value, tc.foreground, tc.fontStyle)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package vsix
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultIgnore is the list vsce ignores before reading .vscodeignore.
+var DefaultIgnore = []string{
+	".vscodeignore",
+	"package-lock.json",
+	"npm-debug.log",
+	"yarn.lock",
+	"yarn-error.log",
+	"npm-shrinkwrap.json",
+	".editorconfig",
+	".npmrc",
+	".yarnrc",
+	".gitattributes",
+	"*.todo",
+	"tslint.yaml",
+	".eslintrc*",
+	".babelrc*",
+	".prettierrc*",
+	".cz-config.js",
+	".commitlintrc*",
+	"webpack.config.js",
+	"ISSUE_TEMPLATE.md",
+	"CONTRIBUTING.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"CODE_OF_CONDUCT.md",
+	".github",
+	".travis.yml",
+	"appveyor.yml",
+	"**/.git/**",
+	"**/*.vsix",
+	"**/.DS_Store",
+	"**/*.vsixmanifest",
+	"**/.vscode-test/**",
+	"**/.vscode-test-web/**",
+}
+
+// Ignore is a compiled ignore list with vsce's semantics: minimatch
+// patterns matched against slash-separated paths relative to the
+// extension root, with dot files matched by wildcards. Each pattern also
+// matches everything below a directory of that name, and a pattern
+// starting with "!" brings back files an earlier pattern ignored.
+type Ignore struct {
+	ignore, keep [][]string
+}
+
+// ParseIgnore compiles DefaultIgnore followed by the lines of a
+// .vscodeignore file. Blank lines and lines starting with # are skipped.
+func ParseIgnore(vscodeignore string) *Ignore {
+	ig := &Ignore{}
+	lines := append([]string(nil), DefaultIgnore...)
+	for _, line := range strings.Split(vscodeignore, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for _, line := range lines {
+		list := &ig.ignore
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			list, line = &ig.keep, rest
+		}
+		for _, p := range expandBraces(line) {
+			p = strings.TrimPrefix(p, "./")
+			*list = append(*list, strings.Split(p, "/"), strings.Split(p+"/**", "/"))
+		}
+	}
+	return ig
+}
+
+// Ignored reports whether the file at the slash-separated path rel is left
+// out of the package.
+func (ig *Ignore) Ignored(rel string) bool {
+	segs := strings.Split(rel, "/")
+	return matchAny(ig.ignore, segs) && !matchAny(ig.keep, segs)
+}
+
+func matchAny(patterns [][]string, segs []string) bool {
+	for _, p := range patterns {
+		if matchSegments(p, segs) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" segment matches any number of path segments.
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pat[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		ok, err := path.Match(strings.ReplaceAll(pat[0], "[!", "[^"), segs[0])
+		if err != nil || !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// expandBraces expands the first {a,b} group of p, recursively.
+func expandBraces(p string) []string {
+	open := strings.IndexByte(p, '{')
+	if open < 0 {
+		return []string{p}
+	}
+	depth, end := 0, -1
+	var alts []string
+	start := open + 1
+	for i := open; i < len(p) && end < 0; i++ {
+		switch p[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				alts = append(alts, p[start:i])
+				end = i
+			}
+		case ',':
+			if depth == 1 {
+				alts = append(alts, p[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if end < 0 || len(alts) < 2 {
+		return []string{p}
+	}
+	var out []string
+	for _, a := range alts {
+		out = append(out, expandBraces(p[:open]+a+p[end+1:])...)
+	}
+	return out
+}
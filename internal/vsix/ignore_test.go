@@ -0,0 +1,77 @@
+package vsix
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIgnored(t *testing.T) {
+	ig := ParseIgnore(`
+# comments and blank lines are skipped
+
+.vscode/**
+src/
+**/*.{rs,toml}
+*.log
+!keep.log
+images/*.png
+!images/icon.png
+./dist
+`)
+	for rel, want := range map[string]bool{
+		// DefaultIgnore.
+		".vscodeignore":                true,
+		".github/workflows/ci.yml":     true,
+		"sub/.git/HEAD":                true,
+		"sub/dir/.DS_Store":            true,
+		"old.vsix":                     true,
+		".eslintrc.json":               true,
+		"CONTRIBUTING.md":              true,
+		"docs/CONTRIBUTING.md":         false,
+		"package.json":                 false,
+		"README.md":                    false,
+		"themes/dark-color-theme.json": false,
+
+		// A directory pattern, with or without a slash, covers what is below.
+		".vscode/settings.json": true,
+		"src/main.rs":           true,
+		"srcs/main.c":           false,
+		// With a slash it matches only a directory.
+		"src":          false,
+		"dist/a/b.txt": true,
+
+		// Braces and ** at any depth.
+		"Cargo.toml":      true,
+		"crates/a/lib.rs": true,
+		"lib.rsx":         false,
+
+		// * does not cross a slash, and matches dot files.
+		"build.log":   true,
+		".hidden.log": true,
+		"logs/a.log":  false,
+
+		// ! brings back a file an earlier pattern ignored.
+		"keep.log":        false,
+		"images/shot.png": true,
+		"images/icon.png": false,
+	} {
+		if got := ig.Ignored(rel); got != want {
+			t.Errorf("Ignored(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	for p, want := range map[string]string{
+		"a":            "a",
+		"{a,b}":        "a b",
+		"x.{a,b{c,d}}": "x.a x.bc x.bd",
+		"{a,b}/{c,d}":  "a/c a/d b/c b/d",
+		"{a}":          "{a}",
+		"{a,b":         "{a,b",
+	} {
+		if s := strings.Join(expandBraces(p), " "); s != want {
+			t.Errorf("expandBraces(%q) = %s, want %s", p, s, want)
+		}
+	}
+}
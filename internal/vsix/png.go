@@ -0,0 +1,171 @@
+package vsix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// keptChunks are the ancillary chunks that change how pixels look and
+// survive optimisation. Text, EXIF and timestamps are dropped.
+var keptChunks = map[string]bool{"iCCP": true, "sRGB": true, "gAMA": true, "cHRM": true, "pHYs": true}
+
+type chunk struct {
+	typ  string
+	data []byte
+}
+
+func readChunks(data []byte) ([]chunk, error) {
+	if !bytes.HasPrefix(data, []byte(pngSignature)) {
+		return nil, fmt.Errorf("not a PNG file")
+	}
+	var cs []chunk
+	for rest := data[len(pngSignature):]; len(rest) > 0; {
+		if len(rest) < 12 {
+			return nil, fmt.Errorf("truncated chunk")
+		}
+		n := binary.BigEndian.Uint32(rest)
+		if uint64(n)+12 > uint64(len(rest)) {
+			return nil, fmt.Errorf("truncated %s chunk", rest[4:8])
+		}
+		cs = append(cs, chunk{string(rest[4:8]), rest[8 : 8+n]})
+		rest = rest[12+n:]
+	}
+	return cs, nil
+}
+
+func writeChunks(cs []chunk) []byte {
+	var b bytes.Buffer
+	b.WriteString(pngSignature)
+	for _, c := range cs {
+		binary.Write(&b, binary.BigEndian, uint32(len(c.data)))
+		crc := crc32.NewIEEE()
+		crc.Write([]byte(c.typ))
+		crc.Write(c.data)
+		b.WriteString(c.typ)
+		b.Write(c.data)
+		binary.Write(&b, binary.BigEndian, crc.Sum32())
+	}
+	return b.Bytes()
+}
+
+// OptimizePNG re-encodes a PNG losslessly: at the highest compression,
+// with an indexed or greyscale colour type when the pixels allow it, and
+// without metadata chunks. Colour profile chunks are kept. Every candidate
+// is decoded again and compared pixel by pixel with the original, and the
+// original is returned when nothing smaller reproduces it exactly.
+func OptimizePNG(data []byte) ([]byte, error) {
+	orig, err := readChunks(data)
+	if err != nil {
+		return nil, err
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var kept []chunk
+	profiled := false
+	for _, c := range orig {
+		if keptChunks[c.typ] {
+			kept = append(kept, c)
+			profiled = profiled || c.typ == "iCCP"
+		}
+	}
+
+	candidates := []image.Image{img}
+	if p := paletted(img); p != nil {
+		candidates = append(candidates, p)
+	}
+	// A greyscale image cannot carry an RGB colour profile.
+	if g := gray(img); g != nil && !profiled {
+		candidates = append(candidates, g)
+	}
+	best := data
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	for _, c := range candidates {
+		var b bytes.Buffer
+		if err := enc.Encode(&b, c); err != nil {
+			return nil, err
+		}
+		cs, err := readChunks(b.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		// The profile chunks go straight after IHDR, before PLTE and IDAT
+		// as the specification requires.
+		out := writeChunks(append(append([]chunk{cs[0]}, kept...), cs[1:]...))
+		if len(out) >= len(best) {
+			continue
+		}
+		back, err := png.Decode(bytes.NewReader(out))
+		if err != nil || !samePixels(img, back) {
+			continue
+		}
+		best = out
+	}
+	return best, nil
+}
+
+// paletted returns img as an indexed image when it has at most 256
+// colours, or nil.
+func paletted(img image.Image) *image.Paletted {
+	b := img.Bounds()
+	index := map[color.NRGBA]uint8{}
+	var pal color.Palette
+	out := image.NewPaletted(b, nil)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			i, ok := index[c]
+			if !ok {
+				if len(pal) == 256 {
+					return nil
+				}
+				i = uint8(len(pal))
+				index[c] = i
+				pal = append(pal, c)
+			}
+			out.SetColorIndex(x, y, i)
+		}
+	}
+	out.Palette = pal
+	return out
+}
+
+// gray returns img as a greyscale image when it is opaque and every pixel
+// is grey, or nil.
+func gray(img image.Image) *image.Gray {
+	b := img.Bounds()
+	out := image.NewGray(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A != 0xff || c.R != c.G || c.G != c.B {
+				return nil
+			}
+			out.Pix[out.PixOffset(x, y)] = c.R
+		}
+	}
+	return out
+}
+
+func samePixels(a, b image.Image) bool {
+	if a.Bounds() != b.Bounds() {
+		return false
+	}
+	r := a.Bounds()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if color.NRGBA64Model.Convert(a.At(x, y)) != color.NRGBA64Model.Convert(b.At(x, y)) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package vsix
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ImageRef is an image referenced from a Markdown file.
+type ImageRef struct {
+	Line int
+	URL  string
+}
+
+var (
+	markdownImage = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	htmlImage     = regexp.MustCompile(`(?i)<img\s[^>]*\bsrc\s*=\s*["']([^"']+)["']`)
+)
+
+// ImageRefs lists the images of a Markdown document, in order.
+func ImageRefs(markdown string) []ImageRef {
+	var refs []ImageRef
+	for i, line := range strings.Split(markdown, "\n") {
+		for _, re := range []*regexp.Regexp{markdownImage, htmlImage} {
+			for _, m := range re.FindAllStringSubmatch(line, -1) {
+				refs = append(refs, ImageRef{Line: i + 1, URL: m[1]})
+			}
+		}
+	}
+	return refs
+}
+
+// ImageLimits bounds the dimensions of README images.
+type ImageLimits struct {
+	MinWidth, MaxWidth, MaxHeight int
+}
+
+// DefaultImageLimits suits the marketplace page, which shows images at up
+// to about 900 CSS pixels wide: smaller images look broken and much larger
+// ones only add download size, even for high-density screens.
+var DefaultImageLimits = ImageLimits{MinWidth: 320, MaxWidth: 2400, MaxHeight: 2400}
+
+// trustedSVG are badge services vsce accepts SVG images from; its own
+// list is longer.
+var trustedSVG = []string{
+	"api.travis-ci.com", "app.fossa.io", "badge.fury.io", "badgen.net",
+	"badges.gitter.im", "ci.appveyor.com", "circleci.com", "codecov.io",
+	"coveralls.io", "dev.azure.com", "github.com", "gitlab.com",
+	"goreportcard.com", "img.shields.io", "marketplace.visualstudio.com",
+	"snyk.io", "travis-ci.com", "vsmarketplacebadge.apphb.com",
+}
+
+// CheckImages checks the images of the Markdown file at root/name: remote
+// images must use HTTPS and SVG only from trusted badge services, as vsce
+// requires; local images must exist, must be inside root and have
+// dimensions within limits. It returns one message per problem.
+func CheckImages(root, name string, limits ImageLimits) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if err != nil {
+		return nil, err
+	}
+	var problems []string
+	report := func(r ImageRef, format string, args ...any) {
+		problems = append(problems, fmt.Sprintf("%s:%d: %s: %s", name, r.Line, r.URL, fmt.Sprintf(format, args...)))
+	}
+	for _, r := range ImageRefs(string(data)) {
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			report(r, "%v", err)
+			continue
+		}
+		isSVG := strings.EqualFold(path.Ext(u.Path), ".svg")
+		if u.Scheme != "" {
+			switch {
+			case u.Scheme == "data":
+				report(r, "data URIs are not allowed")
+			case u.Scheme != "https":
+				report(r, "images must use HTTPS")
+			case isSVG && !trusted(u.Hostname()):
+				report(r, "SVG images are only allowed from trusted badge services")
+			}
+			continue
+		}
+		if isSVG {
+			report(r, "SVG images are only allowed from trusted badge services")
+			continue
+		}
+		rel := path.Clean(path.Join(path.Dir(filepath.ToSlash(name)), u.Path))
+		if strings.HasPrefix(rel, "../") || path.IsAbs(u.Path) {
+			report(r, "points outside the extension")
+			continue
+		}
+		f, err := os.Open(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			report(r, "%v", err)
+			continue
+		}
+		cfg, _, err := image.DecodeConfig(f)
+		f.Close()
+		if err != nil {
+			report(r, "%v", err)
+			continue
+		}
+		switch {
+		case cfg.Width < limits.MinWidth:
+			report(r, "%dx%d is narrower than %d pixels", cfg.Width, cfg.Height, limits.MinWidth)
+		case cfg.Width > limits.MaxWidth || cfg.Height > limits.MaxHeight:
+			report(r, "%dx%d is larger than %dx%d", cfg.Width, cfg.Height, limits.MaxWidth, limits.MaxHeight)
+		}
+	}
+	return problems, nil
+}
+
+func trusted(host string) bool {
+	for _, t := range trustedSVG {
+		if strings.EqualFold(host, t) {
+			return true
+		}
+	}
+	return false
+}
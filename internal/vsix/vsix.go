@@ -0,0 +1,109 @@
+// Package vsix works out what vsce would put in the extension package and
+// how large it would be, and keeps the images that go into it small.
+package vsix
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// File is one file of the package.
+type File struct {
+	// Path is slash separated and relative to the extension root.
+	Path string
+	Size int64
+	// Compressed is the deflated size, as stored in the package.
+	Compressed int64
+}
+
+// Package is the computed contents of a VSIX.
+type Package struct {
+	Files []File
+	// Size is the estimated size of the .vsix file.
+	Size int64
+}
+
+// manifestOverhead approximates the entries vsce generates itself
+// (extension.vsixmanifest and [Content_Types].xml), compressed.
+const manifestOverhead = 1500
+
+// Contents walks root and lists the files vsce would package, applying
+// DefaultIgnore and root/.vscodeignore.
+func Contents(root string) (*Package, error) {
+	rules, err := os.ReadFile(filepath.Join(root, ".vscodeignore"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	ig := ParseIgnore(string(rules))
+	pkg := &Package{Size: manifestOverhead}
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || ig.Ignored(rel) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		f := File{Path: rel, Size: int64(len(data)), Compressed: deflated(data)}
+		pkg.Files = append(pkg.Files, f)
+		// Local header and central directory entry, each with the name
+		// under extension/.
+		name := int64(len("extension/" + rel))
+		pkg.Size += f.Compressed + 30 + name + 46 + name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pkg.Files, func(i, j int) bool { return pkg.Files[i].Path < pkg.Files[j].Path })
+	pkg.Size += 22
+	return pkg, nil
+}
+
+// deflated returns the size data takes in a zip: deflated at the default
+// level, or stored when that is no smaller.
+func deflated(data []byte) int64 {
+	var b bytes.Buffer
+	w, _ := flate.NewWriter(&b, flate.DefaultCompression)
+	w.Write(data)
+	w.Close()
+	return min(int64(b.Len()), int64(len(data)))
+}
+
+// ParseSize parses a package size such as 1048576, 512K or 1.5M, as given
+// by -budget or caffeinated.vsixBudget.
+func ParseSize(s string) (int64, error) {
+	mult, n := 1.0, s
+	switch {
+	case strings.HasSuffix(n, "K"):
+		mult, n = 1<<10, strings.TrimSuffix(n, "K")
+	case strings.HasSuffix(n, "M"):
+		mult, n = 1<<20, strings.TrimSuffix(n, "M")
+	}
+	f, err := strconv.ParseFloat(n, 64)
+	if err != nil || !(f > 0 && f*mult < 1<<62) {
+		return 0, fmt.Errorf("size %q is not a number of bytes", s)
+	}
+	return int64(f * mult), nil
+}
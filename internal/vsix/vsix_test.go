@@ -0,0 +1,112 @@
+package vsix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/manifest"
+)
+
+func TestParseSize(t *testing.T) {
+	for s, want := range map[string]int64{
+		"1048576": 1048576,
+		"512K":    512 << 10,
+		"1.5M":    3 << 19,
+		"0.5K":    512,
+		"2M":      2 << 20,
+	} {
+		got, err := ParseSize(s)
+		if err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "0", "-1", "abc", "K", "512k", "1G", "Inf", "NaN", "1e30M"} {
+		if got, err := ParseSize(s); err == nil {
+			t.Errorf("ParseSize(%q) = %d, want an error", s, got)
+		}
+	}
+}
+
+func TestContents(t *testing.T) {
+	root := t.TempDir()
+	for rel, data := range map[string]string{
+		".vscodeignore":   "notes/**\n*.patch\n",
+		"package.json":    "{}",
+		"themes/a.json":   "{}",
+		"notes/todo.md":   "x",
+		"fix.patch":       "x",
+		".git/config":     "x",
+		"yarn.lock":       "x",
+		"images/icon.png": "png",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pkg, err := Contents(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range pkg.Files {
+		got = append(got, f.Path)
+	}
+	if want := "images/icon.png package.json themes/a.json"; strings.Join(got, " ") != want {
+		t.Errorf("files %q, want %q", got, want)
+	}
+	if pkg.Size <= manifestOverhead {
+		t.Errorf("size %d leaves nothing for the files", pkg.Size)
+	}
+}
+
+// TestRepository checks that the repository's own .vscodeignore leaves the
+// backlog, review and benchmark files out of the package, and that what is
+// left fits caffeinated.vsixBudget.
+func TestRepository(t *testing.T) {
+	rules, err := os.ReadFile("../../.vscodeignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ig := ParseIgnore(string(rules))
+	for _, rel := range []string{
+		"requests.jsonl",
+		"FEATURE_REQUESTS.md",
+		"REVIEW_DIFF.patch",
+		"test_output.txt",
+		"bench_output.txt",
+		"dist/caffeinated-rust.vsix",
+		"cmd/caffeinated/main.go",
+		"go.mod",
+	} {
+		if !ig.Ignored(rel) {
+			t.Errorf("%s is packaged", rel)
+		}
+	}
+	for _, rel := range []string{"package.json", "README.md", "CHANGELOG.md", "LICENSE", "themes/Caffeinated-Rust-color-theme.json"} {
+		if ig.Ignored(rel) {
+			t.Errorf("%s is left out", rel)
+		}
+	}
+
+	m, err := manifest.Load("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget, err := ParseSize(m.Tooling.VSIXBudget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := Contents("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Size > budget {
+		t.Errorf("package is about %d bytes, over the %d byte budget", pkg.Size, budget)
+	}
+}
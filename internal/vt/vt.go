@@ -0,0 +1,578 @@
+// Package vt emulates enough of an xterm-compatible terminal to replay
+// recorded sessions: printing with autowrap and scrolling, cursor movement,
+// erasing, scroll regions, the alternate screen and SGR attributes. Output
+// the emulator does not understand is consumed and ignored, as a terminal
+// would.
+package vt
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ColorKind says how a Color is to be resolved.
+type ColorKind uint8
+
+const (
+	// Default is the terminal's default foreground or background.
+	Default ColorKind = iota
+	// Indexed is one of the 256 xterm colours.
+	Indexed
+	// RGB is a 24-bit colour.
+	RGB
+)
+
+// Color is a cell colour as the program asked for it; the palette is only
+// applied when rendering.
+type Color struct {
+	Kind    ColorKind
+	Index   uint8
+	R, G, B uint8
+}
+
+// Attr is a set of SGR attributes.
+type Attr uint8
+
+const (
+	Bold Attr = 1 << iota
+	Faint
+	Italic
+	Underline
+	Inverse
+	Hidden
+	Strike
+)
+
+// Style is the colours and attributes of a cell.
+type Style struct {
+	FG, BG Color
+	Attr   Attr
+}
+
+// Cell is one character position. Wide characters are not treated
+// specially: every rune takes one cell.
+type Cell struct {
+	Rune rune
+	Style
+}
+
+// blank is an erased cell in style s. Erasing keeps the current background,
+// as xterm does, but no other attribute.
+func blank(s Style) Cell {
+	return Cell{Rune: ' ', Style: Style{BG: s.BG}}
+}
+
+type cursor struct {
+	x, y  int
+	style Style
+	// wrap is set after printing in the last column: the next printable
+	// character wraps first.
+	wrap bool
+}
+
+type parseState uint8
+
+const (
+	ground parseState = iota
+	escape
+	escapeInter
+	csi
+	osc
+	oscEscape
+	dcs
+	dcsEscape
+)
+
+// Terminal is an emulated screen.
+type Terminal struct {
+	w, h   int
+	screen [][]Cell
+	// main holds the primary screen while the alternate one is shown.
+	main       [][]Cell
+	cur        cursor
+	saved      cursor
+	top, bot   int
+	autowrap   bool
+	hideCursor bool
+
+	state parseState
+	param []byte
+	utf   []byte
+}
+
+// New returns a w×h terminal with the cursor at the top left.
+func New(w, h int) *Terminal {
+	t := &Terminal{w: w, h: h, autowrap: true}
+	t.screen = t.newScreen()
+	t.bot = h - 1
+	return t
+}
+
+func (t *Terminal) newScreen() [][]Cell {
+	s := make([][]Cell, t.h)
+	for y := range s {
+		s[y] = t.newLine(Style{})
+	}
+	return s
+}
+
+func (t *Terminal) newLine(st Style) []Cell {
+	l := make([]Cell, t.w)
+	for x := range l {
+		l[x] = blank(st)
+	}
+	return l
+}
+
+// Size returns the width and height in cells.
+func (t *Terminal) Size() (w, h int) { return t.w, t.h }
+
+// Cell returns the cell at column x, row y.
+func (t *Terminal) Cell(x, y int) Cell { return t.screen[y][x] }
+
+// Cursor returns the cursor position and whether it is visible.
+func (t *Terminal) Cursor() (x, y int, visible bool) {
+	return t.cur.x, t.cur.y, !t.hideCursor
+}
+
+// Resize changes the screen size, keeping the top left of the contents.
+func (t *Terminal) Resize(w, h int) {
+	if w < 1 || h < 1 {
+		return
+	}
+	resize := func(s [][]Cell) [][]Cell {
+		if s == nil {
+			return nil
+		}
+		out := make([][]Cell, h)
+		for y := range out {
+			out[y] = make([]Cell, w)
+			for x := range out[y] {
+				if y < len(s) && x < len(s[y]) {
+					out[y][x] = s[y][x]
+				} else {
+					out[y][x] = blank(Style{})
+				}
+			}
+		}
+		return out
+	}
+	t.w, t.h = w, h
+	t.screen, t.main = resize(t.screen), resize(t.main)
+	t.top, t.bot = 0, h-1
+	for _, c := range []*cursor{&t.cur, &t.saved} {
+		c.x, c.y = min(c.x, w-1), min(c.y, h-1)
+		c.wrap = false
+	}
+}
+
+// Write feeds output to the terminal. It never fails.
+func (t *Terminal) Write(p []byte) (int, error) {
+	for _, b := range p {
+		t.feed(b)
+	}
+	return len(p), nil
+}
+
+// WriteString is Write for strings.
+func (t *Terminal) WriteString(s string) (int, error) {
+	return t.Write([]byte(s))
+}
+
+func (t *Terminal) feed(b byte) {
+	switch t.state {
+	case ground:
+		t.ground(b)
+	case escape:
+		t.escape(b)
+	case escapeInter:
+		// Character set designations such as ESC ( B take one more byte.
+		t.state = ground
+	case csi:
+		switch {
+		case b >= 0x40 && b <= 0x7e:
+			t.state = ground
+			t.csi(b)
+		case b == 0x1b:
+			t.state = escape
+		case b == 0x18 || b == 0x1a:
+			t.state = ground
+		default:
+			t.param = append(t.param, b)
+		}
+	case osc, dcs:
+		// Titles, hyperlinks, clipboard and device control strings do not
+		// change the screen.
+		switch b {
+		case 0x07:
+			t.state = ground
+		case 0x1b:
+			t.state++
+		}
+	case oscEscape, dcsEscape:
+		if b == '\\' {
+			t.state = ground
+		} else {
+			t.state--
+		}
+	}
+}
+
+func (t *Terminal) ground(b byte) {
+	if len(t.utf) > 0 || b >= 0x80 {
+		t.utf = append(t.utf, b)
+		if !utf8.FullRune(t.utf) {
+			return
+		}
+		r, _ := utf8.DecodeRune(t.utf)
+		t.utf = t.utf[:0]
+		t.print(r)
+		return
+	}
+	switch b {
+	case 0x1b:
+		t.state = escape
+	case '\r':
+		t.cur.x, t.cur.wrap = 0, false
+	case '\n', '\v', '\f':
+		t.lineFeed()
+	case '\b':
+		if t.cur.x > 0 {
+			t.cur.x--
+		}
+		t.cur.wrap = false
+	case '\t':
+		t.cur.x = min((t.cur.x/8+1)*8, t.w-1)
+	default:
+		if b >= 0x20 && b < 0x7f {
+			t.print(rune(b))
+		}
+	}
+}
+
+func (t *Terminal) escape(b byte) {
+	t.state = ground
+	switch b {
+	case '[':
+		t.state, t.param = csi, t.param[:0]
+	case ']':
+		t.state = osc
+	case 'P', '_', '^':
+		t.state = dcs
+	case '(', ')', '*', '+', '#', '%':
+		t.state = escapeInter
+	case '7':
+		t.saved = t.cur
+	case '8':
+		t.restore()
+	case 'D':
+		t.lineFeed()
+	case 'E':
+		t.cur.x = 0
+		t.lineFeed()
+	case 'M':
+		if t.cur.y == t.top {
+			t.scrollDown(1)
+		} else if t.cur.y > 0 {
+			t.cur.y--
+		}
+	case 'c':
+		*t = *New(t.w, t.h)
+	}
+}
+
+func (t *Terminal) print(r rune) {
+	if t.cur.wrap && t.autowrap {
+		t.cur.x = 0
+		t.lineFeed()
+	}
+	t.screen[t.cur.y][t.cur.x] = Cell{Rune: r, Style: t.cur.style}
+	if t.cur.x == t.w-1 {
+		t.cur.wrap = true
+	} else {
+		t.cur.x++
+	}
+}
+
+func (t *Terminal) lineFeed() {
+	t.cur.wrap = false
+	if t.cur.y == t.bot {
+		t.scrollUp(1)
+	} else if t.cur.y < t.h-1 {
+		t.cur.y++
+	}
+}
+
+// restore moves the cursor to the saved position, kept on the screen in
+// case the saved cursor predates a resize.
+func (t *Terminal) restore() {
+	t.cur = t.saved
+	t.cur.x, t.cur.y = clamp(t.cur.x, 0, t.w-1), clamp(t.cur.y, 0, t.h-1)
+}
+
+// scrollUp moves the lines of the scroll region up by n, blanking the
+// bottom.
+func (t *Terminal) scrollUp(n int) {
+	for ; n > 0; n-- {
+		copy(t.screen[t.top:t.bot+1], t.screen[t.top+1:t.bot+1])
+		t.screen[t.bot] = t.newLine(t.cur.style)
+	}
+}
+
+// scrollDown moves the lines of the scroll region down by n, blanking the
+// top.
+func (t *Terminal) scrollDown(n int) {
+	for ; n > 0; n-- {
+		copy(t.screen[t.top+1:t.bot+1], t.screen[t.top:t.bot])
+		t.screen[t.top] = t.newLine(t.cur.style)
+	}
+}
+
+// params parses the parameters of a control sequence. Missing and negative
+// parameters are 0; sub-parameters separated by colons are flattened.
+func (t *Terminal) params() (private byte, ps []int) {
+	s := string(t.param)
+	if s != "" && strings.ContainsRune("?<=>", rune(s[0])) {
+		private, s = s[0], s[1:]
+	}
+	s = strings.TrimRight(s, " !\"#$%&'()*+,-./")
+	if s == "" {
+		return private, nil
+	}
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ':' }) {
+		n, _ := strconv.Atoi(f)
+		ps = append(ps, max(n, 0))
+	}
+	if strings.HasSuffix(s, ";") {
+		ps = append(ps, 0)
+	}
+	return private, ps
+}
+
+// arg returns parameter i, or def when it is missing or zero.
+func arg(ps []int, i, def int) int {
+	if i < len(ps) && ps[i] != 0 {
+		return ps[i]
+	}
+	return def
+}
+
+func clamp(v, lo, hi int) int {
+	return max(lo, min(v, hi))
+}
+
+func (t *Terminal) csi(final byte) {
+	private, ps := t.params()
+	if private == '?' {
+		if final == 'h' || final == 'l' {
+			t.mode(ps, final == 'h')
+		}
+		return
+	}
+	if private != 0 {
+		return
+	}
+	n := arg(ps, 0, 1)
+	c := &t.cur
+	c.wrap = false
+	switch final {
+	case 'A':
+		c.y = clamp(c.y-n, 0, t.h-1)
+	case 'B', 'e':
+		c.y = clamp(c.y+n, 0, t.h-1)
+	case 'C', 'a':
+		c.x = clamp(c.x+n, 0, t.w-1)
+	case 'D':
+		c.x = clamp(c.x-n, 0, t.w-1)
+	case 'E':
+		c.x, c.y = 0, clamp(c.y+n, 0, t.h-1)
+	case 'F':
+		c.x, c.y = 0, clamp(c.y-n, 0, t.h-1)
+	case 'G', '`':
+		c.x = clamp(n-1, 0, t.w-1)
+	case 'd':
+		c.y = clamp(n-1, 0, t.h-1)
+	case 'H', 'f':
+		c.y = clamp(arg(ps, 0, 1)-1, 0, t.h-1)
+		c.x = clamp(arg(ps, 1, 1)-1, 0, t.w-1)
+	case 'J':
+		t.eraseDisplay(arg(ps, 0, 0))
+	case 'K':
+		t.eraseLine(arg(ps, 0, 0))
+	case 'X':
+		t.erase(c.y, c.x, min(c.x+n, t.w))
+	case 'P':
+		line := t.screen[c.y]
+		n = min(n, t.w-c.x)
+		copy(line[c.x:], line[c.x+n:])
+		t.erase(c.y, t.w-n, t.w)
+	case '@':
+		line := t.screen[c.y]
+		n = min(n, t.w-c.x)
+		copy(line[c.x+n:], line[c.x:])
+		t.erase(c.y, c.x, c.x+n)
+	case 'L', 'M':
+		if c.y < t.top || c.y > t.bot {
+			return
+		}
+		top := t.top
+		t.top = c.y
+		if final == 'L' {
+			t.scrollDown(min(n, t.bot-c.y+1))
+		} else {
+			t.scrollUp(min(n, t.bot-c.y+1))
+		}
+		t.top, c.x = top, 0
+	case 'S':
+		t.scrollUp(min(n, t.bot-t.top+1))
+	case 'T':
+		t.scrollDown(min(n, t.bot-t.top+1))
+	case 'r':
+		top, bot := arg(ps, 0, 1)-1, arg(ps, 1, t.h)-1
+		if 0 <= top && top < bot && bot < t.h {
+			t.top, t.bot = top, bot
+			c.x, c.y = 0, 0
+		}
+	case 's':
+		t.saved = *c
+	case 'u':
+		t.restore()
+	case 'm':
+		t.sgr(ps)
+	}
+}
+
+func (t *Terminal) mode(ps []int, set bool) {
+	for _, p := range ps {
+		switch p {
+		case 7:
+			t.autowrap = set
+		case 25:
+			t.hideCursor = !set
+		case 47, 1047, 1049:
+			if set == (t.main != nil) {
+				continue
+			}
+			if set {
+				if p == 1049 {
+					t.saved = t.cur
+				}
+				t.main, t.screen = t.screen, t.newScreen()
+			} else {
+				t.screen, t.main = t.main, nil
+				if p == 1049 {
+					t.restore()
+				}
+			}
+		}
+	}
+}
+
+// erase blanks columns [from, to) of row y.
+func (t *Terminal) erase(y, from, to int) {
+	for x := from; x < to; x++ {
+		t.screen[y][x] = blank(t.cur.style)
+	}
+}
+
+func (t *Terminal) eraseLine(mode int) {
+	switch mode {
+	case 0:
+		t.erase(t.cur.y, t.cur.x, t.w)
+	case 1:
+		t.erase(t.cur.y, 0, t.cur.x+1)
+	case 2:
+		t.erase(t.cur.y, 0, t.w)
+	}
+}
+
+func (t *Terminal) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		t.eraseLine(0)
+		for y := t.cur.y + 1; y < t.h; y++ {
+			t.erase(y, 0, t.w)
+		}
+	case 1:
+		for y := 0; y < t.cur.y; y++ {
+			t.erase(y, 0, t.w)
+		}
+		t.eraseLine(1)
+	case 2, 3:
+		for y := 0; y < t.h; y++ {
+			t.erase(y, 0, t.w)
+		}
+	}
+}
+
+func (t *Terminal) sgr(ps []int) {
+	if len(ps) == 0 {
+		ps = []int{0}
+	}
+	s := &t.cur.style
+	for i := 0; i < len(ps); i++ {
+		switch p := ps[i]; {
+		case p == 0:
+			*s = Style{}
+		case p == 1:
+			s.Attr |= Bold
+		case p == 2:
+			s.Attr |= Faint
+		case p == 3:
+			s.Attr |= Italic
+		case p == 4:
+			s.Attr |= Underline
+		case p == 7:
+			s.Attr |= Inverse
+		case p == 8:
+			s.Attr |= Hidden
+		case p == 9:
+			s.Attr |= Strike
+		case p == 21 || p == 22:
+			s.Attr &^= Bold | Faint
+		case p == 23:
+			s.Attr &^= Italic
+		case p == 24:
+			s.Attr &^= Underline
+		case p == 27:
+			s.Attr &^= Inverse
+		case p == 28:
+			s.Attr &^= Hidden
+		case p == 29:
+			s.Attr &^= Strike
+		case p >= 30 && p <= 37:
+			s.FG = Color{Kind: Indexed, Index: uint8(p - 30)}
+		case p >= 90 && p <= 97:
+			s.FG = Color{Kind: Indexed, Index: uint8(p - 90 + 8)}
+		case p >= 40 && p <= 47:
+			s.BG = Color{Kind: Indexed, Index: uint8(p - 40)}
+		case p >= 100 && p <= 107:
+			s.BG = Color{Kind: Indexed, Index: uint8(p - 100 + 8)}
+		case p == 39:
+			s.FG = Color{}
+		case p == 49:
+			s.BG = Color{}
+		case p == 38 || p == 48:
+			c, n := extended(ps[i+1:])
+			i += n
+			if p == 38 {
+				s.FG = c
+			} else {
+				s.BG = c
+			}
+		}
+	}
+}
+
+// extended parses the arguments of SGR 38 and 48, "5;n" or "2;r;g;b", and
+// returns the colour and the number of parameters consumed.
+func extended(ps []int) (Color, int) {
+	if len(ps) >= 2 && ps[0] == 5 {
+		return Color{Kind: Indexed, Index: uint8(ps[1])}, 2
+	}
+	if len(ps) >= 4 && ps[0] == 2 {
+		return Color{Kind: RGB, R: uint8(ps[1]), G: uint8(ps[2]), B: uint8(ps[3])}, 4
+	}
+	return Color{}, len(ps)
+}
@@ -0,0 +1,108 @@
+package vt_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/caffeinated-minds/caffeinated-rust/internal/cast"
+	"github.com/caffeinated-minds/caffeinated-rust/internal/vt"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden .screen files")
+
+// TestCasts replays the fixture recordings and compares the screen at each
+// marker and at the end with the .screen file next to each one.
+func TestCasts(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "testdata", "casts", "*"+cast.Ext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no fixture recordings")
+	}
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			c, err := cast.Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			for _, m := range cast.Markers(c) {
+				fmt.Fprintf(&b, "== %s\n%s", m.Label, m.Screen.Dump())
+			}
+			got := b.String()
+			golden := strings.TrimSuffix(path, cast.Ext) + ".screen"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("screens differ from %s (rerun with -update after a deliberate change):\ngot:\n%s\nwant:\n%s", filepath.Base(golden), got, want)
+			}
+		})
+	}
+}
+
+func TestRestoreAfterResize(t *testing.T) {
+	for _, restore := range []struct{ name, save, restore string }{
+		{"ESC 8", "\x1b7", "\x1b8"},
+		{"CSI u", "\x1b[s", "\x1b[u"},
+		{"1049", "\x1b[?1049h", "\x1b[?1049l"},
+	} {
+		t.Run(restore.name, func(t *testing.T) {
+			term := vt.New(20, 6)
+			term.WriteString("\x1b[5;15H" + restore.save)
+			term.Resize(10, 3)
+			term.WriteString(restore.restore)
+			if x, y, _ := term.Cursor(); x != 9 || y != 2 {
+				t.Errorf("cursor at %d,%d after restoring, want 9,2", x, y)
+			}
+			term.WriteString("X")
+			if r := term.Cell(9, 2).Rune; r != 'X' {
+				t.Errorf("cell 9,2 = %q, want X", r)
+			}
+		})
+	}
+}
+
+func TestScrollCountClamped(t *testing.T) {
+	term := vt.New(4, 3)
+	term.WriteString("a\r\nb\r\nc\x1b[2;3r\x1b[999999999S")
+	for y, want := range []rune{'a', ' ', ' '} {
+		if r := term.Cell(0, y).Rune; r != want {
+			t.Errorf("row %d = %q after scrolling the region away, want %q", y, r, want)
+		}
+	}
+	term.WriteString("\x1b[r\x1b[1;1Hz\x1b[999999999T")
+	if r := term.Cell(0, 0).Rune; r != ' ' {
+		t.Errorf("row 0 = %q after scrolling the screen down, want blank", r)
+	}
+}
+
+// TestNegativeParams feeds sequences with negative parameters, which used to
+// set a scroll region above the screen and index lines before the cursor.
+func TestNegativeParams(t *testing.T) {
+	for _, seq := range []string{
+		"\x1b[-1r" + strings.Repeat("\n", 25),
+		"\x1b[-5;10r" + strings.Repeat("\x1bM", 25),
+		"abc\x1b[1;2H\x1b[-3@",
+		"abc\x1b[1;2H\x1b[-3P",
+		"\x1b[-2S\x1b[-2T\x1b[-1L\x1b[-1M\x1b[-4X",
+	} {
+		term := vt.New(80, 24)
+		term.WriteString(seq)
+		if x, y, _ := term.Cursor(); x < 0 || x >= 80 || y < 0 || y >= 24 {
+			t.Errorf("%q: cursor at %d,%d, off the screen", seq, x, y)
+		}
+	}
+}
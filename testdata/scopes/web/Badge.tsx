@@ -0,0 +1,10 @@
+import type { User } from "./portal";
+
+export function Badge({ user }: { user: User }) {
+  return (
+    <span className="badge" data-role={user.role}>
+      <Avatar src={user.avatar} />
+      {user.name}
+    </span>
+  );
+}
@@ -0,0 +1,17 @@
+import { Injectable } from "./di";
+
+interface User {
+  readonly id: number;
+  name: string;
+}
+
+type Role = "admin" | "viewer";
+
+const limit = 42;
+
+@Injectable()
+export class UserService extends Repository {
+  async find(id: number): Promise<User | undefined> {
+    return this.users.get(id);
+  }
+}
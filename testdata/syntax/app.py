@@ -0,0 +1,29 @@
+# SYNTAX TEST "source.python" "Python classes, functions, decorators and strings"
+
+from dataclasses import dataclass
+# <---- keyword.control.import.python role:keyword
+#                ^^^^^^ keyword.control.import.python role:keyword
+
+@dataclass
+# <- meta.function.decorator.python entity.name.function.decorator.python punctuation.definition.decorator.python role:foreground
+class Order:
+# <----- storage.type.class.python role:keyword
+#     ^^^^^ entity.name.type.class.python role:function
+    """An order, with its total in cents."""
+#   ^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^ string.quoted.docstring.multi.python role:string
+
+    total: int = 0
+#          ^^^ support.type.python role:type
+#                ^ constant.numeric.integer.decimal.python role:constant
+
+    def paid(self) -> bool:
+#   ^^^ meta.function.python storage.type.function.python role:keyword
+#       ^^^^ meta.function.python entity.name.function.python role:function
+#                     ^^^^ meta.function.python support.type.python role:type
+        # Free orders count as paid.
+#       ^^^^^^^^^^^^^^^^^^^^^^^^^^^^ comment.line.number-sign.python role:comment fontStyle:italic
+        return self.total >= 0 or None
+#       ^^^^^^ keyword.control.flow.python role:property
+#                         ^^ keyword.operator.comparison.python role:keyword
+#                              ^^ keyword.operator.logical.python role:keyword
+#                                 ^^^^ constant.language.python role:constant
@@ -0,0 +1,12 @@
+# SYNTAX TEST "source.shell" "Shell control flow, variables and strings"
+set -eu
+# <--- support.function.builtin.shell role:foreground
+for f in themes/*.json; do
+# <--- keyword.control.shell role:keyword
+#                       ^^ keyword.control.shell role:keyword
+	echo "checking $f"
+	# <---- support.function.builtin.shell role:foreground
+	#    ^^^^^^^^^^^^ string.quoted.double.shell role:string
+	#              ^^ string.quoted.double.shell variable.other.normal.shell role:variable
+done
+# <---- keyword.control.shell role:keyword
@@ -0,0 +1,27 @@
+// SYNTAX TEST "source.rust" "Rust items, macros and attributes"
+
+use std::fmt;
+// <--- keyword.other.rust role:keyword
+//       ^^^ entity.name.namespace.rust role:variable
+
+/// The theme's name.
+// <--------------------- comment.line.documentation.rust role:comment fontStyle:italic
+pub struct Theme {
+// <--- storage.modifier.rust role:keyword
+//  ^^^^^^ keyword.declaration.struct.rust storage.type.rust role:keyword
+//         ^^^^^ entity.name.type.struct.rust role:foreground
+    name: String,
+//  ^^^^ variable.other.rust role:variable
+//        ^^^^^^ entity.name.type.rust role:foreground
+}
+
+impl fmt::Display for Theme {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+//  ^^ meta.function.definition.rust keyword.other.fn.rust role:keyword
+//     ^^^ meta.function.definition.rust entity.name.function.rust role:foreground
+//                    ^^^ storage.modifier.mut.rust role:keyword
+        write!(f, "{}", self.name)
+//      ^^^^^^ meta.macro.rust entity.name.function.macro.rust role:foreground
+//                ^^^^ string.quoted.double.rust role:string
+    }
+}
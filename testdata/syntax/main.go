@@ -0,0 +1,34 @@
+// SYNTAX TEST "source.go" "Go keywords, declarations and literals"
+
+package main
+// <------- keyword.package.go role:keyword
+//      ^^^^ entity.name.type.package.go role:variable
+
+import "fmt"
+// <------ keyword.control.import.go role:keyword
+//     ^^^^^ string.quoted.double.go role:string
+
+// Greet says hello.
+// <-------------------- comment.line.double-slash.go role:comment fontStyle:italic
+// <-- comment.line.double-slash.go punctuation.definition.comment.go role:comment fontStyle:italic
+
+func Greet(name string) string {
+// <---- keyword.function.go role:keyword fontStyle:none
+//   ^^^^^ meta.function.declaration.go entity.name.function.go role:function
+//                      ^^^^^^ storage.type.string.go role:keyword
+	if name == "" {
+	// <-- keyword.control.go role:keyword
+	//      ^^ keyword.operator.comparison.go role:keyword
+	//         ^ string.quoted.double.go punctuation.definition.string.begin.go role:string
+		return "stranger"
+		// <------ keyword.control.go role:keyword
+		//     ^^^^^^^^^^ string.quoted.double.go role:string
+	}
+	return fmt.Sprintf("hello, %s", name)
+	//         ^^^^^^^ meta.function-call.go entity.name.function.support.go role:foreground
+	//                         ^^ string.quoted.double.go constant.other.placeholder.go role:type
+}
+
+const limit = 42
+// <----- keyword.const.go role:keyword
+//            ^^ constant.numeric.decimal.go role:constant